@@ -0,0 +1,278 @@
+package pplogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// DefaultAlertTemplate 是 Slack 兼容的告警内容，钉钉等需要其他格式时可自定义 Template
+const DefaultAlertTemplate = `{"text":{{json .Text}}}`
+
+// AlertWebhookConfig 是告警 webhook 的配置
+type AlertWebhookConfig struct {
+	URL   string
+	Level string // 触发告警的最低等级，取值同 LogLevel，默认 Panic
+
+	// 请求体的 text/template 模板，默认 DefaultAlertTemplate。可用的数据有 .Text（已格式化的告警文本）、
+	// .Level、.Message、.Logger、.Caller、.Time、.AppName、.Host 及 .Fields，json 函数将值编码为 json
+	Template string
+
+	RateLimit time.Duration // 相同消息的最小告警间隔，默认 1 分钟
+	Timeout   time.Duration // 请求超时时间，默认 3s
+}
+
+const (
+	defaultAlertRateLimit = time.Minute
+	defaultAlertTimeout   = 3 * time.Second
+)
+
+// alertData 是告警模板的数据
+type alertData struct {
+	Text    string
+	Level   string
+	Message string
+	Logger  string
+	Caller  string
+	Time    time.Time
+	AppName string
+	Host    string
+	Fields  map[string]interface{}
+}
+
+var alertFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func (c *AlertWebhookConfig) validate() []error {
+	var errs []error
+
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("AlertWebhook: invalid URL %q", c.URL))
+	}
+
+	if c.Level != "" {
+		if _, err := ParseLevel(c.Level); err != nil {
+			errs = append(errs, fmt.Errorf("AlertWebhook: %w", err))
+		}
+	}
+
+	if c.Template != "" {
+		if _, err := template.New("alert").Funcs(alertFuncs).Parse(c.Template); err != nil {
+			errs = append(errs, fmt.Errorf("AlertWebhook: %w", err))
+		}
+	}
+
+	if c.RateLimit < 0 || c.Timeout < 0 {
+		errs = append(errs, errors.New("AlertWebhook: negative RateLimit or Timeout"))
+	}
+
+	return errs
+}
+
+// newAlertCore 创建告警 webhook 输出，fallback 为其他输出，告警发送失败时写入其中。
+// DPanic 及以上等级同步发送，保证 Panic/Fatal 退出前已送达，其余等级异步发送
+func newAlertCore(config Config, fallback zapcore.Core) *alertCore {
+	c := config.AlertWebhook
+
+	level := zapcore.PanicLevel
+	if c.Level != "" {
+		level, _ = ParseLevel(c.Level)
+	}
+	text := c.Template
+	if text == "" {
+		text = DefaultAlertTemplate
+	}
+	rateLimit := c.RateLimit
+	if rateLimit == 0 {
+		rateLimit = defaultAlertRateLimit
+	}
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultAlertTimeout
+	}
+
+	return &alertCore{
+		LevelEnabler: level,
+		alerter: &alerter{
+			url:       c.URL,
+			template:  template.Must(template.New("alert").Funcs(alertFuncs).Parse(text)),
+			client:    &http.Client{Timeout: timeout},
+			rateLimit: rateLimit,
+			sent:      make(map[string]time.Time),
+			appName:   config.AppName,
+			host:      hostname(),
+			fallback:  fallback,
+		},
+	}
+}
+
+// alertCore 将达到等级的日志发送到 webhook
+type alertCore struct {
+	zapcore.LevelEnabler
+	*alerter
+	fields []zapcore.Field
+}
+
+// alerter 由 alertCore 及其 With 派生的 Core 共享
+type alerter struct {
+	url       string
+	template  *template.Template
+	client    *http.Client
+	rateLimit time.Duration
+	appName   string
+	host      string
+	fallback  zapcore.Core
+	wg        sync.WaitGroup
+
+	mu   sync.Mutex
+	sent map[string]time.Time // 每条消息上一次告警的时间
+}
+
+func (c *alertCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(append([]zapcore.Field(nil), c.fields...), fields...)
+	return &clone
+}
+
+func (c *alertCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *alertCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.allow(ent.Message, ent.Time) {
+		return nil
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	if ent.Level >= zapcore.DPanicLevel {
+		c.send(ent, enc.Fields)
+		return nil
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.send(ent, enc.Fields)
+	}()
+
+	return nil
+}
+
+func (c *alertCore) Sync() error {
+	return nil
+}
+
+// Close 等待异步发送的告警完成
+func (c *alertCore) Close() error {
+	c.wg.Wait()
+	return nil
+}
+
+// allow 判断相同消息距上一次告警是否已超过 rateLimit
+func (a *alerter) allow(message string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if last, ok := a.sent[message]; ok && now.Sub(last) < a.rateLimit {
+		return false
+	}
+
+	// 清理过期的记录，避免消息种类很多时无限增长
+	if len(a.sent) >= 1000 {
+		for k, t := range a.sent {
+			if now.Sub(t) >= a.rateLimit {
+				delete(a.sent, k)
+			}
+		}
+	}
+	a.sent[message] = now
+
+	return true
+}
+
+func (a *alerter) send(ent zapcore.Entry, fields map[string]interface{}) {
+	data := alertData{
+		Level:   ent.Level.CapitalString(),
+		Message: ent.Message,
+		Logger:  ent.LoggerName,
+		Time:    ent.Time,
+		AppName: a.appName,
+		Host:    a.host,
+		Fields:  fields,
+	}
+	if ent.Caller.Defined {
+		data.Caller = ent.Caller.TrimmedPath()
+	}
+	data.Text = alertText(data)
+
+	var body bytes.Buffer
+	err := a.template.Execute(&body, data)
+	if err == nil {
+		err = a.post(body.Bytes())
+	}
+	if err != nil {
+		_ = a.fallback.Write(zapcore.Entry{
+			Level:      zapcore.ErrorLevel,
+			Time:       time.Now(),
+			LoggerName: ent.LoggerName,
+			Message:    "pplogger: send alert webhook failed",
+		}, []zapcore.Field{zap.Error(err), zap.String("alert", ent.Message)})
+	}
+}
+
+func (a *alerter) post(body []byte) error {
+	resp, err := a.client.Post(a.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// alertText 格式化告警文本，例如 [FATAL] app@host db: message (main.go:12)
+func alertText(data alertData) string {
+	var b strings.Builder
+	b.WriteString("[" + data.Level + "] ")
+	if data.AppName != "" {
+		b.WriteString(data.AppName + "@")
+	}
+	b.WriteString(data.Host)
+	if data.Logger != "" {
+		b.WriteString(" " + data.Logger)
+	}
+	b.WriteString(": " + data.Message)
+	if data.Caller != "" {
+		b.WriteString(" (" + data.Caller + ")")
+	}
+
+	return b.String()
+}
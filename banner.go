@@ -0,0 +1,63 @@
+package pplogger
+
+import (
+	"bytes"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+// bannerMessage 是 Banner 的消息
+const bannerMessage = "pplogger: banner"
+
+// bannerEntry 返回 Banner 的日志条目，固定为 Info
+func bannerEntry() zapcore.Entry {
+	return zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: bannerMessage}
+}
+
+// bannerFields 返回 Banner 的字段：主机名、进程号、AppName、AppVersion 及生效的日志配置
+func bannerFields(config Config) []zap.Field {
+	level, _ := ParseLevel(config.LogLevel)
+	encoding := config.FileEncoding
+	if encoding == "" {
+		encoding = config.Encoding
+	}
+
+	fields := hostInfoFields(config)
+	if config.AppVersion != "" {
+		fields = append(fields, zap.String("version", config.AppVersion))
+	}
+
+	return append(fields,
+		zap.String("logLevel", level.String()),
+		zap.String("encoding", encoding),
+		zap.Int("maxSize", config.MaxSize),
+		zap.Int("maxBackups", config.MaxBackups),
+		zap.Int("maxAge", config.MaxAge),
+		zap.String("compression", config.compression()),
+		zap.String("rotateInterval", config.RotateInterval),
+	)
+}
+
+// fileBanner 返回按 FileEncoding 编码 Banner 的函数，切割后写到新文件开头
+func fileBanner(config Config) func() []byte {
+	fields := bannerFields(config)
+
+	return func() []byte {
+		var buf bytes.Buffer
+		core := newSinkCore(config, config.FileEncoding, false, zapcore.AddSync(&buf))
+		_ = core.Write(bannerEntry(), fields)
+
+		return buf.Bytes()
+	}
+}
+
+// setBanner 设置切割后写到新文件开头的 Banner，共享同一文件的多个 Logger 以第一次设置的为准
+func (h *fileHandle) setBanner(banner func() []byte) {
+	h.writer.mu.Lock()
+	defer h.writer.mu.Unlock()
+
+	if h.writer.banner == nil {
+		h.writer.banner = banner
+	}
+}
@@ -0,0 +1,104 @@
+package pplogger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBanner(t *testing.T) {
+	var buf syncBuffer
+	// Banner 不受等级过滤，LogLevel 为 Error 时仍以 Info 输出
+	logger, err := NewLogger(Config{
+		Encoding:     JSONEncoding,
+		LogLevel:     ErrorLevel,
+		Banner:       true,
+		AppName:      "svc",
+		AppVersion:   "1.2.3",
+		ExtraWriters: []io.Writer{&buf},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("filtered")
+	logger.Error("boom")
+	_ = logger.Sync()
+
+	lines := buf.lines()
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want banner and error: %q", len(lines), lines)
+	}
+	banner := decodeLine(t, lines[0])
+	want := map[string]interface{}{
+		"level":    "INFO",
+		"msg":      bannerMessage,
+		"app":      "svc",
+		"version":  "1.2.3",
+		"logLevel": "error",
+		"encoding": JSONEncoding,
+	}
+	for k, v := range want {
+		if banner[k] != v {
+			t.Errorf("banner %s = %#v, want %#v", k, banner[k], v)
+		}
+	}
+	for _, key := range []string{"host", "pid"} {
+		if _, ok := banner[key]; !ok {
+			t.Errorf("banner missing %q: %v", key, banner)
+		}
+	}
+}
+
+func TestBannerAfterRotation(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(Config{
+		FileWriter:    true,
+		LogPath:       dir,
+		Filename:      "app.log",
+		ErrorFilename: "error.log",
+		Encoding:      JSONEncoding,
+		LogLevel:      ErrorLevel,
+		Banner:        true,
+		MaxSize:       1,
+		MaxBackups:    10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按大小切割一次，再手动切割一次
+	line := strings.Repeat("x", 1000)
+	for i := 0; i < 1100; i++ {
+		logger.Error(line)
+	}
+	if err := logger.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Error("after")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readDir(t, dir)
+	var appFiles int
+	for name, content := range files {
+		first := decodeLine(t, strings.SplitN(content, "\n", 2)[0])
+		isBanner := first["msg"] == bannerMessage && first["level"] == "INFO"
+		// Error 文件只在切割后以 Banner 开头，创建时的 Banner 只写入主文件
+		if strings.HasPrefix(name, "app") {
+			appFiles++
+			if !isBanner {
+				t.Errorf("%s does not start with the banner: %v", name, first)
+			}
+		} else if name == "error.log" && !isBanner {
+			t.Errorf("rotated %s does not start with the banner: %v", name, first)
+		}
+	}
+	if appFiles != 3 {
+		t.Errorf("got %d app files, want the current file and two backups", appFiles)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "app.log")); !strings.Contains(string(b), `"msg":"after"`) {
+		t.Errorf("app.log = %q", b)
+	}
+}
@@ -0,0 +1,156 @@
+package pplogger
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+)
+
+// Builder 以链式调用的方式组装日志，设置过程中的错误在 Build 时统一返回
+type Builder struct {
+	config  Config
+	errs    []error
+	zapOpts []zap.Option
+}
+
+// NewBuilder 创建 Builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+func (b *Builder) apply(opt Option) *Builder {
+	if err := opt(&b.config); err != nil {
+		b.errs = append(b.errs, err)
+	}
+
+	return b
+}
+
+// File 输出到 path 目录下的 filename 文件
+func (b *Builder) File(path, filename string) *Builder {
+	return b.apply(WithFile(path, filename))
+}
+
+// Stdout 输出到控制台
+func (b *Builder) Stdout() *Builder {
+	return b.apply(WithStdout())
+}
+
+// Level 设置日志输出等级
+func (b *Builder) Level(level string) *Builder {
+	return b.apply(WithLevel(level))
+}
+
+// MaxSize 设置单个文件最大限制，单位 M
+func (b *Builder) MaxSize(maxSize int) *Builder {
+	return b.apply(WithRotation(maxSize, b.config.MaxBackups, b.config.MaxAge, b.config.Compress))
+}
+
+// MaxBackups 设置最多保留备份数
+func (b *Builder) MaxBackups(maxBackups int) *Builder {
+	return b.apply(WithRotation(b.config.MaxSize, maxBackups, b.config.MaxAge, b.config.Compress))
+}
+
+// MaxAge 设置最多保留天数
+func (b *Builder) MaxAge(maxAge int) *Builder {
+	return b.apply(WithRotation(b.config.MaxSize, b.config.MaxBackups, maxAge, b.config.Compress))
+}
+
+// Compress 压缩切割后的文件
+func (b *Builder) Compress() *Builder {
+	return b.apply(WithRotation(b.config.MaxSize, b.config.MaxBackups, b.config.MaxAge, true))
+}
+
+// JSON 使用 json 编码输出
+func (b *Builder) JSON() *Builder {
+	return b.apply(WithJSON())
+}
+
+// CallerSkip 设置调用者跳过的层数
+func (b *Builder) CallerSkip(n int) *Builder {
+	return b.apply(WithCallerSkip(n))
+}
+
+// Writer 追加额外的输出
+func (b *Builder) Writer(w io.Writer) *Builder {
+	return b.apply(WithWriter(w))
+}
+
+// ZapOptions 追加额外的 zap.Option，例如 zap.Hooks
+func (b *Builder) ZapOptions(opts ...zap.Option) *Builder {
+	b.zapOpts = append(b.zapOpts, opts...)
+	return b
+}
+
+// Clone 复制当前 Builder，便于基于同一套配置创建多个略有差异的日志
+func (b *Builder) Clone() *Builder {
+	return &Builder{
+		config:  cloneConfig(b.config),
+		errs:    append([]error(nil), b.errs...),
+		zapOpts: append([]zap.Option(nil), b.zapOpts...),
+	}
+}
+
+// cloneConfig 深拷贝 config 中的切片、map 及指针字段，避免副本之间互相影响
+func cloneConfig(config Config) Config {
+	config.ExtraWriters = append([]io.Writer(nil), config.ExtraWriters...)
+	config.Outputs = append([]string(nil), config.Outputs...)
+	config.StacktraceSkipPrefixes = append([]string(nil), config.StacktraceSkipPrefixes...)
+	config.DropPatterns = append([]string(nil), config.DropPatterns...)
+	config.AdditionalFiles = append([]FileTarget(nil), config.AdditionalFiles...)
+	config.Sinks = append([]Sink(nil), config.Sinks...)
+	config.Cores = append([]zapcore.Core(nil), config.Cores...)
+	config.ModuleLevels = cloneMap(config.ModuleLevels)
+	config.LevelOutputs = cloneMap(config.LevelOutputs)
+	config.InitialFields = cloneMap(config.InitialFields)
+
+	config.Sampling = clonePtr(config.Sampling)
+	config.RateLimit = clonePtr(config.RateLimit)
+	config.Syslog = clonePtr(config.Syslog)
+	config.RemoteSyslog = clonePtr(config.RemoteSyslog)
+	config.GELF = clonePtr(config.GELF)
+	config.UnixSocket = clonePtr(config.UnixSocket)
+	config.TCP = clonePtr(config.TCP)
+	config.AlertWebhook = clonePtr(config.AlertWebhook)
+	if config.Email = clonePtr(config.Email); config.Email != nil {
+		config.Email.To = append([]string(nil), config.Email.To...)
+	}
+
+	return config
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+
+	clone := make(map[K]V, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+
+	clone := *p
+	return &clone
+}
+
+// Build 创建日志，未指定任何输出时默认输出到控制台。返回的日志无法关闭，见 New
+func (b *Builder) Build() (*zap.Logger, error) {
+	if len(b.errs) > 0 {
+		return nil, errors.Join(b.errs...)
+	}
+
+	logger, err := newLogger([]Option{WithConfig(b.config), withDefaultStdout()}, b.zapOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return logger.Logger, nil
+}
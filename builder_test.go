@@ -0,0 +1,67 @@
+package pplogger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuilderClone(t *testing.T) {
+	var shared [3]syncBuffer
+	base := NewBuilder()
+	for i := range shared {
+		base.Writer(&shared[i])
+	}
+
+	// 两个副本各自追加输出后再创建日志，不应共享 ExtraWriters 的底层数组
+	var x, y syncBuffer
+	cloneX := base.Clone().Writer(&x)
+	cloneY := base.Clone().Writer(&y)
+	loggerX, err := cloneX.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loggerY, err := cloneY.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loggerX.Info("from x")
+	loggerY.Info("from y")
+	_ = loggerX.Sync()
+	_ = loggerY.Sync()
+
+	if got := x.String(); !strings.Contains(got, "from x") || strings.Contains(got, "from y") {
+		t.Errorf("x = %q, want only the entry from x", got)
+	}
+	if got := y.String(); !strings.Contains(got, "from y") || strings.Contains(got, "from x") {
+		t.Errorf("y = %q, want only the entry from y", got)
+	}
+	for i := range shared {
+		if n := len(shared[i].lines()); n != 2 {
+			t.Errorf("shared writer %d got %d lines, want 2", i, n)
+		}
+	}
+}
+
+func TestCloneConfig(t *testing.T) {
+	config := Config{
+		Outputs:       []string{"stdout"},
+		ModuleLevels:  map[string]string{"db": "debug"},
+		InitialFields: map[string]interface{}{"app": "svc"},
+		DropPatterns:  []string{"noisy"},
+		Sampling:      &SamplingConfig{Initial: 1},
+		Email:         &EmailConfig{To: []string{"a@example.com"}},
+	}
+	clone := cloneConfig(config)
+	clone.Outputs[0] = "stderr"
+	clone.ModuleLevels["db"] = "error"
+	clone.InitialFields["app"] = "other"
+	clone.DropPatterns[0] = "other"
+	clone.Sampling.Initial = 2
+	clone.Email.To[0] = "b@example.com"
+
+	if config.Outputs[0] != "stdout" || config.ModuleLevels["db"] != "debug" || config.InitialFields["app"] != "svc" ||
+		config.DropPatterns[0] != "noisy" || config.Sampling.Initial != 1 || config.Email.To[0] != "a@example.com" {
+		t.Errorf("modifying the clone changed the original: %+v", config)
+	}
+}
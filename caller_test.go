@@ -0,0 +1,50 @@
+package pplogger
+
+import (
+	"bytes"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// logWrapped 模拟调用方封装的一层日志函数
+func logWrapped(logger *zap.Logger, msg string) {
+	logger.Info(msg)
+}
+
+func TestCallerSkip(t *testing.T) {
+	// 丢弃控制台输出，只检查 ExtraWriters 中的内容
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	var buf bytes.Buffer
+	logger, _, err := NewPPLoggerE(Config{
+		StdoutWriter: true,
+		Encoding:     "json",
+		CallerSkip:   1,
+		ExtraWriters: []io.Writer{&buf},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logWrapped(logger, "wrapped")
+	_, _, line, _ := runtime.Caller(0)
+	_ = logger.Sync()
+
+	// 跳过一层后应报告调用 logWrapped 的位置
+	want := fmt.Sprintf(`/caller_test.go:%d"`, line-1)
+	if out := buf.String(); !strings.Contains(out, want) {
+		t.Errorf("output %q does not contain caller %q", out, want)
+	}
+}
@@ -0,0 +1,483 @@
+// Package cloudwatch 将日志直接发送到 AWS CloudWatch Logs，无需安装 agent，通过 pplogger.Config.Cores 接入：
+//
+//	core, err := cloudwatch.NewCore(cloudwatch.Config{Group: "/app/api", Stream: "i-0123456789"})
+//	config.Cores = append(config.Cores, core)
+//
+// 凭证通过 AWS SDK 的默认链获取（环境变量、共享配置文件、EC2 实例角色等）。
+// 日志组及日志流不存在时自动创建，事件时间取日志的时间，每批按时间排序后发送
+package cloudwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/piaoyunsoft/pplogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// PutLogEvents 的限制
+const (
+	maxBatchEvents = 10000
+	maxBatchBytes  = 1048576
+	eventOverhead  = 26 // 每个事件额外计入的字节数
+	maxBatchSpan   = 24 * time.Hour
+	maxEventAge    = 14 * 24 * time.Hour
+	maxEventAhead  = 2 * time.Hour
+)
+
+const (
+	defaultFlushInterval = 5 * time.Second
+	defaultBufferSize    = 10000
+	defaultFlushTimeout  = 5 * time.Second
+	requestTimeout       = 30 * time.Second
+	minBackoff           = 100 * time.Millisecond
+	maxBackoff           = 30 * time.Second
+	maxRetries           = 8
+)
+
+// API 是 Core 用到的 CloudWatch Logs 接口，*cloudwatchlogs.Client 实现了该接口
+type API interface {
+	PutLogEvents(ctx context.Context, params *cloudwatchlogs.PutLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+	CreateLogGroup(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	CreateLogStream(ctx context.Context, params *cloudwatchlogs.CreateLogStreamInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error)
+}
+
+// Config 是 CloudWatch Logs 输出的配置
+type Config struct {
+	Group  string // 日志组，不存在时自动创建
+	Stream string // 日志流，不存在时自动创建
+	Region string // 为空时使用默认链中的区域，例如 AWS_REGION
+
+	Level   string          // 最低发送等级，取值同 pplogger.Config.LogLevel，默认不限制，仍受 Logger 等级控制
+	Encoder zapcore.Encoder // 日志的编码，默认使用 ISO8601 时间的 json 编码
+
+	FlushInterval time.Duration // 攒批的最长时间，默认 5s，攒满 10000 条或 1M 时立即发送
+	BufferSize    int           // 内存中最多缓冲的日志条数，默认 10000，缓冲区满时丢弃
+	FlushTimeout  time.Duration // Sync 及 Close 等待发送的最长时间，默认 5s
+
+	Client API // 自定义客户端，用于测试，设置后 Region 不生效
+}
+
+// Stats 是 Core 的运行统计
+type Stats struct {
+	Sent    uint64 // 已发送的日志条数
+	Dropped uint64 // 因缓冲区已满、时间超前或发送失败而丢弃的日志条数
+	Expired uint64 // 早于 14 天的写入期限或被服务端以过期拒收而丢弃的日志条数
+}
+
+// Core 实现 zapcore.Core 与 io.Closer，日志编码后放入缓冲区，由后台 goroutine 攒批发送
+type Core struct {
+	zapcore.LevelEnabler
+	enc    zapcore.Encoder
+	sender *sender
+}
+
+// NewCore 创建 Core，交给 Logger 时由 Logger 关闭
+func NewCore(config Config) (*Core, error) {
+	if config.Group == "" || config.Stream == "" {
+		return nil, errors.New("pplogger/cloudwatch: Group and Stream are required")
+	}
+	if config.FlushInterval < 0 || config.BufferSize < 0 || config.FlushTimeout < 0 {
+		return nil, errors.New("pplogger/cloudwatch: negative FlushInterval, BufferSize or FlushTimeout")
+	}
+
+	var level zapcore.LevelEnabler = zapcore.DebugLevel
+	if config.Level != "" {
+		l, err := pplogger.ParseLevel(config.Level)
+		if err != nil {
+			return nil, fmt.Errorf("pplogger/cloudwatch: %w", err)
+		}
+		level = l
+	}
+
+	if config.Encoder == nil {
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		config.Encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+	if config.FlushInterval == 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	if config.BufferSize == 0 {
+		config.BufferSize = defaultBufferSize
+	}
+	if config.FlushTimeout == 0 {
+		config.FlushTimeout = defaultFlushTimeout
+	}
+
+	if config.Client == nil {
+		var opts []func(*awsconfig.LoadOptions) error
+		if config.Region != "" {
+			opts = append(opts, awsconfig.WithRegion(config.Region))
+		}
+		awsConfig, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("pplogger/cloudwatch: %w", err)
+		}
+		config.Client = cloudwatchlogs.NewFromConfig(awsConfig)
+	}
+
+	s := &sender{
+		config: config,
+		queue:  make(chan event, config.BufferSize),
+		flush:  make(chan chan struct{}),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+
+	return &Core{LevelEnabler: level, enc: config.Encoder, sender: s}, nil
+}
+
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimRight(buf.String(), "\r\n")
+	buf.Free()
+
+	c.sender.add(event{time: ent.Time, msg: msg})
+	if ent.Level > zapcore.ErrorLevel {
+		// DPanic、Panic 与 Fatal 之后进程可能立即退出，先等待发送完成
+		_ = c.Sync()
+	}
+
+	return nil
+}
+
+// Sync 最多等待 FlushTimeout 发送缓冲区中的日志
+func (c *Core) Sync() error {
+	c.sender.sync()
+	return nil
+}
+
+// Close 停止后台发送，最多等待 FlushTimeout 发送剩余的日志
+func (c *Core) Close() error {
+	c.sender.close()
+	return nil
+}
+
+// Stats 返回 Core 的运行统计
+func (c *Core) Stats() Stats {
+	return Stats{
+		Sent:    c.sender.sent.Load(),
+		Dropped: c.sender.dropped.Load(),
+		Expired: c.sender.expired.Load(),
+	}
+}
+
+type event struct {
+	time time.Time
+	msg  string
+}
+
+func (e event) size() int {
+	return len(e.msg) + eventOverhead
+}
+
+// sender 由 Core 及其 With 派生的 Core 共享
+type sender struct {
+	config    Config
+	queue     chan event
+	flush     chan chan struct{}
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	token   *string // 上一次 PutLogEvents 返回的 sequence token
+	created bool    // 是否已确认日志组及日志流存在
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	expired atomic.Uint64
+}
+
+func (s *sender) add(e event) {
+	// 单个事件不能超过一批的大小
+	if e.size() > maxBatchBytes {
+		e.msg = truncate(e.msg, maxBatchBytes-eventOverhead)
+	}
+
+	select {
+	case s.queue <- e:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+func (s *sender) sync() {
+	ack := make(chan struct{})
+	select {
+	case s.flush <- ack:
+		select {
+		case <-ack:
+		case <-time.After(s.config.FlushTimeout):
+		}
+	case <-s.done:
+	case <-time.After(s.config.FlushTimeout):
+	}
+}
+
+func (s *sender) close() {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+		select {
+		case <-s.done:
+		case <-time.After(s.config.FlushTimeout):
+		}
+	})
+}
+
+func (s *sender) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	var pending []event
+	size := 0
+	for {
+		select {
+		case e := <-s.queue:
+			pending = append(pending, e)
+			size += e.size()
+			if len(pending) < maxBatchEvents && size < maxBatchBytes {
+				continue
+			}
+		case <-ticker.C:
+		case ack := <-s.flush:
+			pending = s.drain(pending)
+			s.send(pending)
+			pending, size = nil, 0
+			close(ack)
+			continue
+		case <-s.quit:
+			s.send(s.drain(pending))
+			return
+		}
+
+		s.send(pending)
+		pending, size = nil, 0
+	}
+}
+
+// drain 取出缓冲区中已有的日志
+func (s *sender) drain(pending []event) []event {
+	for {
+		select {
+		case e := <-s.queue:
+			pending = append(pending, e)
+		default:
+			return pending
+		}
+	}
+}
+
+// send 按时间排序后按 PutLogEvents 的限制分批发送，丢弃超出写入期限的日志
+func (s *sender) send(events []event) {
+	if len(events) == 0 {
+		return
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].time.Before(events[j].time)
+	})
+
+	now := time.Now()
+	var batch []types.InputLogEvent
+	var first time.Time
+	size := 0
+	for _, e := range events {
+		if e.time.Before(now.Add(-maxEventAge)) {
+			s.expired.Add(1)
+			continue
+		}
+		if e.time.After(now.Add(maxEventAhead)) {
+			s.dropped.Add(1)
+			continue
+		}
+
+		if len(batch) > 0 && (len(batch) == maxBatchEvents || size+e.size() > maxBatchBytes || e.time.Sub(first) > maxBatchSpan) {
+			s.put(batch)
+			batch, size = nil, 0
+		}
+		if len(batch) == 0 {
+			first = e.time
+		}
+		batch = append(batch, types.InputLogEvent{
+			Message:   aws.String(e.msg),
+			Timestamp: aws.Int64(e.time.UnixMilli()),
+		})
+		size += e.size()
+	}
+
+	if len(batch) > 0 {
+		s.put(batch)
+	}
+}
+
+// put 发送一批日志，限流或服务不可用时按指数退避重试，sequence token 不匹配时使用服务端返回的 token 重试
+func (s *sender) put(batch []types.InputLogEvent) {
+	backoff := minBackoff
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if !s.created {
+			if err := s.create(); err != nil {
+				if !s.wait(&backoff) {
+					break
+				}
+				continue
+			}
+			s.created = true
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		out, err := s.config.Client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+			LogGroupName:  aws.String(s.config.Group),
+			LogStreamName: aws.String(s.config.Stream),
+			LogEvents:     batch,
+			SequenceToken: s.token,
+		})
+		cancel()
+
+		var invalidToken *types.InvalidSequenceTokenException
+		var accepted *types.DataAlreadyAcceptedException
+		var notFound *types.ResourceNotFoundException
+		switch {
+		case err == nil:
+			s.token = out.NextSequenceToken
+			n := rejected(out.RejectedLogEventsInfo, len(batch))
+			s.sent.Add(uint64(len(batch) - n))
+			s.expired.Add(uint64(n))
+			return
+		case errors.As(err, &accepted):
+			s.token = accepted.ExpectedSequenceToken
+			s.sent.Add(uint64(len(batch)))
+			return
+		case errors.As(err, &invalidToken):
+			s.token = invalidToken.ExpectedSequenceToken
+			continue
+		case errors.As(err, &notFound):
+			s.created, s.token = false, nil
+			continue
+		case !retryable(err):
+			s.dropped.Add(uint64(len(batch)))
+			return
+		}
+
+		if !s.wait(&backoff) {
+			break
+		}
+	}
+
+	s.dropped.Add(uint64(len(batch)))
+}
+
+// wait 等待一次退避时间，关闭时不再等待，返回 false 表示放弃重试
+func (s *sender) wait(backoff *time.Duration) bool {
+	select {
+	case <-time.After(*backoff):
+	case <-s.quit:
+		return false
+	}
+	*backoff = min(*backoff*2, maxBackoff)
+
+	return true
+}
+
+// create 创建日志组及日志流，已存在时忽略
+func (s *sender) create() error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	var exists *types.ResourceAlreadyExistsException
+	if _, err := s.config.Client.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(s.config.Group),
+	}); err != nil && !errors.As(err, &exists) {
+		return err
+	}
+
+	if _, err := s.config.Client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(s.config.Group),
+		LogStreamName: aws.String(s.config.Stream),
+	}); err != nil && !errors.As(err, &exists) {
+		return err
+	}
+
+	return nil
+}
+
+// rejected 返回服务端拒收的日志条数
+func rejected(info *types.RejectedLogEventsInfo, n int) int {
+	if info == nil {
+		return 0
+	}
+
+	count := 0
+	for _, end := range []*int32{info.TooOldLogEventEndIndex, info.ExpiredLogEventEndIndex} {
+		if end != nil {
+			count = max(count, int(*end))
+		}
+	}
+	if info.TooNewLogEventStartIndex != nil {
+		count += n - int(*info.TooNewLogEventStartIndex)
+	}
+
+	return min(count, n)
+}
+
+// retryable 判断错误是否可以重试，限流、服务端错误及网络错误可以重试
+func retryable(err error) bool {
+	var throttling *types.ThrottlingException
+	var unavailable *types.ServiceUnavailableException
+	var limit *types.LimitExceededException
+	var aborted *types.OperationAbortedException
+	var invalid *types.InvalidParameterException
+	var denied *types.AccessDeniedException
+	var unrecognized *types.UnrecognizedClientException
+	switch {
+	case errors.As(err, &throttling), errors.As(err, &unavailable), errors.As(err, &limit), errors.As(err, &aborted):
+		return true
+	case errors.As(err, &invalid), errors.As(err, &denied), errors.As(err, &unrecognized):
+		return false
+	}
+
+	return true
+}
+
+// truncate 按字符边界截断 s，使其不超过 n 字节
+func truncate(s string, n int) string {
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,326 @@
+package pplogger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config.Compression 的取值
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+const (
+	gzipExt = ".gz"
+	zstdExt = ".zst"
+)
+
+// compression 返回实际使用的压缩方式，Compression 为空时按 Compress 使用 gzip，不压缩时返回空字符串
+func (config Config) compression() string {
+	switch strings.ToLower(config.Compression) {
+	case "":
+		if config.Compress {
+			return CompressionGzip
+		}
+	case CompressionGzip:
+		return CompressionGzip
+	case CompressionZstd:
+		return CompressionZstd
+	}
+
+	return ""
+}
+
+// validCompression 检查 Config.Compression 及 CompressionLevel
+func (config Config) validCompression() error {
+	switch strings.ToLower(config.Compression) {
+	case "", CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return fmt.Errorf("unknown Compression %q, want %s, %s or %s", config.Compression, CompressionNone, CompressionGzip, CompressionZstd)
+	}
+
+	if config.CompressionLevel < 0 || config.CompressionLevel > 9 {
+		return fmt.Errorf("invalid CompressionLevel %d, want 1 to 9", config.CompressionLevel)
+	}
+
+	if config.CompressDelay < 0 {
+		return fmt.Errorf("negative CompressDelay %s", config.CompressDelay)
+	}
+
+	return nil
+}
+
+// zstdLevel 将 1 到 9 的压缩等级对应到 zstd 的等级，0 为默认等级
+func zstdLevel(level int) zstd.EncoderLevel {
+	switch {
+	case level == 0:
+		return zstd.SpeedDefault
+	case level <= 2:
+		return zstd.SpeedFastest
+	case level <= 5:
+		return zstd.SpeedDefault
+	case level <= 8:
+		return zstd.SpeedBetterCompression
+	}
+
+	return zstd.SpeedBestCompression
+}
+
+// trimCompressExt 去掉压缩文件的扩展名
+func trimCompressExt(name string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(name, gzipExt), zstdExt)
+}
+
+// ownMill 返回备份是否由 mill 清理及压缩，而不是交给 lumberjack：
+// FilenamePattern 格式的备份 lumberjack 无法识别，lumberjack 也不支持 zstd、压缩等级及延迟压缩
+func (w *fileWriter) ownMill() bool {
+	return w.pattern != nil || w.compression != ""
+}
+
+// mill 清理备份文件并将未压缩的交给 compressor，规则与 lumberjack 相同：超过 MaxBackups 个或早于 MaxAge 天的删除，
+// 已压缩及未压缩的备份一起计算，新旧按文件的修改时间。启动时同样执行一次，
+// 清理上次崩溃时未完成的压缩（*.tmp）并重新压缩
+func (w *fileWriter) mill(now time.Time) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	dir := filepath.Dir(w.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	backups := w.backups()
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	var files []backup
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || path == w.path {
+			continue
+		}
+		// compressor 压缩时同样持有 millMu，此时存在的临时文件都是之前未完成的
+		if tmp := strings.TrimSuffix(path, ".tmp"); tmp != path && tmp != trimCompressExt(tmp) && backups[trimCompressExt(tmp)] {
+			_ = os.Remove(path)
+			continue
+		}
+		if !backups[trimCompressExt(path)] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, backup{path: path, modTime: info.ModTime()})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+
+	cutoff := now.Add(-time.Duration(w.maxAge) * 24 * time.Hour)
+	for i, f := range files {
+		if (w.maxBackups > 0 && i >= w.maxBackups) || (w.maxAge > 0 && f.modTime.Before(cutoff)) {
+			_ = os.Remove(f.path)
+			continue
+		}
+		if w.compression != "" && f.path == trimCompressExt(f.path) {
+			compressor.add(w, f.path)
+		}
+	}
+}
+
+// compressor 在后台逐个压缩所有日志文件的备份，每个备份在切割 CompressDelay 之后才压缩，
+// 避免切割时集中占用 CPU。Logger 关闭时完成正在压缩的文件，尚未开始的在下次启动时由 mill 重新加入
+var compressor = newCompressQueue()
+
+type compressQueue struct {
+	mu      sync.Mutex
+	idle    sync.Cond // current 完成时通知 finish
+	jobs    map[string]*compressJob
+	current *compressJob
+	running bool
+	wake    chan struct{} // 加入更早的任务时唤醒等待中的 run
+}
+
+func newCompressQueue() *compressQueue {
+	q := &compressQueue{
+		jobs: make(map[string]*compressJob),
+		wake: make(chan struct{}, 1),
+	}
+	q.idle.L = &q.mu
+
+	return q
+}
+
+type compressJob struct {
+	w    *fileWriter
+	path string
+	due  time.Time
+}
+
+// add 将备份 path 加入队列，在 CompressDelay 之后压缩，已在队列中时忽略
+func (q *compressQueue) add(w *fileWriter, path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.jobs[path]; ok {
+		return
+	}
+	q.jobs[path] = &compressJob{w: w, path: path, due: time.Now().Add(w.compressDelay)}
+
+	if !q.running {
+		q.running = true
+		go q.run()
+		return
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 按到期时间逐个压缩，队列为空时退出，之后 add 时重新启动
+func (q *compressQueue) run() {
+	for {
+		q.mu.Lock()
+		var job *compressJob
+		for _, j := range q.jobs {
+			if job == nil || j.due.Before(job.due) {
+				job = j
+			}
+		}
+		if job == nil {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		if wait := time.Until(job.due); wait > 0 {
+			q.mu.Unlock()
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-q.wake:
+				timer.Stop()
+			}
+			continue
+		}
+		delete(q.jobs, job.path)
+		q.current = job
+		q.mu.Unlock()
+
+		job.w.compressBackup(job.path)
+
+		q.mu.Lock()
+		q.current = nil
+		q.idle.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// finish 移除 w 尚未开始的压缩任务，并等待正在压缩的 w 的备份完成
+func (q *compressQueue) finish(w *fileWriter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for path, job := range q.jobs {
+		if job.w == w {
+			delete(q.jobs, path)
+		}
+	}
+	for q.current != nil && q.current.w == w {
+		q.idle.Wait()
+	}
+}
+
+// compressBackup 压缩备份 path，已被删除或已被其他进程压缩时跳过
+func (w *fileWriter) compressBackup(path string) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if fileExists(path+gzipExt) || fileExists(path+zstdExt) {
+		return
+	}
+
+	_ = compressFile(path, info.ModTime(), w.compression, w.compressionLevel)
+}
+
+// compressFile 按 compression 将 name 压缩为 name.gz 或 name.zst 后删除原文件。
+// 先写入 .tmp 并 fsync 再重命名，崩溃时只会留下 .tmp 及完整的原文件。压缩文件保留原文件的修改时间
+func compressFile(name string, modTime time.Time, compression string, level int) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	target := name + gzipExt
+	if compression == CompressionZstd {
+		target = name + zstdExt
+	}
+	tmp := target + ".tmp"
+
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	var enc io.WriteCloser
+	if compression == CompressionZstd {
+		enc, err = zstd.NewWriter(dst, zstd.WithEncoderLevel(zstdLevel(level)), zstd.WithEncoderConcurrency(1))
+	} else {
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		enc, err = gzip.NewWriterLevel(dst, level)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(enc, src); err != nil {
+		return err
+	}
+	if err = enc.Close(); err != nil {
+		return err
+	}
+	if err = dst.Sync(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	_ = os.Chtimes(tmp, modTime, modTime)
+	if err = os.Rename(tmp, target); err != nil {
+		return err
+	}
+	_ = src.Close()
+
+	return os.Remove(name)
+}
@@ -0,0 +1,186 @@
+package pplogger
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// validPathPattern 检查 Config.PathPattern 生成的是 LogPath 下的相对目录
+func validPathPattern(pattern string) error {
+	dir := time.Now().Format(pattern)
+	if filepath.IsAbs(dir) || strings.Contains(dir, "..") {
+		return fmt.Errorf("invalid PathPattern %q, want a relative directory layout such as 2006/01/02", pattern)
+	}
+
+	return nil
+}
+
+// newDatedFileWriter 创建按日期分目录的日志文件，目录为 LogPath/<当前时间按 PathPattern 格式化>
+func newDatedFileWriter(config Config) (*datedFileWriter, error) {
+	w := &datedFileWriter{config: config, loc: timeLocation(config.TimeZone), now: time.Now}
+	if err := w.open(w.now()); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// datedFileWriter 每次写入时检查当前时间对应的目录，变化时（例如跨过零点）在新目录下打开文件并关闭之前的文件，
+// 同一目录内仍按 MaxSize 切割
+type datedFileWriter struct {
+	mu       sync.Mutex
+	config   Config
+	loc      *time.Location
+	now      func() time.Time // 便于测试替换时钟
+	dir      string
+	sec      int64 // 上次检查目录时的秒数，同一秒内不再重复格式化
+	handle   *fileHandle
+	onRotate func(string)
+	link     *currentLink
+	onRemove func(string, int64)
+	banner   func() []byte
+	janitor  *retentionJanitor
+	owner    *Logger // 交给 janitor 时输出删除记录的 Logger
+	closed   bool
+}
+
+func (w *datedFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return len(p), nil
+	}
+
+	if now := w.now(); now.Unix() != w.sec {
+		if err := w.open(now); err != nil {
+			// 新目录无法打开时继续写入之前的文件
+			_, _ = w.handle.Write(p)
+			return 0, err
+		}
+	}
+
+	return w.handle.Write(p)
+}
+
+// open 在 now 对应的目录与当前不同时切换文件
+func (w *datedFileWriter) open(now time.Time) error {
+	w.sec = now.Unix()
+	dir := now.In(w.loc).Format(w.config.PathPattern)
+	if w.handle != nil && dir == w.dir {
+		return nil
+	}
+
+	config := w.config
+	logPath, err := resolveLogPath(filepath.Join(config.LogPath, dir))
+	if err != nil {
+		return err
+	}
+	config.LogPath = logPath
+
+	handle, err := acquireFileWriter(config)
+	if err != nil {
+		return err
+	}
+	if w.onRotate != nil {
+		handle.setOnRotate(w.onRotate)
+	}
+	if w.link != nil {
+		handle.setLink(w.link)
+	}
+	if w.onRemove != nil {
+		handle.setOnRemove(w.onRemove)
+	}
+	if w.banner != nil {
+		handle.setBanner(w.banner)
+	}
+	if w.janitor != nil {
+		handle.setJanitor(w.janitor, w.owner)
+	}
+
+	if w.handle != nil {
+		_ = w.handle.Close()
+		// 切换到新目录的文件同样以 Banner 开头
+		if w.banner != nil {
+			_, _ = handle.Write(w.banner())
+		}
+	}
+	w.handle, w.dir = handle, dir
+
+	return nil
+}
+
+// setOnRotate 设置切割回调，之后切换的文件同样生效
+func (w *datedFileWriter) setOnRotate(onRotate func(string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onRotate = onRotate
+	w.handle.setOnRotate(onRotate)
+}
+
+// setLink 使 link 指向当前文件，切换目录后同样更新
+func (w *datedFileWriter) setLink(link *currentLink) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.link = link
+	w.handle.setLink(link)
+}
+
+// setOnRemove 设置 MaxTotalSize 删除备份后的回调，之后切换的文件同样生效
+func (w *datedFileWriter) setOnRemove(onRemove func(string, int64)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onRemove = onRemove
+	w.handle.setOnRemove(onRemove)
+}
+
+// setBanner 设置切割后写到新文件开头的 Banner，之后切换的文件同样生效
+func (w *datedFileWriter) setBanner(banner func() []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.banner = banner
+	w.handle.setBanner(banner)
+}
+
+// setJanitor 将当前文件交给 j 管理，之后切换的文件同样生效
+func (w *datedFileWriter) setJanitor(j *retentionJanitor, logger *Logger) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.janitor, w.owner = j, logger
+	w.handle.setJanitor(j, logger)
+}
+
+func (w *datedFileWriter) forceRotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	return w.handle.forceRotate()
+}
+
+func (w *datedFileWriter) Sync() error {
+	return nil
+}
+
+func (w *datedFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	return w.handle.Close()
+}
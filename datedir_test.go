@@ -0,0 +1,122 @@
+package pplogger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock 返回可手动推进的时钟
+func fakeClock(now time.Time) (func() time.Time, func(time.Duration)) {
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestDatedFileWriterMidnight(t *testing.T) {
+	dir := t.TempDir()
+	w, err := newDatedFileWriter(Config{LogPath: dir, Filename: "app.log", PathPattern: "2006/01/02", TimeZone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now, advance := fakeClock(time.Date(2024, 6, 15, 23, 59, 59, 0, time.UTC))
+	w.now = now
+
+	for _, line := range []string{"a\n", "b\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	advance(2 * time.Second)
+	if _, err := w.Write([]byte("c\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, want := range map[string]string{"2024/06/15/app.log": "a\nb\n", "2024/06/16/app.log": "c\n"} {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("%s = %q, want %q", file, b, want)
+		}
+	}
+}
+
+func TestDatedFileWriterTimeZone(t *testing.T) {
+	dir := t.TempDir()
+	// UTC 15 日 20 点在 UTC+8 已是 16 日
+	w, err := newDatedFileWriter(Config{LogPath: dir, Filename: "app.log", PathPattern: "2006-01-02", TimeZone: "Asia/Shanghai"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now, _ = fakeClock(time.Date(2024, 6, 15, 20, 0, 0, 0, time.UTC))
+	if _, err := w.Write([]byte("a\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "2024-06-16", "app.log")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDatedFileWriterSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	w, err := newDatedFileWriter(Config{LogPath: dir, Filename: "app.log", PathPattern: "2006/01/02", TimeZone: "UTC", MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now, _ = fakeClock(time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC))
+	line := []byte(strings.Repeat("x", 600<<10) + "\n")
+	for i := 0; i < 2; i++ {
+		if _, err := w.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一天内超过 MaxSize 时在当天目录中切割
+	entries, err := os.ReadDir(filepath.Join(dir, "2024", "06", "15"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("got %d files in the day directory, want the current file and one backup", len(entries))
+	}
+}
+
+func TestPathPattern(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(Config{FileWriter: true, LogPath: dir, Filename: "app.log", PathPattern: "2006-01-02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, time.Now().Format("2006-01-02"), "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "hello") {
+		t.Errorf("file = %q", b)
+	}
+}
+
+func TestPathPatternInvalid(t *testing.T) {
+	for _, pattern := range []string{"/2006/01/02", "../2006"} {
+		if err := (Config{FileWriter: true, PathPattern: pattern}).Validate(); err == nil {
+			t.Errorf("Validate accepted PathPattern %q", pattern)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package pplogger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dedupState 是 dedupCore 及其 With 产生的 Core 共享的状态，记录上一条日志及其重复次数
+type dedupState struct {
+	mu      sync.Mutex
+	window  time.Duration
+	core    zapcore.Core // 输出汇总
+	hash    uint64
+	has     bool          // hash 有效，即上一条日志仍在窗口内
+	ent     zapcore.Entry // 上一条输出的日志
+	repeats int
+	last    time.Time // 最后一次重复的时间
+	timer   *time.Timer
+	gen     uint64 // 每次 take 加一，过期的 timer 回调据此忽略
+}
+
+// dedupCore 丢弃窗口内连续重复（等级、logger 名称、消息及字段都相同）的日志，
+// 遇到不同的日志、窗口结束或 Sync 时输出一条 "last message repeated N times"，见 Config.DedupWindow
+type dedupCore struct {
+	zapcore.Core
+	state *dedupState
+	enc   zapcore.Encoder // 只编码字段，包括 With 附加的，用于计算 hash
+}
+
+func newDedupCore(core zapcore.Core, window time.Duration) zapcore.Core {
+	return &dedupCore{
+		Core:  core,
+		state: &dedupState{window: window, core: core},
+		enc:   zapcore.NewJSONEncoder(zapcore.EncoderConfig{}),
+	}
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	return &dedupCore{Core: c.Core.With(fields), state: c.state, enc: enc}
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	hash, ok := c.hash(ent, fields)
+
+	s := c.state
+	s.mu.Lock()
+	if ok && s.has && hash == s.hash && ent.Time.Sub(s.ent.Time) < s.window {
+		if s.repeats == 0 {
+			gen := s.gen
+			s.timer = time.AfterFunc(s.window-ent.Time.Sub(s.ent.Time), func() { s.expire(gen) })
+		}
+		s.repeats++
+		s.last = ent.Time
+		s.mu.Unlock()
+		return nil
+	}
+	summary, pending := s.take()
+	msg := s.ent.Message
+	s.hash, s.has, s.ent = hash, ok, ent
+	s.mu.Unlock()
+
+	if pending {
+		_ = s.core.Write(summary, []zapcore.Field{zap.String("original_message", msg)})
+	}
+
+	return c.Core.Write(ent, fields)
+}
+
+// hash 计算等级、logger 名称、消息及编码后的字段的 hash，字段无法编码时返回 false，此时不去重
+func (c *dedupCore) hash(ent zapcore.Entry, fields []zapcore.Field) (uint64, bool) {
+	buf, err := c.enc.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		return 0, false
+	}
+	defer buf.Free()
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strconv.Itoa(int(ent.Level))))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(ent.LoggerName))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(ent.Message))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(buf.Bytes())
+
+	return h.Sum64(), true
+}
+
+func (c *dedupCore) Sync() error {
+	c.state.flush()
+
+	return c.Core.Sync()
+}
+
+// take 返回待输出的汇总并清零计数，调用方需持有 mu
+func (s *dedupState) take() (zapcore.Entry, bool) {
+	s.gen++
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.repeats == 0 {
+		return zapcore.Entry{}, false
+	}
+
+	summary := zapcore.Entry{
+		Level:      s.ent.Level,
+		Time:       s.last,
+		LoggerName: s.ent.LoggerName,
+		Message:    "last message repeated " + strconv.Itoa(s.repeats) + " times",
+	}
+	s.repeats = 0
+
+	return summary, true
+}
+
+// expire 在窗口结束时输出汇总，之后相同的日志重新开始计数。gen 不是当前的代数时说明汇总已被 take 取走，直接忽略
+func (s *dedupState) expire(gen uint64) {
+	s.mu.Lock()
+	if s.gen != gen {
+		s.mu.Unlock()
+		return
+	}
+	summary, pending := s.take()
+	msg := s.ent.Message
+	s.has = false
+	s.mu.Unlock()
+
+	if pending {
+		_ = s.core.Write(summary, []zapcore.Field{zap.String("original_message", msg)})
+	}
+}
+
+// flush 立即输出尚未输出的汇总，窗口不变
+func (s *dedupState) flush() {
+	s.mu.Lock()
+	summary, pending := s.take()
+	msg := s.ent.Message
+	s.mu.Unlock()
+
+	if pending {
+		_ = s.core.Write(summary, []zapcore.Field{zap.String("original_message", msg)})
+	}
+}
@@ -0,0 +1,96 @@
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newDedupTestLogger(window time.Duration) (*zap.Logger, *syncBuffer) {
+	buf := &syncBuffer{}
+	core := newDedupCore(newSinkCore(Config{Encoding: JSONEncoding}, "", false, buf), window)
+
+	return zap.New(core), buf
+}
+
+func TestDedupCollapsesRepeats(t *testing.T) {
+	logger, buf := newDedupTestLogger(200 * time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("same", zap.Int("a", 1))
+	}
+	logger.Info("same", zap.Int("a", 2))
+	for i := 0; i < 3; i++ {
+		logger.With(zap.String("x", "y")).Warn("w")
+	}
+	_ = logger.Sync()
+
+	lines := buf.lines()
+	want := []string{`"msg":"same","a":1`, `"msg":"last message repeated 4 times","original_message":"same"`, `"msg":"same","a":2`, `"msg":"w","x":"y"`, `"msg":"last message repeated 2 times","original_message":"w"`}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), buf)
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("line %d = %s, want %s", i, lines[i], w)
+		}
+	}
+}
+
+func TestDedupWindowExpires(t *testing.T) {
+	logger, buf := newDedupTestLogger(50 * time.Millisecond)
+
+	logger.Info("same")
+	logger.Info("same")
+	time.Sleep(150 * time.Millisecond)
+	if lines := buf.lines(); len(lines) != 2 || !strings.Contains(lines[1], "repeated 1 times") {
+		t.Fatalf("summary not emitted when the window expired:\n%s", buf)
+	}
+
+	// 窗口结束后重新开始计数
+	logger.Info("same")
+	if lines := buf.lines(); len(lines) != 3 || !strings.Contains(lines[2], `"msg":"same"`) {
+		t.Fatalf("entry after the window was suppressed:\n%s", buf)
+	}
+}
+
+func TestDedupConcurrent(t *testing.T) {
+	logger, buf := newDedupTestLogger(time.Millisecond)
+
+	const goroutines, entries = 4, 2000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				logger.Info("same")
+				if i%100 == 0 {
+					time.Sleep(2 * time.Millisecond)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	_ = logger.Sync()
+
+	// 每条日志要么输出，要么计入某条汇总
+	total := 0
+	for _, line := range buf.lines() {
+		if i := strings.Index(line, "repeated "); i >= 0 {
+			var n int
+			if _, err := fmt.Sscanf(line[i:], "repeated %d times", &n); err != nil {
+				t.Fatal(err)
+			}
+			total += n
+			continue
+		}
+		total++
+	}
+	if total != goroutines*entries {
+		t.Fatalf("accounted for %d entries, want %d", total, goroutines*entries)
+	}
+}
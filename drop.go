@@ -0,0 +1,63 @@
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"regexp"
+	"sync/atomic"
+)
+
+// dropPattern 是 Config.DropPatterns 中的一条规则及其丢弃的条数
+type dropPattern struct {
+	pattern string
+	re      *regexp.Regexp
+	dropped atomic.Uint64
+}
+
+// compileDropPatterns 编译 Config.DropPatterns
+func compileDropPatterns(patterns []string) ([]*dropPattern, error) {
+	drops := make([]*dropPattern, 0, len(patterns))
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("DropPatterns[%d]: %w", i, err)
+		}
+		drops = append(drops, &dropPattern{pattern: pattern, re: re})
+	}
+
+	return drops, nil
+}
+
+// dropCore 丢弃消息（DropMatchLoggerName 时还包括 logger 名称）匹配任一规则的日志，按第一条匹配的规则计数
+type dropCore struct {
+	zapcore.Core
+	patterns  []*dropPattern
+	matchName bool
+}
+
+func newDropCore(core zapcore.Core, patterns []*dropPattern, matchName bool) zapcore.Core {
+	return &dropCore{Core: core, patterns: patterns, matchName: matchName}
+}
+
+func (c *dropCore) With(fields []zapcore.Field) zapcore.Core {
+	return newDropCore(c.Core.With(fields), c.patterns, c.matchName)
+}
+
+func (c *dropCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *dropCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	for _, p := range c.patterns {
+		if p.re.MatchString(ent.Message) || (c.matchName && p.re.MatchString(ent.LoggerName)) {
+			p.dropped.Add(1)
+			return nil
+		}
+	}
+
+	return c.Core.Write(ent, fields)
+}
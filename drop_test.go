@@ -0,0 +1,74 @@
+package pplogger
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDropPatterns(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		matchName bool
+		kept      []string
+		dropped   map[string]uint64
+	}{
+		{
+			name:    "message only",
+			kept:    []string{"harmless 42 but real", "from lib", "kept"},
+			dropped: map[string]uint64{`^harmless \d+$`: 3, "noisy": 0},
+		},
+		{
+			name:      "logger name",
+			matchName: true,
+			kept:      []string{"harmless 42 but real", "kept"},
+			dropped:   map[string]uint64{`^harmless \d+$`: 3, "noisy": 1},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf syncBuffer
+			logger, err := NewLogger(Config{
+				Encoding:            ConsoleEncoding,
+				DisableCaller:       true,
+				ExtraWriters:        []io.Writer{&buf},
+				DropPatterns:        []string{`^harmless \d+$`, "noisy"},
+				DropMatchLoggerName: tt.matchName,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer logger.Close()
+
+			for i := 0; i < 3; i++ {
+				logger.Warn("harmless 42")
+			}
+			logger.Warn("harmless 42 but real")
+			logger.Named("noisylib").Info("from lib")
+			logger.Named("ok").With().Info("kept")
+			_ = logger.Sync()
+
+			lines := buf.lines()
+			if len(lines) != len(tt.kept) {
+				t.Fatalf("got %q, want %q", lines, tt.kept)
+			}
+			for i, msg := range tt.kept {
+				if !strings.HasSuffix(lines[i], "\t"+msg) {
+					t.Errorf("line %d = %q, want message %q", i, lines[i], msg)
+				}
+			}
+			got := logger.Stats().DroppedByPattern
+			for pattern, want := range tt.dropped {
+				if got[pattern] != want {
+					t.Errorf("DroppedByPattern[%q] = %d, want %d", pattern, got[pattern], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDropPatternsInvalid(t *testing.T) {
+	_, err := NewLogger(Config{DiscardWriter: true, DropPatterns: []string{"("}})
+	if err == nil || !strings.HasPrefix(err.Error(), "pplogger: ") {
+		t.Fatalf("err = %v, want an invalid pattern error", err)
+	}
+}
@@ -0,0 +1,93 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"path/filepath"
+	"strings"
+)
+
+// ecsVersion 为输出遵循的 ECS 版本
+const ecsVersion = "1.6.0"
+
+// ecsCore 将日志转换为 Elastic Common Schema：调用者写入 log.origin，堆栈写入 error.stack_trace，
+// zap.Error 写入 error.message，其余不带 . 的字段放到 labels 下，带 . 的字段按原路径输出
+type ecsCore struct {
+	zapcore.Core
+	caller bool
+}
+
+func newECSCore(core zapcore.Core, caller bool) zapcore.Core {
+	return &ecsCore{
+		Core:   core.With([]zapcore.Field{{Key: "ecs.version", Type: zapcore.StringType, String: ecsVersion}}),
+		caller: caller,
+	}
+}
+
+func (c *ecsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &ecsCore{Core: c.Core.With(ecsFields(fields)), caller: c.caller}
+}
+
+func (c *ecsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *ecsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	fields = ecsFields(fields)
+	if c.caller && ent.Caller.Defined {
+		fields = append(fields, zapcore.Field{Key: "log.origin", Type: zapcore.ObjectMarshalerType, Interface: ecsOrigin(ent.Caller)})
+	}
+	if ent.Stack != "" {
+		fields = append(fields, zapcore.Field{Key: "error.stack_trace", Type: zapcore.StringType, String: ent.Stack})
+	}
+
+	return c.Core.Write(ent, fields)
+}
+
+// ecsFields 返回按 ECS 重命名后的字段，不修改原切片。zap.Namespace 之后的字段已在命名空间内，保持原样
+func ecsFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields), len(fields)+2)
+	namespaced := false
+	for i, f := range fields {
+		switch {
+		case namespaced:
+		case f.Type == zapcore.ErrorType && f.Key == "error":
+			f.Key = "error.message"
+			if err, ok := f.Interface.(error); ok && err != nil {
+				f = zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: err.Error()}
+			}
+		case f.Type == zapcore.NamespaceType:
+			namespaced = true
+			if !strings.Contains(f.Key, ".") {
+				f.Key = "labels." + f.Key
+			}
+		case strings.Contains(f.Key, "."):
+		default:
+			f.Key = "labels." + f.Key
+		}
+		out[i] = f
+	}
+
+	return out
+}
+
+// ecsOrigin 输出 ECS 的 log.origin 对象
+type ecsOrigin zapcore.EntryCaller
+
+func (o ecsOrigin) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if err := enc.AddObject("file", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("name", filepath.Base(o.File))
+		enc.AddInt("line", o.Line)
+		return nil
+	})); err != nil {
+		return err
+	}
+	if o.Function != "" {
+		enc.AddString("function", o.Function)
+	}
+
+	return nil
+}
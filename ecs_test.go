@@ -0,0 +1,70 @@
+package pplogger
+
+import (
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestECSEncodingGolden(t *testing.T) {
+	var buf syncBuffer
+	core := newSinkCore(Config{Encoding: ECSEncoding, TimeZone: "UTC"}, "", false, &buf)
+	core = core.With([]zapcore.Field{zap.String("service.name", "api")})
+	ent := zapcore.Entry{
+		Level:      zapcore.ErrorLevel,
+		Time:       time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC),
+		LoggerName: "db",
+		Message:    "failed",
+		Caller:     zapcore.NewEntryCaller(0, "/src/app/handler.go", 42, true),
+		Stack:      "main.main\n\t/src/main.go:1",
+	}
+	ent.Caller.Function = "app.Handle"
+	fields := []zapcore.Field{
+		zap.String("user_id", "u1"),
+		zap.String("http.request.method", "GET"),
+		zap.Error(errors.New("boom")),
+	}
+	if err := core.Write(ent, fields); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join("testdata", "ecs.golden.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want interface{}
+	if err := json.Unmarshal(b, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %s\nwant %s", buf.String(), b)
+	}
+}
+
+func TestECSEncodingWithoutCaller(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{Encoding: ECSEncoding, DisableCaller: true, ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	_ = logger.Sync()
+
+	m := decodeLine(t, buf.lines()[0])
+	if m["message"] != "hello" || m["log.level"] != "info" || m["ecs.version"] == nil || m["@timestamp"] == nil {
+		t.Errorf("unexpected fields %v", m)
+	}
+	if _, ok := m["log.origin"]; ok {
+		t.Errorf("unexpected log.origin with DisableCaller: %v", m)
+	}
+}
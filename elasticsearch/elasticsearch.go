@@ -0,0 +1,356 @@
+// Package elasticsearch 通过 _bulk API 将日志批量写入 Elasticsearch 或 OpenSearch，通过 pplogger.Config.Sinks 接入：
+//
+//	w, err := elasticsearch.NewWriter(elasticsearch.Config{URL: "http://localhost:9200", Index: "logs-2006.01.02"})
+//	config.Sinks = append(config.Sinks, w)
+//
+// 日志需使用 json 编码（Config.SinkEncoding 的默认值）
+package elasticsearch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultIndex          = "logs-2006.01.02"
+	defaultFlushInterval  = time.Second
+	defaultBatchSize      = 500
+	defaultMaxBufferBytes = 16 << 20
+	defaultTimeout        = 10 * time.Second
+	defaultMaxRetries     = 3
+	minBackoff            = 100 * time.Millisecond
+)
+
+// Config 是 Elasticsearch 输出的配置
+type Config struct {
+	URL string // 例如 http://localhost:9200
+
+	// 索引名，按写入时间（UTC）以 Go 时间格式展开，默认 logs-2006.01.02，
+	// 不含时间格式时即为固定的索引名
+	Index string
+
+	Username string // basic auth 用户名
+	Password string
+	APIKey   string // 已 base64 编码的 API key，设置后忽略 Username 与 Password
+
+	Gzip bool // 是否 gzip 压缩请求体
+
+	FlushInterval  time.Duration // 定时发送的间隔，默认 1s
+	BatchSize      int           // 攒满多少条立即发送，默认 500
+	MaxBufferBytes int           // 缓冲及发送中的日志最多占用的字节数，默认 16M，超过时丢弃新日志
+	Timeout        time.Duration // 单次请求的超时时间，默认 10s
+	MaxRetries     int           // 429 及 5xx 等可重试错误的最多重试次数，默认 3
+
+	HTTPClient *http.Client // 自定义 http.Client，设置后忽略 Timeout
+}
+
+// Stats 是 Writer 的运行统计
+type Stats struct {
+	Indexed uint64 // 成功写入的日志条数
+	Dropped uint64 // 因超出内存上限、不可重试的错误或重试耗尽而丢弃的日志条数
+}
+
+// Writer 实现 pplogger.Sink，写入只追加到内存缓冲区，由后台 goroutine 定时或攒满一批后发送
+type Writer struct {
+	config   Config
+	endpoint string
+	client   *http.Client
+
+	mu      sync.Mutex
+	docs    []document
+	pending int // 缓冲及发送中的字节数
+
+	flushMu sync.Mutex
+	kick    chan struct{}
+	quit    chan struct{}
+	done    chan struct{}
+
+	indexed   atomic.Uint64
+	dropped   atomic.Uint64
+	closeOnce sync.Once
+}
+
+// document 是一条待写入的日志
+type document struct {
+	index string
+	body  []byte
+}
+
+// NewWriter 创建 Writer，使用完毕后应调用 Close，交给 Logger 时由 Logger 关闭
+func NewWriter(config Config) (*Writer, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("pplogger/elasticsearch: invalid URL %q", config.URL)
+	}
+	if config.FlushInterval < 0 || config.BatchSize < 0 || config.MaxBufferBytes < 0 ||
+		config.Timeout < 0 || config.MaxRetries < 0 {
+		return nil, errors.New("pplogger/elasticsearch: negative FlushInterval, BatchSize, MaxBufferBytes, Timeout or MaxRetries")
+	}
+
+	if config.Index == "" {
+		config.Index = defaultIndex
+	}
+	if config.FlushInterval == 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.MaxBufferBytes == 0 {
+		config.MaxBufferBytes = defaultMaxBufferBytes
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+
+	w := &Writer{
+		config:   config,
+		endpoint: strings.TrimRight(config.URL, "/") + "/_bulk",
+		client:   client,
+		kick:     make(chan struct{}, 1),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	body := bytes.TrimRight(p, "\r\n")
+
+	w.mu.Lock()
+	if w.pending+len(body) > w.config.MaxBufferBytes {
+		w.mu.Unlock()
+		w.dropped.Add(1)
+		return len(p), nil
+	}
+	w.docs = append(w.docs, document{
+		index: time.Now().UTC().Format(w.config.Index),
+		body:  append([]byte(nil), body...),
+	})
+	w.pending += len(body)
+	full := len(w.docs) >= w.config.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+// Sync 立即发送缓冲区中的日志，包括可重试错误的重试
+func (w *Writer) Sync() error {
+	w.flush()
+	return nil
+}
+
+// Close 停止定时发送并发送剩余的日志
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.quit)
+		<-w.done
+		w.flush()
+	})
+
+	return nil
+}
+
+// Stats 返回 Writer 的运行统计
+func (w *Writer) Stats() Stats {
+	return Stats{
+		Indexed: w.indexed.Load(),
+		Dropped: w.dropped.Load(),
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.kick:
+		case <-w.quit:
+			return
+		}
+		w.flush()
+	}
+}
+
+// flush 按 BatchSize 分批发送缓冲区中的日志，同一时间只有一个 flush 在发送
+func (w *Writer) flush() {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	docs := w.docs
+	w.docs = nil
+	w.mu.Unlock()
+
+	for len(docs) > 0 {
+		n := min(len(docs), w.config.BatchSize)
+		w.sendRetry(docs[:n])
+		docs = docs[n:]
+	}
+}
+
+// sendRetry 发送一批日志，整个请求或单条日志遇到可重试错误时按指数退避重试
+func (w *Writer) sendRetry(docs []document) {
+	size := 0
+	for _, d := range docs {
+		size += len(d.body)
+	}
+	defer func() {
+		w.mu.Lock()
+		w.pending -= size
+		w.mu.Unlock()
+	}()
+
+	backoff := minBackoff
+	for attempt := 0; ; attempt++ {
+		retry := w.send(docs)
+		if len(retry) == 0 {
+			return
+		}
+		if attempt >= w.config.MaxRetries {
+			w.dropped.Add(uint64(len(retry)))
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		docs = retry
+	}
+}
+
+// send 发送一次 _bulk 请求，返回需要重试的日志
+func (w *Writer) send(docs []document) []document {
+	req, err := w.newRequest(docs)
+	if err != nil {
+		w.dropped.Add(uint64(len(docs)))
+		return nil
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return docs
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		if retryable(resp.StatusCode) {
+			return docs
+		}
+		w.dropped.Add(uint64(len(docs)))
+		return nil
+	}
+
+	var result bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || len(result.Items) != len(docs) {
+		// 无法判断哪些日志写入失败，按全部成功处理，避免重复写入
+		w.indexed.Add(uint64(len(docs)))
+		return nil
+	}
+
+	var retry []document
+	for i, item := range result.Items {
+		for _, op := range item {
+			switch {
+			case op.Status < 300:
+				w.indexed.Add(1)
+			case retryable(op.Status):
+				retry = append(retry, docs[i])
+			default:
+				w.dropped.Add(1)
+			}
+		}
+	}
+
+	return retry
+}
+
+func (w *Writer) newRequest(docs []document) (*http.Request, error) {
+	var body bytes.Buffer
+	var bw io.Writer = &body
+	var zw *gzip.Writer
+	if w.config.Gzip {
+		zw = gzip.NewWriter(&body)
+		bw = zw
+	}
+
+	for _, d := range docs {
+		action, _ := json.Marshal(map[string]interface{}{"index": map[string]string{"_index": d.index}})
+		_, _ = bw.Write(action)
+		_, _ = bw.Write([]byte{'\n'})
+		_, _ = bw.Write(d.body)
+		_, _ = bw.Write([]byte{'\n'})
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if w.config.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if w.config.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+w.config.APIKey)
+	} else if w.config.Username != "" {
+		req.SetBasicAuth(w.config.Username, w.config.Password)
+	}
+
+	return req, nil
+}
+
+// bulkResponse 是 _bulk 的响应，items 与请求中的日志一一对应
+type bulkResponse struct {
+	Errors bool                  `json:"errors"`
+	Items  []map[string]bulkItem `json:"items"`
+}
+
+type bulkItem struct {
+	Status int `json:"status"`
+}
+
+// retryable 判断状态码是否可重试：429 及网关、服务不可用等临时错误
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
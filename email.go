@@ -0,0 +1,363 @@
+package pplogger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	EmailTLSStartTLS = "starttls"
+	EmailTLSImplicit = "tls"
+	EmailTLSNone     = "none"
+)
+
+// EmailConfig 是邮件告警的配置，时间窗口内达到等级的日志汇总为一封邮件发送
+type EmailConfig struct {
+	Address   string // SMTP 服务器 host:port，例如 smtp.example.com:587
+	Username  string
+	Password  string
+	From      string
+	To        []string
+	TLS       string      // starttls（默认）、tls（465 端口的隐式 TLS）或 none
+	TLSConfig *tls.Config // 为空时按服务器主机名校验证书
+
+	Level       string        // 发送邮件的最低等级，取值同 LogLevel，默认 Error
+	BatchWindow time.Duration // 收到第一条日志后等待汇总的时间，默认 60s
+	MaxPerHour  int           // 每小时最多发送的邮件数，默认 10，超出时日志留到下一封邮件
+}
+
+const (
+	defaultEmailBatchWindow = time.Minute
+	defaultEmailMaxPerHour  = 10
+	emailMaxEntries         = 200 // 单封邮件最多包含的日志条数，超出的只计数
+	emailTimeout            = 30 * time.Second
+)
+
+func (c *EmailConfig) validate() []error {
+	var errs []error
+
+	if host, _, err := net.SplitHostPort(c.Address); err != nil || host == "" {
+		errs = append(errs, fmt.Errorf("Email: invalid Address %q, want host:port", c.Address))
+	}
+
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		errs = append(errs, fmt.Errorf("Email: invalid From %q: %w", c.From, err))
+	}
+
+	if len(c.To) == 0 {
+		errs = append(errs, errors.New("Email: missing To"))
+	}
+	for _, to := range c.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			errs = append(errs, fmt.Errorf("Email: invalid To %q: %w", to, err))
+		}
+	}
+
+	switch c.TLS {
+	case "", EmailTLSStartTLS, EmailTLSImplicit, EmailTLSNone:
+	default:
+		errs = append(errs, fmt.Errorf("Email: unknown TLS %q", c.TLS))
+	}
+
+	if c.Level != "" {
+		if _, err := ParseLevel(c.Level); err != nil {
+			errs = append(errs, fmt.Errorf("Email: %w", err))
+		}
+	}
+
+	if c.BatchWindow < 0 || c.MaxPerHour < 0 {
+		errs = append(errs, errors.New("Email: negative BatchWindow or MaxPerHour"))
+	}
+
+	return errs
+}
+
+// newEmailCore 创建邮件告警输出，日志只追加到内存，由后台 goroutine 汇总发送，
+// 无法发送的批次写入 fallback
+func newEmailCore(config Config, fallback zapcore.Core) *emailCore {
+	c := *config.Email
+
+	level := zapcore.ErrorLevel
+	if c.Level != "" {
+		level, _ = ParseLevel(c.Level)
+	}
+	if c.TLS == "" {
+		c.TLS = EmailTLSStartTLS
+	}
+	if c.BatchWindow == 0 {
+		c.BatchWindow = defaultEmailBatchWindow
+	}
+	if c.MaxPerHour == 0 {
+		c.MaxPerHour = defaultEmailMaxPerHour
+	}
+
+	s := &emailSender{
+		config:   c,
+		subject:  emailSubject(config.AppName),
+		fallback: fallback,
+		kick:     make(chan struct{}, 1),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+
+	return &emailCore{
+		LevelEnabler: level,
+		enc:          newEncoder(config, ConsoleEncoding, false),
+		emailSender:  s,
+	}
+}
+
+func emailSubject(appName string) string {
+	if appName == "" {
+		return hostname()
+	}
+
+	return appName + "@" + hostname()
+}
+
+// emailCore 将达到等级的日志编码后交给 emailSender
+type emailCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	*emailSender
+}
+
+// emailSender 由 emailCore 及其 With 派生的 Core 共享
+type emailSender struct {
+	config   EmailConfig
+	subject  string
+	fallback zapcore.Core
+
+	mu      sync.Mutex
+	entries []string
+	omitted int
+	sent    []time.Time // 最近一小时内发送邮件的时间
+
+	kick      chan struct{}
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *emailCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+
+	return &clone
+}
+
+func (c *emailCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *emailCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	c.add(buf.String())
+	buf.Free()
+
+	return nil
+}
+
+func (c *emailCore) Sync() error {
+	return nil
+}
+
+// add 追加一条日志，是批次中的第一条时通知后台开始计时
+func (s *emailSender) add(entry string) {
+	s.mu.Lock()
+	first := len(s.entries) == 0 && s.omitted == 0
+	if len(s.entries) < emailMaxEntries {
+		s.entries = append(s.entries, entry)
+	} else {
+		s.omitted++
+	}
+	s.mu.Unlock()
+
+	if first {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close 立即发送尚未发送的日志
+func (s *emailSender) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+		<-s.done
+	})
+
+	return nil
+}
+
+func (s *emailSender) run() {
+	defer close(s.done)
+
+	window := time.NewTimer(s.config.BatchWindow)
+	window.Stop()
+
+	for {
+		select {
+		case <-s.kick:
+			window.Reset(s.config.BatchWindow)
+			continue
+		case <-window.C:
+		case <-s.quit:
+			window.Stop()
+			s.flush(true)
+			return
+		}
+
+		if !s.flush(false) {
+			// 超出每小时上限，日志留到下一个窗口
+			window.Reset(s.config.BatchWindow)
+		}
+	}
+}
+
+// flush 发送当前批次，超出每小时上限时返回 false，closing 时无视上限
+func (s *emailSender) flush(closing bool) bool {
+	now := time.Now()
+
+	s.mu.Lock()
+	if len(s.entries) == 0 {
+		s.mu.Unlock()
+		return true
+	}
+	recent := s.sent[:0]
+	for _, t := range s.sent {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	s.sent = recent
+	if !closing && len(s.sent) >= s.config.MaxPerHour {
+		s.mu.Unlock()
+		return false
+	}
+	entries, omitted := s.entries, s.omitted
+	s.entries, s.omitted = nil, 0
+	s.sent = append(s.sent, now)
+	s.mu.Unlock()
+
+	if err := s.send(entries, omitted); err != nil {
+		_ = s.fallback.Write(zapcore.Entry{
+			Level:   zapcore.ErrorLevel,
+			Time:    time.Now(),
+			Message: "pplogger: send alert email failed",
+		}, []zapcore.Field{zap.Error(err), zap.Int("entries", len(entries)+omitted), zap.Strings("logs", entries)})
+	}
+
+	return true
+}
+
+func (s *emailSender) send(entries []string, omitted int) error {
+	c := s.config
+	host, _, _ := net.SplitHostPort(c.Address)
+	tlsConfig := c.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: host}
+	}
+
+	dialer := &net.Dialer{Timeout: emailTimeout}
+	var conn net.Conn
+	var err error
+	if c.TLS == EmailTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.Address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", c.Address)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(emailTimeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.TLS == EmailTLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if c.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, host)); err != nil {
+			return err
+		}
+	}
+
+	from, _ := mail.ParseAddress(c.From)
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range c.To {
+		addr, _ := mail.ParseAddress(to)
+		if err := client.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(s.message(entries, omitted)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// message 生成邮件内容，每条日志一行
+func (s *emailSender) message(entries []string, omitted int) []byte {
+	total := len(entries) + omitted
+	subject := fmt.Sprintf("[pplogger] %d log entries from %s", total, s.subject)
+
+	var b bytes.Buffer
+	b.WriteString("From: " + s.config.From + "\r\n")
+	b.WriteString("To: " + strings.Join(s.config.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	for _, entry := range entries {
+		b.WriteString(strings.ReplaceAll(strings.TrimRight(entry, "\r\n"), "\n", "\r\n"))
+		b.WriteString("\r\n")
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "... %d more entries omitted\r\n", omitted)
+	}
+
+	return b.Bytes()
+}
@@ -0,0 +1,370 @@
+package pplogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewEncoderConfigWith 根据 Config 调整 NewEncoderConfig 返回的默认编码配置
+func NewEncoderConfigWith(config Config) zapcore.EncoderConfig {
+	return encoderConfig(config, config.Encoding, false)
+}
+
+func encoderConfig(config Config, encoding string, color bool) zapcore.EncoderConfig {
+	encoderConfig := NewEncoderConfig()
+
+	switch encoding {
+	case JSONEncoding, JSONPrettyEncoding, LogfmtEncoding, Syslog5424Encoding:
+		// json、logfmt 及 syslog5424 编码使用通用的小写字段名，便于日志采集工具解析
+		encoderConfig.TimeKey = "ts"
+		encoderConfig.LevelKey = "level"
+		encoderConfig.NameKey = "logger"
+		encoderConfig.CallerKey = "caller"
+		encoderConfig.MessageKey = "msg"
+		encoderConfig.StacktraceKey = "stacktrace"
+	case ECSEncoding:
+		// 调用者与堆栈由 ecsCore 转换为 ECS 的嵌套字段
+		encoderConfig.TimeKey = "@timestamp"
+		encoderConfig.LevelKey = "log.level"
+		encoderConfig.NameKey = "log.logger"
+		encoderConfig.CallerKey = ""
+		encoderConfig.MessageKey = "message"
+		encoderConfig.StacktraceKey = ""
+	}
+
+	levelFormat := config.LevelFormat
+	if levelFormat == "" {
+		levelFormat = LevelFormatCapital
+		if encoding == LogfmtEncoding || encoding == ECSEncoding {
+			levelFormat = LevelFormatLower
+		}
+	}
+	encoderConfig.EncodeLevel = levelEncoder(levelFormat, color)
+
+	overrideKey(&encoderConfig.TimeKey, config.Keys.TimeKey)
+	overrideKey(&encoderConfig.LevelKey, config.Keys.LevelKey)
+	overrideKey(&encoderConfig.NameKey, config.Keys.NameKey)
+	overrideKey(&encoderConfig.CallerKey, config.Keys.CallerKey)
+	overrideKey(&encoderConfig.MessageKey, config.Keys.MessageKey)
+	overrideKey(&encoderConfig.StacktraceKey, config.Keys.StacktraceKey)
+
+	if config.DisableCaller {
+		encoderConfig.CallerKey = ""
+	}
+
+	if config.NameSeparator != "" && config.NameSeparator != "." {
+		encoderConfig.EncodeName = nameEncoderWithSeparator(config.NameSeparator)
+	}
+
+	switch config.DurationFormat {
+	case DurationFormatSeconds:
+		encoderConfig.EncodeDuration = zapcore.SecondsDurationEncoder
+	case DurationFormatMillis:
+		encoderConfig.EncodeDuration = zapcore.MillisDurationEncoder
+	case DurationFormatNanos:
+		encoderConfig.EncodeDuration = zapcore.NanosDurationEncoder
+	}
+
+	switch config.CallerFormat {
+	case CallerFormatFull:
+		encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+	case CallerFormatFunc:
+		encoderConfig.EncodeCaller = funcCallerEncoder
+	}
+
+	if config.LineEnding == LineEndingCRLF {
+		encoderConfig.LineEnding = "\r\n"
+	}
+
+	switch config.TimeFormat {
+	case TimeFormatEpochMillis:
+		encoderConfig.EncodeTime = zapcore.EpochMillisTimeEncoder
+	case TimeFormatEpochNanos:
+		encoderConfig.EncodeTime = zapcore.EpochNanosTimeEncoder
+	case TimeFormatRFC3339Nano:
+		encoderConfig.EncodeTime = timeEncoderIn(config.TimeZone, zapcore.RFC3339NanoTimeEncoder)
+	default:
+		if encoding == ECSEncoding {
+			// ECS 要求 ISO8601 格式的时间
+			encoderConfig.EncodeTime = timeEncoderIn(config.TimeZone, zapcore.RFC3339NanoTimeEncoder)
+		} else {
+			encoderConfig.EncodeTime = timeEncoderIn(config.TimeZone, TimeEncoder)
+		}
+	}
+
+	if config.EncoderConfigOverride != nil {
+		config.EncoderConfigOverride(&encoderConfig)
+	}
+
+	return encoderConfig
+}
+
+// overrideKey 用 key 覆盖 dst，key 为 OmitKey 时清空以不输出该部分
+func overrideKey(dst *string, key string) {
+	switch key {
+	case "":
+	case OmitKey:
+		*dst = ""
+	default:
+		*dst = key
+	}
+}
+
+// timeEncoderIn 先将时间转换到指定时区再交给 encode，时区为空或无效时直接使用 encode
+func timeEncoderIn(timeZone string, encode zapcore.TimeEncoder) zapcore.TimeEncoder {
+	if timeZone == "" {
+		return encode
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return encode
+	}
+
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		encode(t.In(loc), enc)
+	}
+}
+
+// levelEncoder 返回 LevelFormat 对应的 LevelEncoder，带颜色的格式只在 color 为 true 时输出颜色
+func levelEncoder(format string, color bool) zapcore.LevelEncoder {
+	switch format {
+	case LevelFormatShort3:
+		return short3LevelEncoder
+	case LevelFormatLower, LevelFormatLowerColor:
+		if color {
+			return zapcore.LowercaseColorLevelEncoder
+		}
+		return zapcore.LowercaseLevelEncoder
+	default:
+		if color {
+			return zapcore.CapitalColorLevelEncoder
+		}
+		return zapcore.CapitalLevelEncoder
+	}
+}
+
+// short3LevelEncoder 以定长的三个字母输出等级，便于控制台对齐
+func short3LevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch level {
+	case zapcore.DebugLevel:
+		enc.AppendString("DBG")
+	case zapcore.InfoLevel:
+		enc.AppendString("INF")
+	case zapcore.WarnLevel:
+		enc.AppendString("WRN")
+	case zapcore.ErrorLevel:
+		enc.AppendString("ERR")
+	case zapcore.DPanicLevel:
+		enc.AppendString("DPN")
+	case zapcore.PanicLevel:
+		enc.AppendString("PNC")
+	case zapcore.FatalLevel:
+		enc.AppendString("FTL")
+	default:
+		enc.AppendString(level.CapitalString())
+	}
+}
+
+// nameEncoderWithSeparator 将 zap 以 . 连接的 logger 名称改用 sep 连接
+func nameEncoderWithSeparator(sep string) zapcore.NameEncoder {
+	return func(name string, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(strings.ReplaceAll(name, ".", sep))
+	}
+}
+
+// funcCallerEncoder 以 pkg.Func:42 的形式输出调用者，没有函数名时退回 ShortCallerEncoder
+func funcCallerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	function := caller.Function
+	if function == "" {
+		zapcore.ShortCallerEncoder(caller, enc)
+		return
+	}
+
+	if i := strings.LastIndexByte(function, '/'); i >= 0 {
+		function = function[i+1:]
+	}
+	enc.AppendString(function + ":" + strconv.Itoa(caller.Line))
+}
+
+// newEncoder 创建指定编码格式的 Encoder，encoding 为空时使用 config.Encoding，
+// color 只对 console 编码生效
+func newEncoder(config Config, encoding string, color bool) zapcore.Encoder {
+	if encoding == "" {
+		encoding = config.Encoding
+	}
+
+	switch encoding {
+	case JSONEncoding, ECSEncoding:
+		return zapcore.NewJSONEncoder(encoderConfig(config, encoding, false))
+	case JSONPrettyEncoding:
+		encoderConfig := encoderConfig(config, encoding, false)
+		return newPrettyJSONEncoder(zapcore.NewJSONEncoder(encoderConfig), encoderConfig.LineEnding)
+	case LogfmtEncoding:
+		return newLogfmtEncoder(encoderConfig(config, encoding, false))
+	case GELFEncoding:
+		return newGELFEncoder(encoderConfig(config, encoding, false), hostname())
+	case Syslog5424Encoding:
+		return newSyslog5424Encoder(encoderConfig(config, encoding, false), config, syslogFacilityUser)
+	default:
+		return zapcore.NewConsoleEncoder(encoderConfig(config, encoding, color))
+	}
+}
+
+// newSinkCore 为单个输出创建 Core，等级由外层统一控制
+func newSinkCore(config Config, encoding string, color bool, ws zapcore.WriteSyncer) zapcore.Core {
+	if encoding == "" {
+		encoding = config.Encoding
+	}
+
+	enc := newEncoder(config, encoding, color)
+	if config.EscapeNewlines && config.EscapeStacktrace {
+		// 堆栈由 Encoder 另起一行输出，只能在编码后转义
+		enc = newEscapeEncoder(enc, encoderConfig(config, encoding, color).LineEnding)
+	}
+
+	core := zapcore.NewCore(enc, ws, zapcore.DebugLevel)
+	if encoding == ECSEncoding {
+		core = newECSCore(core, !config.DisableCaller)
+	}
+
+	return core
+}
+
+// useColor 判断控制台输出是否使用带颜色的等级。
+// 优先级：Config.Color 显式设置 > NO_COLOR > FORCE_COLOR/CLICOLOR_FORCE > 终端检测，LevelFormat 不影响判断
+func useColor(config Config) bool {
+	switch config.Color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	if envForced("FORCE_COLOR") || envForced("CLICOLOR_FORCE") {
+		return true
+	}
+
+	return isTerminal(os.Stdout)
+}
+
+// envForced 判断环境变量是否设置为开启，0 和 false 视为关闭
+func envForced(key string) bool {
+	switch strings.ToLower(os.Getenv(key)) {
+	case "", "0", "false":
+		return false
+	default:
+		return true
+	}
+}
+
+// isTerminal 判断 f 是否为终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func validEncoding(encoding string) bool {
+	switch encoding {
+	case "", ConsoleEncoding, JSONEncoding, LogfmtEncoding, GELFEncoding, Syslog5424Encoding, ECSEncoding, JSONPrettyEncoding:
+		return true
+	default:
+		return false
+	}
+}
+
+// escapeEncoder 将 Encoder 输出中除行尾外的换行和回车转义为 \n、\r，保证每条日志只占一行
+type escapeEncoder struct {
+	zapcore.Encoder
+	lineEnding []byte
+}
+
+func newEscapeEncoder(enc zapcore.Encoder, lineEnding string) zapcore.Encoder {
+	return &escapeEncoder{Encoder: enc, lineEnding: []byte(lineEnding)}
+}
+
+func (enc *escapeEncoder) Clone() zapcore.Encoder {
+	return &escapeEncoder{Encoder: enc.Encoder.Clone(), lineEnding: enc.lineEnding}
+}
+
+func (enc *escapeEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := enc.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	body := buf.Bytes()
+	hasLineEnding := len(enc.lineEnding) > 0 && bytes.HasSuffix(body, enc.lineEnding)
+	if hasLineEnding {
+		body = body[:len(body)-len(enc.lineEnding)]
+	}
+	if bytes.IndexAny(body, "\r\n") < 0 {
+		return buf, nil
+	}
+
+	out := bufferPool.Get()
+	for _, b := range body {
+		switch b {
+		case '\n':
+			out.AppendString(`\n`)
+		case '\r':
+			out.AppendString(`\r`)
+		default:
+			out.AppendByte(b)
+		}
+	}
+	if hasLineEnding {
+		_, _ = out.Write(enc.lineEnding)
+	}
+	buf.Free()
+
+	return out, nil
+}
+
+// prettyJSONEncoder 将 json Encoder 的输出缩进两格，条目之间空一行
+type prettyJSONEncoder struct {
+	zapcore.Encoder
+	lineEnding string
+}
+
+func newPrettyJSONEncoder(enc zapcore.Encoder, lineEnding string) zapcore.Encoder {
+	return &prettyJSONEncoder{Encoder: enc, lineEnding: lineEnding}
+}
+
+func (enc *prettyJSONEncoder) Clone() zapcore.Encoder {
+	return &prettyJSONEncoder{Encoder: enc.Encoder.Clone(), lineEnding: enc.lineEnding}
+}
+
+func (enc *prettyJSONEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := enc.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, bytes.TrimRight(buf.Bytes(), "\r\n"), "", "  "); err != nil {
+		return nil, err
+	}
+
+	out := bufferPool.Get()
+	_, _ = out.Write(indented.Bytes())
+	out.AppendString(enc.lineEnding)
+	out.AppendString(enc.lineEnding)
+
+	return out, nil
+}
@@ -0,0 +1,537 @@
+package pplogger
+
+import (
+	"encoding/json"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testObject struct {
+	name  string
+	count int
+}
+
+func (o testObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", o.name)
+	enc.AddInt("count", o.count)
+	return nil
+}
+
+// decodeLine 把 JSON 编码的一行日志解析为 map
+func decodeLine(t *testing.T, line string) map[string]interface{} {
+	t.Helper()
+
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &m); err != nil {
+		t.Fatalf("invalid json %q: %v", line, err)
+	}
+
+	return m
+}
+
+func TestJSONEncoding(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{Encoding: JSONEncoding, ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Named("svc").Info("hello", zap.Int("n", 1), zap.Object("obj", testObject{name: "a", count: 2}))
+	_ = logger.Sync()
+
+	lines := buf.lines()
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1: %q", len(lines), buf.String())
+	}
+	m := decodeLine(t, lines[0])
+	for _, key := range []string{"ts", "caller"} {
+		if _, ok := m[key]; !ok {
+			t.Errorf("missing %q in %v", key, m)
+		}
+	}
+	if m["level"] != "INFO" || m["msg"] != "hello" || m["logger"] != "svc" || m["n"] != float64(1) {
+		t.Errorf("unexpected fields %v", m)
+	}
+	if caller, _ := m["caller"].(string); !strings.Contains(caller, "/encoder_test.go:") {
+		t.Errorf("caller = %q", caller)
+	}
+	obj, ok := m["obj"].(map[string]interface{})
+	if !ok || obj["name"] != "a" || obj["count"] != float64(2) {
+		t.Errorf("obj = %v", m["obj"])
+	}
+}
+
+func TestJSONEncodingKeys(t *testing.T) {
+	var buf syncBuffer
+	config := Config{Encoding: JSONEncoding, ExtraWriters: []io.Writer{&buf}}
+	config.Keys = EncoderKeys{MessageKey: "message", TimeKey: OmitKey}
+	logger, err := NewLogger(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	_ = logger.Sync()
+
+	m := decodeLine(t, buf.lines()[0])
+	if m["message"] != "hello" || m["level"] != "INFO" {
+		t.Errorf("unexpected fields %v", m)
+	}
+	for _, key := range []string{"msg", "ts"} {
+		if _, ok := m[key]; ok {
+			t.Errorf("unexpected %q in %v", key, m)
+		}
+	}
+}
+
+func TestConsoleEncodingDefault(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello", zap.Int("n", 1))
+	_ = logger.Sync()
+
+	line := buf.lines()[0]
+	if strings.HasPrefix(line, "{") || !strings.Contains(line, "\tINFO\t") || !strings.HasSuffix(line, `hello	{"n": 1}`) {
+		t.Errorf("unexpected console line %q", line)
+	}
+}
+
+func TestPerSinkEncoding(t *testing.T) {
+	stdout := captureStdout(t)
+	dir := t.TempDir()
+	logger, err := NewLogger(Config{
+		StdoutWriter:   true,
+		FileWriter:     true,
+		LogPath:        dir,
+		Filename:       "app.log",
+		StdoutEncoding: ConsoleEncoding,
+		FileEncoding:   JSONEncoding,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello", zap.Int("n", 1))
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	console := strings.TrimRight(stdout(), "\n")
+	if strings.HasPrefix(console, "{") || !strings.Contains(console, "\tINFO\t") || !strings.HasSuffix(console, `hello	{"n": 1}`) {
+		t.Errorf("stdout = %q, want a console line", console)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := decodeLine(t, strings.TrimRight(string(b), "\n"))
+	if m["msg"] != "hello" || m["level"] != "INFO" || m["n"] != float64(1) {
+		t.Errorf("file fields = %v", m)
+	}
+}
+
+func TestEncoderKeys(t *testing.T) {
+	keys := EncoderKeys{TimeKey: OmitKey, LevelKey: "severity", MessageKey: "message", CallerKey: "source", NameKey: OmitKey, StacktraceKey: "trace"}
+
+	t.Run("json", func(t *testing.T) {
+		var buf syncBuffer
+		logger, err := NewLogger(Config{Encoding: JSONEncoding, Keys: keys, StacktraceLevel: "error", ExtraWriters: []io.Writer{&buf}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Named("svc").Error("boom")
+		_ = logger.Sync()
+
+		m := decodeLine(t, buf.lines()[0])
+		for _, key := range []string{"severity", "message", "source", "trace"} {
+			if _, ok := m[key]; !ok {
+				t.Errorf("missing %q in %v", key, m)
+			}
+		}
+		for _, key := range []string{"ts", "level", "msg", "caller", "logger", "stacktrace", "svc"} {
+			if _, ok := m[key]; ok {
+				t.Errorf("unexpected %q in %v", key, m)
+			}
+		}
+	})
+
+	t.Run("console", func(t *testing.T) {
+		var buf syncBuffer
+		logger, err := NewLogger(Config{Keys: keys, ExtraWriters: []io.Writer{&buf}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Named("svc").Info("hello")
+		_ = logger.Sync()
+
+		// 省略时间和名称后，控制台行以等级开头且不含名称
+		line := buf.lines()[0]
+		if !strings.HasPrefix(line, "INFO\t") || strings.Contains(line, "svc") || !strings.HasSuffix(line, "\thello") {
+			t.Errorf("unexpected console line %q", line)
+		}
+	})
+}
+
+func TestColor(t *testing.T) {
+	for _, tt := range []struct {
+		color string
+		want  bool
+	}{
+		{ColorAlways, true},
+		{ColorNever, false},
+	} {
+		t.Run(tt.color, func(t *testing.T) {
+			stdout := captureStdout(t)
+			dir := t.TempDir()
+			logger, err := NewLogger(Config{StdoutWriter: true, FileWriter: true, LogPath: dir, Filename: "app.log", Color: tt.color})
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Error("boom")
+			_ = logger.Sync()
+			defer logger.Close()
+
+			if got := strings.Contains(stdout(), "\x1b["); got != tt.want {
+				t.Errorf("stdout has escape codes = %v, want %v: %q", got, tt.want, stdout())
+			}
+			// 文件始终不带颜色
+			b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(b), "\x1b[") || !strings.Contains(string(b), "\tERROR\t") {
+				t.Errorf("file = %q, want plain level", b)
+			}
+		})
+	}
+}
+
+func TestUseColorEnv(t *testing.T) {
+	for _, tt := range []struct {
+		name                          string
+		color                         string
+		levelFormat                   string
+		noColor, forceColor, cliForce string
+		want                          bool
+	}{
+		{name: "auto not a terminal", want: false},
+		{name: "NO_COLOR", noColor: "1", want: false},
+		{name: "FORCE_COLOR", forceColor: "1", want: true},
+		{name: "FORCE_COLOR=0", forceColor: "0", want: false},
+		{name: "FORCE_COLOR=false", forceColor: "false", want: false},
+		{name: "CLICOLOR_FORCE", cliForce: "1", want: true},
+		{name: "NO_COLOR beats FORCE_COLOR", noColor: "1", forceColor: "1", want: false},
+		{name: "always beats NO_COLOR", color: ColorAlways, noColor: "1", want: true},
+		{name: "never beats FORCE_COLOR", color: ColorNever, forceColor: "1", want: false},
+		{name: "never beats CLICOLOR_FORCE", color: ColorNever, cliForce: "1", want: false},
+		{name: "explicit auto", color: ColorAuto, forceColor: "1", want: true},
+		// 带颜色的 LevelFormat 同样遵循环境变量及终端检测
+		{name: "colored format not a terminal", levelFormat: LevelFormatCapitalColor, want: false},
+		{name: "colored format NO_COLOR", levelFormat: LevelFormatLowerColor, noColor: "1", want: false},
+		{name: "colored format FORCE_COLOR", levelFormat: LevelFormatCapitalColor, forceColor: "1", want: true},
+		{name: "colored format never", levelFormat: LevelFormatCapitalColor, color: ColorNever, forceColor: "1", want: false},
+		{name: "colored format always", levelFormat: LevelFormatLowerColor, color: ColorAlways, noColor: "1", want: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// 替换 os.Stdout，保证自动检测时不是终端
+			captureStdout(t)
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("FORCE_COLOR", tt.forceColor)
+			t.Setenv("CLICOLOR_FORCE", tt.cliForce)
+
+			if got := useColor(Config{Color: tt.color, LevelFormat: tt.levelFormat}); got != tt.want {
+				t.Errorf("useColor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	for _, encoding := range []string{ConsoleEncoding, JSONEncoding, LogfmtEncoding, GELFEncoding} {
+		for lineEnding, want := range map[string]string{"": "\n", LineEndingLF: "\n", LineEndingCRLF: "\r\n"} {
+			var buf syncBuffer
+			logger, err := NewLogger(Config{Encoding: encoding, LineEnding: lineEnding, ExtraWriters: []io.Writer{&buf}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info("first")
+			logger.Info("second")
+			_ = logger.Sync()
+
+			s := buf.String()
+			if !strings.HasSuffix(s, want) || strings.Count(s, want) != 2 || want == "\n" && strings.Contains(s, "\r") {
+				t.Errorf("%s with LineEnding %q: %q", encoding, lineEnding, s)
+			}
+		}
+	}
+
+	if err := (Config{StdoutWriter: true, LineEnding: "cr"}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown LineEnding")
+	}
+}
+
+func TestCallerFormat(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	short := filepath.Base(filepath.Dir(file)) + "/encoder_test.go:"
+	for _, tt := range []struct {
+		format string
+		want   string
+	}{
+		{"", short},
+		{CallerFormatShort, short},
+		{CallerFormatFull, file + ":"},
+		{CallerFormatFunc, "pplogger.TestCallerFormat:"},
+	} {
+		for _, encoding := range []string{ConsoleEncoding, JSONEncoding, GELFEncoding} {
+			var buf syncBuffer
+			logger, err := NewLogger(Config{Encoding: encoding, CallerFormat: tt.format, ExtraWriters: []io.Writer{&buf}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info("hello")
+			_ = logger.Sync()
+
+			var caller string
+			switch line := buf.lines()[0]; encoding {
+			case ConsoleEncoding:
+				caller = strings.Split(line, "\t")[2]
+			case JSONEncoding:
+				caller, _ = decodeLine(t, line)["caller"].(string)
+			case GELFEncoding:
+				caller, _ = decodeLine(t, line)["_caller"].(string)
+			}
+			if !strings.HasPrefix(caller, tt.want) {
+				t.Errorf("%s with CallerFormat %q: caller = %q, want prefix %q", encoding, tt.format, caller, tt.want)
+			}
+		}
+	}
+}
+
+func TestShort3LevelEncoder(t *testing.T) {
+	for level, want := range map[zapcore.Level]string{
+		zapcore.DebugLevel:  "DBG",
+		zapcore.InfoLevel:   "INF",
+		zapcore.WarnLevel:   "WRN",
+		zapcore.ErrorLevel:  "ERR",
+		zapcore.DPanicLevel: "DPN",
+		zapcore.PanicLevel:  "PNC",
+		zapcore.FatalLevel:  "FTL",
+	} {
+		var got primitiveStrings
+		short3LevelEncoder(level, &got)
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("short3LevelEncoder(%v) = %q, want %q", level, got, want)
+		}
+	}
+}
+
+func TestLevelFormat(t *testing.T) {
+	for _, tt := range []struct {
+		format   string
+		encoding string
+		color    bool
+		want     string
+	}{
+		{"", ConsoleEncoding, false, "WARN"},
+		{"", JSONEncoding, false, "WARN"},
+		{"", LogfmtEncoding, false, "warn"},
+		{"", ConsoleEncoding, true, "\x1b[33mWARN\x1b[0m"},
+		{LevelFormatCapital, ConsoleEncoding, false, "WARN"},
+		{LevelFormatLower, JSONEncoding, false, "warn"},
+		{LevelFormatLower, ConsoleEncoding, true, "\x1b[33mwarn\x1b[0m"},
+		{LevelFormatCapitalColor, ConsoleEncoding, true, "\x1b[33mWARN\x1b[0m"},
+		{LevelFormatCapitalColor, JSONEncoding, false, "WARN"},
+		{LevelFormatLowerColor, ConsoleEncoding, true, "\x1b[33mwarn\x1b[0m"},
+		{LevelFormatLowerColor, JSONEncoding, false, "warn"},
+		{LevelFormatShort3, ConsoleEncoding, false, "WRN"},
+		{LevelFormatShort3, ConsoleEncoding, true, "WRN"},
+	} {
+		var got primitiveStrings
+		encoderConfig(Config{LevelFormat: tt.format}, tt.encoding, tt.color).EncodeLevel(zapcore.WarnLevel, &got)
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("LevelFormat %q, %s, color %v: got %q, want %q", tt.format, tt.encoding, tt.color, got, tt.want)
+		}
+	}
+}
+
+func TestDurationFormat(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		want   interface{}
+	}{
+		{"", "1.5s"},
+		{DurationFormatString, "1.5s"},
+		{DurationFormatSeconds, 1.5},
+		{DurationFormatMillis, float64(1500)},
+		{DurationFormatNanos, float64(1500000000)},
+	} {
+		for _, encoding := range []string{JSONEncoding, GELFEncoding} {
+			var buf syncBuffer
+			logger, err := NewLogger(Config{Encoding: encoding, DurationFormat: tt.format, ExtraWriters: []io.Writer{&buf}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info("request", zap.Duration("latency", 1500*time.Millisecond))
+			_ = logger.Sync()
+
+			key := "latency"
+			if encoding == GELFEncoding {
+				key = "_latency"
+			}
+			if got := decodeLine(t, buf.lines()[0])[key]; got != tt.want {
+				t.Errorf("%s with DurationFormat %q: got %#v, want %#v", encoding, tt.format, got, tt.want)
+			}
+		}
+	}
+
+	if err := (Config{StdoutWriter: true, DurationFormat: "hours"}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown DurationFormat")
+	}
+}
+
+func TestEncoderConfigOverride(t *testing.T) {
+	var buf syncBuffer
+	// 钩子在 LevelFormat 等 Config 设置之后执行，覆盖它们
+	logger, err := New(WithWriter(&buf), func(c *Config) error { c.LevelFormat = LevelFormatShort3; return nil }, WithEncoderConfig(func(c *zapcore.EncoderConfig) {
+		c.ConsoleSeparator = " | "
+		c.EncodeLevel = zapcore.LowercaseLevelEncoder
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	_ = logger.Sync()
+
+	if line := buf.lines()[0]; !strings.Contains(line, " | info | ") || !strings.HasSuffix(line, " | hello") {
+		t.Errorf("unexpected line %q", line)
+	}
+
+	// 没有钩子时保持默认
+	var plain syncBuffer
+	defaults, err := NewLogger(Config{ExtraWriters: []io.Writer{&plain}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults.Info("hello")
+	_ = defaults.Sync()
+
+	if line := plain.lines()[0]; strings.Contains(line, " | ") || !strings.Contains(line, "\tINFO\t") {
+		t.Errorf("unexpected line %q", line)
+	}
+}
+
+// json-pretty 只用于本地查看，测试只检查缩进和空行分隔，不作为采集格式
+func TestJSONPrettyEncoding(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{Encoding: JSONPrettyEncoding, DisableCaller: true, ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("a", zap.Object("obj", testObject{name: "x", count: 1}))
+	logger.Info("b")
+	_ = logger.Sync()
+
+	s := buf.String()
+	if !strings.Contains(s, "\n  \"msg\": \"a\",\n") || !strings.Contains(s, "\n  \"obj\": {\n    \"name\": \"x\",\n    \"count\": 1\n  }\n}\n\n{") || !strings.HasSuffix(s, "}\n\n") {
+		t.Errorf("unexpected output %q", s)
+	}
+}
+
+func TestJSONPrettyStdoutOnly(t *testing.T) {
+	stdout := captureStdout(t)
+	dir := t.TempDir()
+	logger, err := NewLogger(Config{
+		StdoutWriter:   true,
+		FileWriter:     true,
+		LogPath:        dir,
+		Filename:       "app.log",
+		Encoding:       JSONEncoding,
+		StdoutEncoding: JSONPrettyEncoding,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	_ = logger.Sync()
+	defer logger.Close()
+
+	if s := stdout(); !strings.HasPrefix(s, "{\n  ") || !strings.HasSuffix(s, "}\n\n") {
+		t.Errorf("stdout = %q, want pretty json", s)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n"); len(lines) != 1 {
+		t.Errorf("file = %q, want compact json", b)
+	}
+	decodeLine(t, strings.TrimRight(string(b), "\n"))
+}
+
+func TestLoggerName(t *testing.T) {
+	for _, tt := range []struct {
+		encoding  string
+		separator string
+		nameKey   string
+		key       string
+		want      string
+	}{
+		{JSONEncoding, "", "", "logger", "api.v2"},
+		{JSONEncoding, "/", "", "logger", "api/v2"},
+		{JSONEncoding, "", "subsystem", "subsystem", "api.v2"},
+		{GELFEncoding, "", "", "_logger", "api.v2"},
+	} {
+		var buf syncBuffer
+		logger, err := NewLogger(Config{Encoding: tt.encoding, NameSeparator: tt.separator, Keys: EncoderKeys{NameKey: tt.nameKey}, ExtraWriters: []io.Writer{&buf}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Named("api").Named("v2").Info("hello")
+		_ = logger.Sync()
+
+		if got := decodeLine(t, buf.lines()[0])[tt.key]; got != tt.want {
+			t.Errorf("%s separator %q: %s = %v, want %q", tt.encoding, tt.separator, tt.key, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		config Config
+		want   string
+	}{
+		{Config{}, "\tapi.v2\t"},
+		{Config{Encoding: LogfmtEncoding, NameSeparator: ":"}, " logger=api:v2 "},
+	} {
+		var buf syncBuffer
+		tt.config.ExtraWriters = []io.Writer{&buf}
+		logger, err := NewLogger(tt.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Named("api").Named("v2").Info("hello")
+		_ = logger.Sync()
+
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("%q does not contain %q", buf.String(), tt.want)
+		}
+	}
+
+	var buf syncBuffer
+	logger, err := NewLogger(Config{Keys: EncoderKeys{NameKey: OmitKey}, ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Named("api").Info("hello")
+	_ = logger.Sync()
+
+	if strings.Contains(buf.String(), "api") {
+		t.Errorf("name not omitted: %q", buf.String())
+	}
+}
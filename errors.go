@@ -0,0 +1,156 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"reflect"
+)
+
+// ErrorField 与 zap.Error 类似，但同时输出错误链：error 为顶层错误信息，errorCauses 为逐层 Unwrap 得到的错误信息，
+// 错误链中有带堆栈的错误（pkg/errors 风格，%+v 输出与 Error() 不同）时以 errorStack 输出。err 为 nil 时不输出任何字段
+func ErrorField(err error) zap.Field {
+	return namedErrorField("error", err)
+}
+
+func namedErrorField(key string, err error) zap.Field {
+	if isNilError(err) {
+		return zap.Skip()
+	}
+
+	return zap.Inline(richError{key: key, err: err})
+}
+
+func isNilError(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(err)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// richError 以 key、keyCauses、keyStack 输出错误及其错误链
+type richError struct {
+	key string
+	err error
+}
+
+func (e richError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString(e.key, e.err.Error())
+
+	if causes := errorCauses(e.err); len(causes) > 0 {
+		_ = enc.AddArray(e.key+"Causes", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, cause := range causes {
+				arr.AppendString(cause)
+			}
+			return nil
+		}))
+	}
+
+	if stack := errorStack(e.err); stack != "" {
+		enc.AddString(e.key+"Stack", stack)
+	}
+
+	return nil
+}
+
+// errorStack 返回错误链中第一个带堆栈的错误的 %+v 输出，没有时返回空串
+func errorStack(err error) string {
+	queue := []error{err}
+	for len(queue) > 0 {
+		cause := queue[0]
+		queue = queue[1:]
+		if isNilError(cause) {
+			continue
+		}
+		if formatter, ok := cause.(fmt.Formatter); ok {
+			if verbose := fmt.Sprintf("%+v", formatter); verbose != cause.Error() {
+				return verbose
+			}
+		}
+		queue = append(queue, unwrapAll(cause)...)
+	}
+
+	return ""
+}
+
+// errorCauses 按广度优先返回 err 之下各层错误的信息，支持 errors.Join 等 Unwrap() []error
+func errorCauses(err error) []string {
+	var causes []string
+	queue := unwrapAll(err)
+	for len(queue) > 0 {
+		cause := queue[0]
+		queue = queue[1:]
+		if isNilError(cause) {
+			continue
+		}
+		causes = append(causes, cause.Error())
+		queue = append(queue, unwrapAll(cause)...)
+	}
+
+	return causes
+}
+
+func unwrapAll(err error) []error {
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		return multi.Unwrap()
+	}
+
+	if cause := errors.Unwrap(err); cause != nil {
+		return []error{cause}
+	}
+
+	return nil
+}
+
+// verboseErrorCore 将 zap.Error、zap.NamedError 等错误字段替换为 namedErrorField 的输出
+type verboseErrorCore struct {
+	zapcore.Core
+}
+
+func newVerboseErrorCore(core zapcore.Core) zapcore.Core {
+	return &verboseErrorCore{Core: core}
+}
+
+func (c *verboseErrorCore) With(fields []zapcore.Field) zapcore.Core {
+	return newVerboseErrorCore(c.Core.With(verboseErrorFields(fields)))
+}
+
+func (c *verboseErrorCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *verboseErrorCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, verboseErrorFields(fields))
+}
+
+// verboseErrorFields 返回替换后的字段，没有错误字段时直接返回 fields
+func verboseErrorFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		if f.Type != zapcore.ErrorType {
+			if out != nil {
+				out[i] = f
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields[:i])
+		}
+		err, _ := f.Interface.(error)
+		out[i] = namedErrorField(f.Key, err)
+	}
+
+	if out == nil {
+		return fields
+	}
+
+	return out
+}
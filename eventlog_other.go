@@ -0,0 +1,13 @@
+//go:build !windows
+
+package pplogger
+
+import (
+	"errors"
+	"go.uber.org/zap/zapcore"
+	"io"
+)
+
+func newEventLogCore(config Config, stats *sinkStats) (zapcore.Core, io.Closer, error) {
+	return nil, nil, errors.New("pplogger: EventSource is only supported on windows")
+}
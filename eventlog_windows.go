@@ -0,0 +1,84 @@
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"io"
+	"strings"
+)
+
+// eventLogID 为写入事件的 ID，EventCreate.exe 作为消息文件时支持 1 至 1000
+const eventLogID = 1
+
+// eventLogCore 将日志写入 Windows 事件日志的 Application 日志，写入失败时计数并丢弃
+type eventLogCore struct {
+	zapcore.LevelEnabler
+	enc   zapcore.Encoder
+	log   *eventlog.Log
+	stats *sinkStats
+}
+
+func newEventLogCore(config Config, stats *sinkStats) (zapcore.Core, io.Closer, error) {
+	// 注册事件源需要管理员权限，已注册或无权限时忽略错误，未注册的事件源同样可以写入
+	_ = eventlog.InstallAsEventCreate(config.EventSource, eventlog.Error|eventlog.Warning|eventlog.Info)
+
+	log, err := eventlog.Open(config.EventSource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pplogger: open event log: %w", err)
+	}
+
+	core := &eventLogCore{
+		LevelEnabler: zapcore.DebugLevel,
+		enc:          newEncoder(syslogMessageConfig(config), "", false),
+		log:          log,
+		stats:        stats,
+	}
+
+	return core, log, nil
+}
+
+func (c *eventLogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+
+	return &clone
+}
+
+func (c *eventLogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *eventLogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	message := strings.TrimRight(buf.String(), "\r\n")
+	buf.Free()
+
+	switch {
+	case ent.Level >= zapcore.ErrorLevel:
+		err = c.log.Error(eventLogID, message)
+	case ent.Level == zapcore.WarnLevel:
+		err = c.log.Warning(eventLogID, message)
+	default:
+		err = c.log.Info(eventLogID, message)
+	}
+	if err != nil {
+		c.stats.writeErrors.Add(1)
+	}
+
+	return nil
+}
+
+func (c *eventLogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,117 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+)
+
+// Factory 按名称创建写到各自文件的日志，例如 NewFactory(config).Logger("db") 写到 LogPath/db.log，
+// 设置了 CombinedFilename 时所有日志同时写入该文件。同一文件只打开一次，Close 时统一关闭
+type Factory struct {
+	base    Config
+	mu      sync.Mutex
+	loggers map[string]*Logger
+	closed  bool
+	janitor *retentionJanitor // 见 SetRetention
+}
+
+// FactoryRetention 是 Factory 创建的全部日志文件共同的备份保留策略，与各文件自身的 MaxBackups、MaxAge 等同时生效
+type FactoryRetention struct {
+	MaxBackups   int    // 全部文件的备份总数上限，0 表示不限制
+	MaxTotalSize string // 全部文件及其备份占用空间的上限，例如 "10GB"，格式同 Config.MaxTotalSize，为空时不限制
+	DryRun       bool   // 只通过日志输出将要删除的备份，不实际删除，便于确认策略
+}
+
+// NewFactory 以 base 为模板创建 Factory，base 中的 Filename 不生效，FileWriter 固定为 true
+func NewFactory(base Config) *Factory {
+	return &Factory{
+		base:    base,
+		loggers: make(map[string]*Logger),
+	}
+}
+
+// Logger 返回 name 对应的日志，写到 LogPath/<name>.log，同一个 name 只会创建一次
+func (f *Factory) Logger(name string) (*zap.Logger, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, fmt.Errorf("pplogger: invalid logger name %q", name)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil, errors.New("pplogger: factory is closed")
+	}
+
+	if logger, ok := f.loggers[name]; ok {
+		return logger.Logger, nil
+	}
+
+	config := f.base
+	config.FileWriter = true
+	config.Filename = name + ".log"
+	config.FilenamePattern = sharedFilenamePattern(config.FilenamePattern)
+
+	logger, err := newLogger([]Option{WithConfig(config)})
+	if err != nil {
+		return nil, err
+	}
+	logger.Logger = logger.Logger.Named(name)
+	f.loggers[name] = logger
+	if f.janitor != nil {
+		f.janitor.manage(logger)
+	}
+
+	return logger.Logger, nil
+}
+
+// SetRetention 设置全部日志文件共同的备份保留策略。任一文件切割后在后台统计所有文件的备份，
+// 超过限制时按修改时间从旧到新删除，不属于这些文件的备份（例如同目录下其他程序的文件）及当前文件不会删除。
+// 设置后立即检查一次，已创建及之后创建的日志均生效，只能设置一次
+func (f *Factory) SetRetention(retention FactoryRetention) error {
+	maxTotalSize, err := parseByteSize(retention.MaxTotalSize)
+	if err != nil {
+		return fmt.Errorf("pplogger: MaxTotalSize: %w", err)
+	}
+	if retention.MaxBackups < 0 {
+		return fmt.Errorf("pplogger: negative MaxBackups %d", retention.MaxBackups)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return errors.New("pplogger: factory is closed")
+	}
+	if f.janitor != nil {
+		return errors.New("pplogger: retention is already set")
+	}
+
+	f.janitor = newRetentionJanitor(retention.MaxBackups, maxTotalSize, retention.DryRun)
+	for _, logger := range f.loggers {
+		f.janitor.manage(logger)
+	}
+
+	return nil
+}
+
+// Close 关闭 Factory 创建的全部日志，可重复调用，关闭后 Logger 返回错误
+func (f *Factory) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	var errs []error
+	for _, logger := range f.loggers {
+		errs = append(errs, logger.Close())
+	}
+
+	return errors.Join(errs...)
+}
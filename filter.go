@@ -0,0 +1,60 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// FilterFunc 决定是否输出一条日志，fields 包括 With 附加的字段及本次调用的字段，返回 false 时丢弃，见 Config.Filter
+type FilterFunc func(entry zapcore.Entry, fields []zapcore.Field) bool
+
+// filterCore 在编码之前按 Config.Filter 过滤日志，记录 With 附加的字段以便一并交给 filter
+type filterCore struct {
+	zapcore.Core
+	filter  FilterFunc
+	context []zapcore.Field
+}
+
+func newFilterCore(core zapcore.Core, filter FilterFunc) zapcore.Core {
+	return &filterCore{Core: core, filter: filter}
+}
+
+func (c *filterCore) With(fields []zapcore.Field) zapcore.Core {
+	context := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+
+	return &filterCore{Core: c.Core.With(fields), filter: c.filter, context: context}
+}
+
+func (c *filterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *filterCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := fields
+	if len(c.context) > 0 {
+		all = make([]zapcore.Field, 0, len(c.context)+len(fields))
+		all = append(all, c.context...)
+		all = append(all, fields...)
+	}
+	if !c.keep(ent, all) {
+		return nil
+	}
+
+	return c.Core.Write(ent, fields)
+}
+
+// keep 调用 filter，filter panic 时保留该日志
+func (c *filterCore) keep(ent zapcore.Entry, fields []zapcore.Field) (keep bool) {
+	defer func() {
+		if recover() != nil {
+			keep = true
+		}
+	}()
+
+	return c.filter(ent, fields)
+}
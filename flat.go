@@ -0,0 +1,272 @@
+package pplogger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"go.uber.org/zap/zapcore"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flatField 是展开后的单个字段，嵌套对象的 key 以 . 连接
+type flatField struct {
+	Key    string
+	Value  string
+	Number bool // Value 是否为数字，json 类格式可不加引号直接输出
+}
+
+// flatEncoder 实现 zapcore.ObjectEncoder，将字段按写入顺序展开为 key/value 字符串，
+// 供 logfmt 等扁平格式使用。数组以 json 形式表示
+type flatEncoder struct {
+	cfg    *zapcore.EncoderConfig
+	fields []flatField
+	prefix string
+}
+
+func newFlatEncoder(cfg *zapcore.EncoderConfig) *flatEncoder {
+	return &flatEncoder{cfg: cfg}
+}
+
+func (enc *flatEncoder) clone() *flatEncoder {
+	return &flatEncoder{
+		cfg:    enc.cfg,
+		fields: append([]flatField(nil), enc.fields...),
+		prefix: enc.prefix,
+	}
+}
+
+func (enc *flatEncoder) add(key, value string) {
+	enc.fields = append(enc.fields, flatField{Key: enc.prefix + key, Value: value})
+}
+
+func (enc *flatEncoder) addNumber(key, value string) {
+	enc.fields = append(enc.fields, flatField{Key: enc.prefix + key, Value: value, Number: true})
+}
+
+// addEncoded 通过 zap 的 EncodeTime 等回调函数得到字符串形式的值
+func (enc *flatEncoder) addEncoded(key string, encode func(zapcore.PrimitiveArrayEncoder)) {
+	var values primitiveStrings
+	encode(&values)
+	enc.add(key, strings.Join(values, " "))
+}
+
+// encodeName 用 cfg.EncodeName 编码 logger 名称，未设置时原样返回
+func encodeName(cfg *zapcore.EncoderConfig, name string) string {
+	if name == "" || cfg.EncodeName == nil {
+		return name
+	}
+
+	var values primitiveStrings
+	cfg.EncodeName(name, &values)
+	return strings.Join(values, " ")
+}
+
+// addHeader 按 zap json 编码器的顺序写入时间、等级、名称、调用者及消息
+func (enc *flatEncoder) addHeader(ent zapcore.Entry) {
+	cfg := enc.cfg
+	if cfg.TimeKey != "" && !ent.Time.IsZero() {
+		enc.AddTime(cfg.TimeKey, ent.Time)
+	}
+	if cfg.LevelKey != "" && cfg.EncodeLevel != nil {
+		enc.addEncoded(cfg.LevelKey, func(pe zapcore.PrimitiveArrayEncoder) {
+			cfg.EncodeLevel(ent.Level, pe)
+		})
+	}
+	if ent.LoggerName != "" && cfg.NameKey != "" {
+		nameEncoder := cfg.EncodeName
+		if nameEncoder == nil {
+			nameEncoder = zapcore.FullNameEncoder
+		}
+		enc.addEncoded(cfg.NameKey, func(pe zapcore.PrimitiveArrayEncoder) {
+			nameEncoder(ent.LoggerName, pe)
+		})
+	}
+	if ent.Caller.Defined {
+		if cfg.CallerKey != "" && cfg.EncodeCaller != nil {
+			enc.addEncoded(cfg.CallerKey, func(pe zapcore.PrimitiveArrayEncoder) {
+				cfg.EncodeCaller(ent.Caller, pe)
+			})
+		}
+		if cfg.FunctionKey != "" {
+			enc.add(cfg.FunctionKey, ent.Caller.Function)
+		}
+	}
+	if cfg.MessageKey != "" {
+		enc.add(cfg.MessageKey, ent.Message)
+	}
+}
+
+func (enc *flatEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+	b, err := json.Marshal(m.Fields[key])
+	if err != nil {
+		return err
+	}
+	enc.add(key, string(b))
+	return nil
+}
+
+func (enc *flatEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	prefix := enc.prefix
+	enc.prefix = prefix + key + "."
+	err := obj.MarshalLogObject(enc)
+	enc.prefix = prefix
+	return err
+}
+
+func (enc *flatEncoder) AddBinary(key string, value []byte) {
+	enc.add(key, base64.StdEncoding.EncodeToString(value))
+}
+
+func (enc *flatEncoder) AddByteString(key string, value []byte) {
+	enc.add(key, string(value))
+}
+
+func (enc *flatEncoder) AddBool(key string, value bool) {
+	enc.add(key, strconv.FormatBool(value))
+}
+
+func (enc *flatEncoder) AddComplex128(key string, value complex128) {
+	enc.add(key, strconv.FormatComplex(value, 'g', -1, 128))
+}
+
+func (enc *flatEncoder) AddComplex64(key string, value complex64) {
+	enc.add(key, strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+
+func (enc *flatEncoder) AddDuration(key string, value time.Duration) {
+	if enc.cfg.EncodeDuration == nil {
+		enc.add(key, value.String())
+		return
+	}
+	enc.addEncoded(key, func(pe zapcore.PrimitiveArrayEncoder) {
+		enc.cfg.EncodeDuration(value, pe)
+	})
+
+	// seconds、millis 等格式输出的是数字
+	last := &enc.fields[len(enc.fields)-1]
+	if _, err := strconv.ParseFloat(last.Value, 64); err == nil {
+		last.Number = true
+	}
+}
+
+func (enc *flatEncoder) AddFloat64(key string, value float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		enc.add(key, strconv.FormatFloat(value, 'g', -1, 64))
+		return
+	}
+	enc.addNumber(key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func (enc *flatEncoder) AddFloat32(key string, value float32) {
+	if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+		enc.add(key, strconv.FormatFloat(float64(value), 'g', -1, 32))
+		return
+	}
+	enc.addNumber(key, strconv.FormatFloat(float64(value), 'g', -1, 32))
+}
+
+func (enc *flatEncoder) AddInt(key string, value int) { enc.AddInt64(key, int64(value)) }
+
+func (enc *flatEncoder) AddInt64(key string, value int64) {
+	enc.addNumber(key, strconv.FormatInt(value, 10))
+}
+
+func (enc *flatEncoder) AddInt32(key string, value int32) { enc.AddInt64(key, int64(value)) }
+
+func (enc *flatEncoder) AddInt16(key string, value int16) { enc.AddInt64(key, int64(value)) }
+
+func (enc *flatEncoder) AddInt8(key string, value int8) { enc.AddInt64(key, int64(value)) }
+
+func (enc *flatEncoder) AddString(key, value string) {
+	enc.add(key, value)
+}
+
+func (enc *flatEncoder) AddTime(key string, value time.Time) {
+	if enc.cfg.EncodeTime == nil {
+		enc.add(key, value.Format(time.RFC3339Nano))
+		return
+	}
+	enc.addEncoded(key, func(pe zapcore.PrimitiveArrayEncoder) {
+		enc.cfg.EncodeTime(value, pe)
+	})
+}
+
+func (enc *flatEncoder) AddUint(key string, value uint) { enc.AddUint64(key, uint64(value)) }
+
+func (enc *flatEncoder) AddUint64(key string, value uint64) {
+	enc.addNumber(key, strconv.FormatUint(value, 10))
+}
+
+func (enc *flatEncoder) AddUint32(key string, value uint32) { enc.AddUint64(key, uint64(value)) }
+
+func (enc *flatEncoder) AddUint16(key string, value uint16) { enc.AddUint64(key, uint64(value)) }
+
+func (enc *flatEncoder) AddUint8(key string, value uint8) { enc.AddUint64(key, uint64(value)) }
+
+func (enc *flatEncoder) AddUintptr(key string, value uintptr) { enc.AddUint64(key, uint64(value)) }
+
+func (enc *flatEncoder) AddReflected(key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	enc.add(key, string(b))
+	return nil
+}
+
+func (enc *flatEncoder) OpenNamespace(key string) {
+	enc.prefix += key + "."
+}
+
+// primitiveStrings 实现 zapcore.PrimitiveArrayEncoder，收集 EncodeTime 等回调写入的值
+type primitiveStrings []string
+
+func (s *primitiveStrings) AppendBool(v bool) { *s = append(*s, strconv.FormatBool(v)) }
+
+func (s *primitiveStrings) AppendByteString(v []byte) { *s = append(*s, string(v)) }
+
+func (s *primitiveStrings) AppendComplex128(v complex128) {
+	*s = append(*s, strconv.FormatComplex(v, 'g', -1, 128))
+}
+
+func (s *primitiveStrings) AppendComplex64(v complex64) {
+	*s = append(*s, strconv.FormatComplex(complex128(v), 'g', -1, 64))
+}
+
+func (s *primitiveStrings) AppendFloat64(v float64) {
+	*s = append(*s, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func (s *primitiveStrings) AppendFloat32(v float32) {
+	*s = append(*s, strconv.FormatFloat(float64(v), 'g', -1, 32))
+}
+
+func (s *primitiveStrings) AppendInt(v int) { s.AppendInt64(int64(v)) }
+
+func (s *primitiveStrings) AppendInt64(v int64) { *s = append(*s, strconv.FormatInt(v, 10)) }
+
+func (s *primitiveStrings) AppendInt32(v int32) { s.AppendInt64(int64(v)) }
+
+func (s *primitiveStrings) AppendInt16(v int16) { s.AppendInt64(int64(v)) }
+
+func (s *primitiveStrings) AppendInt8(v int8) { s.AppendInt64(int64(v)) }
+
+func (s *primitiveStrings) AppendString(v string) { *s = append(*s, v) }
+
+func (s *primitiveStrings) AppendUint(v uint) { s.AppendUint64(uint64(v)) }
+
+func (s *primitiveStrings) AppendUint64(v uint64) { *s = append(*s, strconv.FormatUint(v, 10)) }
+
+func (s *primitiveStrings) AppendUint32(v uint32) { s.AppendUint64(uint64(v)) }
+
+func (s *primitiveStrings) AppendUint16(v uint16) { s.AppendUint64(uint64(v)) }
+
+func (s *primitiveStrings) AppendUint8(v uint8) { s.AppendUint64(uint64(v)) }
+
+func (s *primitiveStrings) AppendUintptr(v uintptr) { s.AppendUint64(uint64(v)) }
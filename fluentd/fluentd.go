@@ -0,0 +1,474 @@
+// Package fluentd 通过 Fluentd forward 协议（msgpack over TCP）发送日志，通过 pplogger.Config.Sinks 接入：
+//
+//	w, err := fluentd.NewWriter(fluentd.Config{Address: "127.0.0.1:24224", Tag: "app", RequireAck: true})
+//	config.Sinks = append(config.Sinks, w)
+//
+// 日志需使用 json 编码（Config.SinkEncoding 的默认值），每条日志的 tag 为 Tag 加上 logger 名称
+package fluentd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/vmihailenco/msgpack/v5"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultAddress      = "127.0.0.1:24224"
+	defaultNameKey      = "logger"
+	defaultBatchSize    = 100
+	defaultLinger       = 100 * time.Millisecond
+	defaultBufferSize   = 10000
+	defaultTimeout      = 5 * time.Second
+	defaultAckTimeout   = 10 * time.Second
+	defaultFlushTimeout = 5 * time.Second
+	minBackoff          = 100 * time.Millisecond
+	maxBackoff          = 30 * time.Second
+)
+
+// Config 是 Fluentd 输出的配置
+type Config struct {
+	Address   string      // host:port，默认 127.0.0.1:24224
+	TLSConfig *tls.Config // 不为空时使用 TLS 连接
+
+	// tag 前缀，日志有 logger 名称时 tag 为 Tag.名称，例如 app.db，为空时只使用 logger 名称
+	Tag     string
+	NameKey string // json 日志中 logger 名称的字段名，默认 logger
+
+	// 是否等待 Fluentd 确认（chunk/ack），确认超时或失败时重发，保证至少送达一次
+	RequireAck bool
+
+	BatchSize    int           // 每个 chunk 最多的日志条数，默认 100
+	Linger       time.Duration // 未攒满一个 chunk 时最多等待的时间，默认 100ms
+	BufferSize   int           // 内存中最多缓冲的日志条数，默认 10000，缓冲区满时丢弃
+	Timeout      time.Duration // 连接及写入的超时时间，默认 5s
+	AckTimeout   time.Duration // 等待确认的超时时间，默认 10s
+	FlushTimeout time.Duration // Close 时发送剩余日志最多等待的时间，默认 5s
+}
+
+// Stats 是 Writer 的运行统计
+type Stats struct {
+	Sent       uint64 // 成功发送（RequireAck 时为已确认）的日志条数
+	Dropped    uint64 // 因缓冲区已满或关闭时未能发出而丢弃的日志条数
+	Reconnects uint64 // 重新连接的次数
+}
+
+// Writer 实现 pplogger.Sink，写入从不阻塞，由后台 goroutine 攒批并按指数退避重连重发
+type Writer struct {
+	config Config
+
+	queue  chan event
+	flush  chan chan struct{}
+	quit   chan struct{}
+	done   chan struct{}
+	conn   net.Conn
+	dialed bool
+
+	sent       atomic.Uint64
+	dropped    atomic.Uint64
+	reconnects atomic.Uint64
+	closeOnce  sync.Once
+}
+
+// event 是一条待发送的日志
+type event struct {
+	time   time.Time
+	record []byte
+}
+
+// NewWriter 创建 Writer，连接在第一次发送时建立，使用完毕后应调用 Close，交给 Logger 时由 Logger 关闭
+func NewWriter(config Config) (*Writer, error) {
+	if config.BatchSize < 0 || config.Linger < 0 || config.BufferSize < 0 ||
+		config.Timeout < 0 || config.AckTimeout < 0 || config.FlushTimeout < 0 {
+		return nil, errors.New("pplogger/fluentd: negative BatchSize, BufferSize or duration")
+	}
+
+	if config.Address == "" {
+		config.Address = defaultAddress
+	}
+	if _, _, err := net.SplitHostPort(config.Address); err != nil {
+		return nil, fmt.Errorf("pplogger/fluentd: invalid Address: %w", err)
+	}
+	if config.NameKey == "" {
+		config.NameKey = defaultNameKey
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.Linger == 0 {
+		config.Linger = defaultLinger
+	}
+	if config.BufferSize == 0 {
+		config.BufferSize = defaultBufferSize
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.AckTimeout == 0 {
+		config.AckTimeout = defaultAckTimeout
+	}
+	if config.FlushTimeout == 0 {
+		config.FlushTimeout = defaultFlushTimeout
+	}
+
+	w := &Writer{
+		config: config,
+		queue:  make(chan event, config.BufferSize),
+		flush:  make(chan chan struct{}),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	e := event{time: time.Now(), record: append([]byte(nil), p...)}
+	select {
+	case w.queue <- e:
+	default:
+		w.dropped.Add(1)
+	}
+
+	return len(p), nil
+}
+
+// Sync 等待此前写入的日志发送完成，Fluentd 不可用时会一直重试直到 Close
+func (w *Writer) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case w.flush <- ack:
+		<-ack
+	case <-w.done:
+	}
+
+	return nil
+}
+
+// Close 最多等待 FlushTimeout 发送剩余的日志后关闭连接
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.quit)
+		<-w.done
+	})
+
+	return nil
+}
+
+// Stats 返回 Writer 的运行统计
+func (w *Writer) Stats() Stats {
+	return Stats{
+		Sent:       w.sent.Load(),
+		Dropped:    w.dropped.Load(),
+		Reconnects: w.reconnects.Load(),
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	defer func() {
+		if w.conn != nil {
+			_ = w.conn.Close()
+		}
+	}()
+
+	var batch []event
+	linger := time.NewTimer(w.config.Linger)
+	linger.Stop()
+
+	for {
+		select {
+		case e := <-w.queue:
+			if len(batch) == 0 {
+				linger.Reset(w.config.Linger)
+			}
+			batch = append(batch, e)
+			if len(batch) < w.config.BatchSize {
+				continue
+			}
+		case <-linger.C:
+			if len(batch) == 0 {
+				continue
+			}
+		case ack := <-w.flush:
+			batch = w.drain(batch, time.Time{})
+			close(ack)
+			continue
+		case <-w.quit:
+			w.drain(batch, time.Now().Add(w.config.FlushTimeout))
+			return
+		}
+
+		linger.Stop()
+		if !w.sendRetry(batch, time.Time{}) {
+			w.drain(batch, time.Now().Add(w.config.FlushTimeout))
+			return
+		}
+		batch = nil
+	}
+}
+
+// drain 发送 batch 及缓冲区中已有的日志，deadline 不为零时超时后丢弃剩余日志，
+// 返回值为 Close 打断发送时尚未发出的日志
+func (w *Writer) drain(batch []event, deadline time.Time) []event {
+	for {
+		for len(batch) < w.config.BatchSize {
+			select {
+			case e := <-w.queue:
+				batch = append(batch, e)
+				continue
+			default:
+			}
+			break
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if !w.sendRetry(batch, deadline) {
+			if deadline.IsZero() {
+				return batch
+			}
+			w.dropped.Add(uint64(len(batch)))
+			for {
+				select {
+				case <-w.queue:
+					w.dropped.Add(1)
+				default:
+					return nil
+				}
+			}
+		}
+		batch = nil
+	}
+}
+
+// sendRetry 按指数退避重发 batch 直到成功，deadline 到达或 Close 时放弃并返回 false
+func (w *Writer) sendRetry(batch []event, deadline time.Time) bool {
+	backoff := minBackoff
+	for {
+		err := w.send(batch)
+		if err == nil {
+			w.sent.Add(uint64(len(batch)))
+			return true
+		}
+
+		wait := backoff
+		if !deadline.IsZero() {
+			remain := time.Until(deadline)
+			if remain <= 0 {
+				return false
+			}
+			wait = min(wait, remain)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-w.quitIfRunning(deadline):
+			timer.Stop()
+			return false
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// quitIfRunning 返回正常运行时用于打断重试的 quit，关闭过程中返回 nil 以等待到 deadline
+func (w *Writer) quitIfRunning(deadline time.Time) <-chan struct{} {
+	if !deadline.IsZero() {
+		return nil
+	}
+
+	return w.quit
+}
+
+// send 按 PackedForward 模式发送一个 chunk，同一批中 tag 不同的日志分别发送
+func (w *Writer) send(batch []event) error {
+	if w.conn == nil {
+		conn, err := w.dial()
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+
+	var tags []string
+	streams := make(map[string]*stream)
+	for _, e := range batch {
+		tag, record := w.decode(e.record)
+		s, ok := streams[tag]
+		if !ok {
+			s = &stream{}
+			streams[tag] = s
+			tags = append(tags, tag)
+		}
+		if err := encodeEntry(&s.entries, e.time, record); err != nil {
+			return err
+		}
+		s.size++
+	}
+
+	for _, tag := range tags {
+		if err := w.sendStream(tag, streams[tag]); err != nil {
+			_ = w.conn.Close()
+			w.conn = nil
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Writer) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: w.config.Timeout}
+	var conn net.Conn
+	var err error
+	if w.config.TLSConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", w.config.Address, w.config.TLSConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", w.config.Address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if w.dialed {
+		w.reconnects.Add(1)
+	}
+	w.dialed = true
+
+	return conn, nil
+}
+
+// stream 是同一 tag 的 MessagePackEventStream
+type stream struct {
+	entries bytes.Buffer
+	size    int
+}
+
+// sendStream 发送 [tag, entries, option]，RequireAck 时等待 {"ack": chunk}
+func (w *Writer) sendStream(tag string, s *stream) error {
+	option := map[string]interface{}{"size": s.size}
+	var chunk string
+	if w.config.RequireAck {
+		chunk = newChunkID()
+		option["chunk"] = chunk
+	}
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	if err := enc.EncodeArrayLen(3); err != nil {
+		return err
+	}
+	if err := enc.EncodeString(tag); err != nil {
+		return err
+	}
+	if err := enc.EncodeBytes(s.entries.Bytes()); err != nil {
+		return err
+	}
+	if err := enc.Encode(option); err != nil {
+		return err
+	}
+
+	_ = w.conn.SetWriteDeadline(time.Now().Add(w.config.Timeout))
+	if _, err := w.conn.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if !w.config.RequireAck {
+		return nil
+	}
+
+	_ = w.conn.SetReadDeadline(time.Now().Add(w.config.AckTimeout))
+	resp, err := msgpack.NewDecoder(w.conn).DecodeMap()
+	if err != nil {
+		return err
+	}
+	if ack, _ := resp["ack"].(string); ack != chunk {
+		return fmt.Errorf("pplogger/fluentd: unexpected ack %q, want %q", ack, chunk)
+	}
+
+	return nil
+}
+
+// decode 解析 json 日志，返回 tag 及记录，无法解析时整行作为 message 字段
+func (w *Writer) decode(p []byte) (string, map[string]interface{}) {
+	p = bytes.TrimRight(p, "\r\n")
+
+	var record map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if err := dec.Decode(&record); err != nil {
+		record = map[string]interface{}{"message": string(p)}
+	}
+	for k, v := range record {
+		record[k] = normalize(v)
+	}
+
+	tag := w.config.Tag
+	if name, _ := record[w.config.NameKey].(string); name != "" {
+		if tag == "" {
+			tag = name
+		} else {
+			tag += "." + name
+		}
+	}
+
+	return tag, record
+}
+
+// normalize 将 json.Number 转为整数或浮点数，使 msgpack 按数字编码
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+	}
+
+	return v
+}
+
+// encodeEntry 将 [EventTime, record] 追加到 MessagePackEventStream
+func encodeEntry(buf *bytes.Buffer, t time.Time, record map[string]interface{}) error {
+	enc := msgpack.NewEncoder(buf)
+	if err := enc.EncodeArrayLen(2); err != nil {
+		return err
+	}
+
+	// EventTime 为 ext type 0，内容是大端的秒与纳秒
+	if err := enc.EncodeExtHeader(0, 8); err != nil {
+		return err
+	}
+	var eventTime [8]byte
+	binary.BigEndian.PutUint32(eventTime[:4], uint32(t.Unix()))
+	binary.BigEndian.PutUint32(eventTime[4:], uint32(t.Nanosecond()))
+	buf.Write(eventTime[:])
+
+	return enc.Encode(record)
+}
+
+func newChunkID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return base64.StdEncoding.EncodeToString(id[:])
+}
@@ -0,0 +1,147 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// gelfEncoder 输出 GELF 1.1 格式的 json，附加字段以 _ 开头，嵌套对象以 . 展开
+type gelfEncoder struct {
+	*flatEncoder
+	cfg  *zapcore.EncoderConfig
+	host string
+}
+
+func newGELFEncoder(cfg zapcore.EncoderConfig, host string) zapcore.Encoder {
+	return &gelfEncoder{flatEncoder: newFlatEncoder(&cfg), cfg: &cfg, host: host}
+}
+
+func (enc *gelfEncoder) Clone() zapcore.Encoder {
+	return &gelfEncoder{flatEncoder: enc.flatEncoder.clone(), cfg: enc.cfg, host: enc.host}
+}
+
+func (enc *gelfEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := enc.flatEncoder.clone()
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+
+	buf := bufferPool.Get()
+	buf.AppendString(`{"version":"1.1","host":`)
+	appendJSONString(buf, enc.host)
+	buf.AppendString(`,"short_message":`)
+	appendJSONString(buf, ent.Message)
+	if ent.Stack != "" {
+		buf.AppendString(`,"full_message":`)
+		appendJSONString(buf, ent.Message+"\n"+ent.Stack)
+	}
+	buf.AppendString(`,"timestamp":`)
+	millis := ent.Time.UnixMilli()
+	buf.AppendInt(millis / 1000)
+	buf.AppendByte('.')
+	frac := strconv.FormatInt(millis%1000+1000, 10)
+	buf.AppendString(frac[1:])
+	buf.AppendString(`,"level":`)
+	buf.AppendInt(int64(syslogSeverity(ent.Level)))
+
+	if ent.LoggerName != "" && enc.cfg.NameKey != "" {
+		buf.AppendString(`,"_logger":`)
+		appendJSONString(buf, encodeName(enc.cfg, ent.LoggerName))
+	}
+	if ent.Caller.Defined && enc.cfg.CallerKey != "" && enc.cfg.EncodeCaller != nil {
+		var caller primitiveStrings
+		enc.cfg.EncodeCaller(ent.Caller, &caller)
+		buf.AppendString(`,"_caller":`)
+		appendJSONString(buf, strings.Join(caller, " "))
+	}
+	for _, f := range final.fields {
+		buf.AppendString(`,"_`)
+		appendGELFKey(buf, f.Key)
+		buf.AppendString(`":`)
+		if f.Number {
+			buf.AppendString(f.Value)
+		} else {
+			appendJSONString(buf, f.Value)
+		}
+	}
+
+	buf.AppendByte('}')
+	lineEnding := enc.cfg.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	buf.AppendString(lineEnding)
+
+	return buf, nil
+}
+
+// syslogSeverity 将 zap 等级映射为 syslog 的数字等级，GELF 与 RFC5424 共用
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel:
+		return 2
+	case zapcore.PanicLevel:
+		return 1
+	case zapcore.FatalLevel:
+		return 0
+	default:
+		return 6
+	}
+}
+
+// appendGELFKey 写入附加字段名，GELF 只允许字母、数字、_、. 和 -，id 为保留字段
+func appendGELFKey(buf *buffer.Buffer, key string) {
+	if key == "id" {
+		key = "id_"
+	}
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-' {
+			buf.AppendByte(c)
+		} else {
+			buf.AppendByte('_')
+		}
+	}
+}
+
+// appendJSONString 以 json 字符串的形式写入 s
+func appendJSONString(buf *buffer.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.AppendByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf.AppendString("\ufffd")
+		case r == '"' || r == '\\':
+			buf.AppendByte('\\')
+			buf.AppendByte(byte(r))
+		case r == '\n':
+			buf.AppendString(`\n`)
+		case r == '\r':
+			buf.AppendString(`\r`)
+		case r == '\t':
+			buf.AppendString(`\t`)
+		case r < 0x20:
+			buf.AppendString(`\u00`)
+			buf.AppendByte(hex[r>>4])
+			buf.AppendByte(hex[r&0xf])
+		default:
+			buf.AppendString(string(r))
+		}
+	}
+	buf.AppendByte('"')
+}
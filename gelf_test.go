@@ -0,0 +1,91 @@
+package pplogger
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestSyslogSeverity(t *testing.T) {
+	for level, want := range map[zapcore.Level]int{
+		zapcore.DebugLevel:  7,
+		zapcore.InfoLevel:   6,
+		zapcore.WarnLevel:   4,
+		zapcore.ErrorLevel:  3,
+		zapcore.DPanicLevel: 2,
+		zapcore.PanicLevel:  1,
+		zapcore.FatalLevel:  0,
+	} {
+		if got := syslogSeverity(level); got != want {
+			t.Errorf("syslogSeverity(%v) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestGELFEncoder(t *testing.T) {
+	enc := newGELFEncoder(encoderConfig(Config{}, GELFEncoding, false), "web-1")
+	ent := zapcore.Entry{
+		Level:      zapcore.DPanicLevel,
+		Time:       time.Unix(1700000000, 123456789),
+		LoggerName: "api",
+		Message:    `boom "x"`,
+		Stack:      "main.main\n\tmain.go:1",
+	}
+	buf, err := enc.EncodeEntry(ent, []zapcore.Field{
+		zap.Int("n", 3),
+		zap.String("id", "z"),
+		zap.String("bad key", "v"),
+		zap.Object("obj", testObject{name: "a", count: 2}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := decodeLine(t, buf.String())
+	want := map[string]interface{}{
+		"version":       "1.1",
+		"host":          "web-1",
+		"short_message": `boom "x"`,
+		"full_message":  "boom \"x\"\nmain.main\n\tmain.go:1",
+		"timestamp":     1700000000.123,
+		"level":         float64(2),
+		"_logger":       "api",
+		"_n":            float64(3),
+		"_id_":          "z",
+		"_bad_key":      "v",
+		"_obj.name":     "a",
+		"_obj.count":    float64(2),
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, m[k], v)
+		}
+	}
+	if len(m) != len(want) {
+		t.Errorf("got %d fields, want %d: %v", len(m), len(want), m)
+	}
+}
+
+func TestGELFEncoding(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{Encoding: GELFEncoding, ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Warn("disk", zap.Error(errors.New("full")))
+	_ = logger.Sync()
+
+	m := decodeLine(t, buf.lines()[0])
+	if m["version"] != "1.1" || m["short_message"] != "disk" || m["level"] != float64(4) || m["_error"] != "full" {
+		t.Errorf("unexpected fields %v", m)
+	}
+	if _, ok := m["_caller"]; !ok {
+		t.Errorf("missing _caller in %v", m)
+	}
+	if _, ok := m["full_message"]; ok {
+		t.Errorf("unexpected full_message without stacktrace: %v", m)
+	}
+}
@@ -0,0 +1,182 @@
+package pplogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net"
+	"sync/atomic"
+)
+
+const (
+	GELFCompressionNone = "none"
+	GELFCompressionGzip = "gzip"
+	GELFCompressionZlib = "zlib"
+)
+
+// GELFConfig 是通过 UDP 发送到 Graylog 的配置，日志使用 gelf 编码
+type GELFConfig struct {
+	Address     string // host:port，端口默认 12201
+	Compression string // 压缩方式，none（默认）、gzip 或 zlib
+	ChunkSize   int    // 单个 UDP 数据报的最大字节数，默认 1420，超过时按 GELF 分块发送
+}
+
+const (
+	defaultGELFChunkSize = 1420
+	gelfChunkHeaderSize  = 12
+	gelfMaxChunks        = 128
+)
+
+func (c *GELFConfig) validate() []error {
+	var errs []error
+
+	if _, err := c.address(); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch c.Compression {
+	case "", GELFCompressionNone, GELFCompressionGzip, GELFCompressionZlib:
+	default:
+		errs = append(errs, fmt.Errorf("GELF: unknown Compression %q", c.Compression))
+	}
+
+	if c.ChunkSize != 0 && c.ChunkSize <= gelfChunkHeaderSize {
+		errs = append(errs, fmt.Errorf("GELF: ChunkSize %d must be greater than %d", c.ChunkSize, gelfChunkHeaderSize))
+	}
+
+	return errs
+}
+
+// address 补全默认端口
+func (c *GELFConfig) address() (string, error) {
+	if c.Address == "" {
+		return "", errors.New("GELF: missing Address")
+	}
+
+	host, port, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		host, port = c.Address, "12201"
+	}
+	if host == "" {
+		return "", fmt.Errorf("GELF: missing host in %q", c.Address)
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// newGELFCore 创建 GELF UDP 输出，发送失败只计入 Stats.WriteErrors，
+// 压缩后超过 128 块的日志会被丢弃并计入 Stats.Dropped
+func newGELFCore(config Config, stats *sinkStats) (zapcore.Core, io.Closer, error) {
+	c := config.GELF
+	addr, _ := c.address()
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pplogger: dial GELF: %w", err)
+	}
+
+	chunkSize := c.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultGELFChunkSize
+	}
+
+	w := &gelfUDPWriter{
+		conn:        conn,
+		compression: c.Compression,
+		chunkSize:   chunkSize,
+		stats:       stats,
+	}
+	var base [8]byte
+	_, _ = rand.Read(base[:])
+	w.id.Store(binary.BigEndian.Uint64(base[:]))
+
+	return newSinkCore(config, GELFEncoding, false, w), w, nil
+}
+
+// gelfUDPWriter 将每条日志作为一个 GELF 消息发送，写入从不返回错误
+type gelfUDPWriter struct {
+	conn        net.Conn
+	compression string
+	chunkSize   int
+	stats       *sinkStats
+	id          atomic.Uint64
+}
+
+func (w *gelfUDPWriter) Write(p []byte) (int, error) {
+	msg, err := w.compress(bytes.TrimRight(p, "\r\n"))
+	if err != nil {
+		w.stats.writeErrors.Add(1)
+		return len(p), nil
+	}
+
+	if err := w.send(msg); err != nil {
+		w.stats.writeErrors.Add(1)
+	}
+
+	return len(p), nil
+}
+
+func (w *gelfUDPWriter) Sync() error {
+	return nil
+}
+
+func (w *gelfUDPWriter) Close() error {
+	return w.conn.Close()
+}
+
+func (w *gelfUDPWriter) compress(p []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	switch w.compression {
+	case GELFCompressionGzip:
+		zw = gzip.NewWriter(&buf)
+	case GELFCompressionZlib:
+		zw = zlib.NewWriter(&buf)
+	default:
+		return p, nil
+	}
+
+	if _, err := zw.Write(p); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// send 发送一条消息，超过 chunkSize 时分块：magic 0x1e 0x0f、8 字节消息 ID、序号、总块数及数据
+func (w *gelfUDPWriter) send(msg []byte) error {
+	if len(msg) <= w.chunkSize {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+
+	dataSize := w.chunkSize - gelfChunkHeaderSize
+	count := (len(msg) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		w.stats.dropped.Add(1)
+		return nil
+	}
+
+	id := w.id.Add(1)
+	chunk := make([]byte, 0, w.chunkSize)
+	for i := 0; i < count; i++ {
+		data := msg[i*dataSize : min((i+1)*dataSize, len(msg))]
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = binary.BigEndian.AppendUint64(chunk, id)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
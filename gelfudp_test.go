@@ -0,0 +1,148 @@
+package pplogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"go.uber.org/zap"
+	"io"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+)
+
+// readGELF 从 pc 读取一条 GELF 消息，分块时按序号重组，返回重组后的数据及数据报个数
+func readGELF(t *testing.T, pc net.PacketConn, chunkSize int) ([]byte, int) {
+	t.Helper()
+
+	buf := make([]byte, 65536)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	chunks := map[byte][]byte{}
+	var id uint64
+	for {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > chunkSize {
+			t.Fatalf("datagram of %d bytes exceeds ChunkSize %d", n, chunkSize)
+		}
+		b := buf[:n]
+		if len(chunks) == 0 && !bytes.HasPrefix(b, []byte{0x1e, 0x0f}) {
+			return append([]byte(nil), b...), 1
+		}
+		if !bytes.HasPrefix(b, []byte{0x1e, 0x0f}) || n < gelfChunkHeaderSize {
+			t.Fatalf("bad chunk header %x", b[:min(n, gelfChunkHeaderSize)])
+		}
+		if chunkID := binary.BigEndian.Uint64(b[2:10]); len(chunks) == 0 {
+			id = chunkID
+		} else if chunkID != id {
+			t.Fatalf("chunk id %d, want %d", chunkID, id)
+		}
+		seq, count := b[10], b[11]
+		chunks[seq] = append([]byte(nil), b[gelfChunkHeaderSize:]...)
+		if len(chunks) == int(count) {
+			var msg []byte
+			for i := byte(0); i < count; i++ {
+				data, ok := chunks[i]
+				if !ok {
+					t.Fatalf("missing chunk %d of %d", i, count)
+				}
+				msg = append(msg, data...)
+			}
+			return msg, int(count)
+		}
+	}
+}
+
+func TestGELFUDPChunking(t *testing.T) {
+	// 随机内容难以压缩，保证压缩后仍需要分块
+	big := make([]byte, 5000)
+	for i := range big {
+		big[i] = byte('a' + rand.Intn(26))
+	}
+
+	for _, tt := range []struct {
+		compression string
+		decompress  func(io.Reader) (io.Reader, error)
+	}{
+		{GELFCompressionNone, func(r io.Reader) (io.Reader, error) { return r, nil }},
+		{GELFCompressionGzip, func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{GELFCompressionZlib, func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }},
+	} {
+		t.Run(tt.compression, func(t *testing.T) {
+			pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer pc.Close()
+
+			const chunkSize = 400
+			logger, err := NewLogger(Config{GELF: &GELFConfig{Address: pc.LocalAddr().String(), Compression: tt.compression, ChunkSize: chunkSize}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer logger.Close()
+
+			for _, data := range []string{"small", string(big)} {
+				logger.Info("hello", zap.String("data", data))
+
+				msg, datagrams := readGELF(t, pc, chunkSize)
+				if data == "small" && datagrams != 1 || data != "small" && datagrams < 2 {
+					t.Errorf("%d-byte field sent in %d datagrams", len(data), datagrams)
+				}
+				r, err := tt.decompress(bytes.NewReader(msg))
+				if err != nil {
+					t.Fatal(err)
+				}
+				raw, err := io.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if m := decodeLine(t, string(raw)); m["_data"] != data || m["short_message"] != "hello" {
+					t.Errorf("reassembled message does not match")
+				}
+				if bytes.HasSuffix(raw, []byte("\n")) {
+					t.Error("GELF payload ends with a newline")
+				}
+			}
+			if stats := logger.Stats(); stats.WriteErrors != 0 || stats.Dropped != 0 {
+				t.Errorf("Stats = %+v", stats)
+			}
+		})
+	}
+}
+
+func TestGELFUDPTooManyChunks(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	logger, err := NewLogger(Config{GELF: &GELFConfig{Address: pc.LocalAddr().String(), ChunkSize: gelfChunkHeaderSize + 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	// 每块只有 1 字节数据，超过 128 块的消息被丢弃
+	logger.Info("too big for 128 chunks")
+	if stats := logger.Stats(); stats.Dropped != 1 {
+		t.Errorf("Stats = %+v, want 1 dropped", stats)
+	}
+}
+
+func TestGELFConfigInvalid(t *testing.T) {
+	for _, gelf := range []*GELFConfig{
+		{},
+		{Address: "graylog", Compression: "lz4"},
+		{Address: "graylog", ChunkSize: gelfChunkHeaderSize},
+	} {
+		if err := (Config{GELF: gelf}).Validate(); err == nil {
+			t.Errorf("Validate accepted %+v", gelf)
+		}
+	}
+}
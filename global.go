@@ -0,0 +1,130 @@
+package pplogger
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrAlreadyInitialized 表示全局日志已经通过 Init 初始化过
+var ErrAlreadyInitialized = errors.New("pplogger: global logger already initialized")
+
+// ErrGlobalsReplaced 表示已有其他 Logger 通过 ReplaceGlobals 安装为 zap 的全局日志
+var ErrGlobalsReplaced = errors.New("pplogger: zap globals already replaced by another logger")
+
+type globalLogger struct {
+	logger *zap.Logger
+	sugar  *zap.SugaredLogger
+}
+
+var (
+	globalMu     sync.Mutex
+	globalInited bool
+	globalOwner  *Logger // Init 创建的 Logger，Close 时关闭
+	global       atomic.Pointer[globalLogger]
+	nopGlobal    = newGlobalLogger(zap.NewNop())
+)
+
+func newGlobalLogger(logger *zap.Logger) *globalLogger {
+	return &globalLogger{logger: logger, sugar: logger.Sugar()}
+}
+
+// Init 根据 Config 创建全局日志，Close 之前只能成功调用一次，重复调用返回 ErrAlreadyInitialized
+func Init(config Config) error {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	if globalInited {
+		return ErrAlreadyInitialized
+	}
+
+	logger, err := newLogger([]Option{WithConfig(config)})
+	if err != nil {
+		return err
+	}
+
+	global.Store(newGlobalLogger(logger.Logger))
+	globalInited, globalOwner = true, logger
+
+	return nil
+}
+
+// Close 关闭 Init 创建的全局日志：刷新缓冲、关闭日志文件并停止其后台任务，之后 L、S 返回不输出任何内容的 Logger，
+// 可以再次调用 Init。未初始化时返回 nil
+func Close() error {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	if !globalInited {
+		return nil
+	}
+	owner := globalOwner
+	globalInited, globalOwner = false, nil
+	global.Store(nil)
+
+	return owner.Close()
+}
+
+// L 返回全局 *zap.Logger，Init 之前返回不输出任何内容的 Logger
+func L() *zap.Logger {
+	return loadGlobal().logger
+}
+
+// S 返回全局 *zap.SugaredLogger，Init 之前返回不输出任何内容的 SugaredLogger
+func S() *zap.SugaredLogger {
+	return loadGlobal().sugar
+}
+
+// ReplaceLogger 替换全局日志，主要用于测试，传入 nil 时恢复为不输出的 Logger
+func ReplaceLogger(logger *zap.Logger) {
+	if logger == nil {
+		global.Store(nil)
+		return
+	}
+
+	global.Store(newGlobalLogger(logger))
+}
+
+func loadGlobal() *globalLogger {
+	if g := global.Load(); g != nil {
+		return g
+	}
+
+	return nopGlobal
+}
+
+var zapGlobals struct {
+	sync.Mutex
+	owner *Logger
+}
+
+// replaceZapGlobals 将 logger 安装为 zap.L()/zap.S() 及标准库 log 的输出，返回恢复函数。
+// permanent 时不记录 owner，返回 nil，之后的安装可以直接覆盖；恢复时还原为安装前的全局日志
+func replaceZapGlobals(logger *Logger, permanent bool) (func(), error) {
+	zapGlobals.Lock()
+	defer zapGlobals.Unlock()
+
+	if zapGlobals.owner != nil {
+		return nil, ErrGlobalsReplaced
+	}
+
+	undoGlobals := zap.ReplaceGlobals(logger.Logger)
+	undoStdLog := zap.RedirectStdLog(logger.Logger)
+	if permanent {
+		return nil, nil
+	}
+	zapGlobals.owner = logger
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			zapGlobals.Lock()
+			defer zapGlobals.Unlock()
+
+			undoStdLog()
+			undoGlobals()
+			zapGlobals.owner = nil
+		})
+	}, nil
+}
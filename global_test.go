@@ -0,0 +1,149 @@
+package pplogger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGlobalClose(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{FileWriter: true, LogPath: dir, Filename: "app.log"}
+	if err := Init(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	if err := Init(config); err != ErrAlreadyInitialized {
+		t.Fatalf("second Init = %v, want ErrAlreadyInitialized", err)
+	}
+
+	L().Info("global")
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "app.log")); !strings.Contains(string(b), "global") {
+		t.Fatalf("log file = %q", b)
+	}
+	if L().Core().Enabled(zapcore.FatalLevel) {
+		t.Fatal("L is not a no-op logger after Close")
+	}
+
+	if err := Init(config); err != nil {
+		t.Fatalf("Init after Close: %v", err)
+	}
+}
+
+func TestReplaceGlobals(t *testing.T) {
+	before := zap.L()
+	buf := &syncBuffer{}
+	logger, err := NewLogger(Config{ExtraWriters: []io.Writer{buf}, ReplaceGlobals: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	if zap.L() != logger.Logger {
+		t.Fatal("zap.L was not replaced")
+	}
+	zap.S().Info("from zap.S")
+	log.Print("from std log")
+	if out := buf.String(); !strings.Contains(out, "from zap.S") || !strings.Contains(out, "from std log") {
+		t.Fatalf("output = %q", out)
+	}
+
+	// 同一时间只能有一个 Logger 安装
+	if _, err := NewLogger(Config{DiscardWriter: true, ReplaceGlobals: true}); err != ErrGlobalsReplaced {
+		t.Fatalf("second logger: err = %v, want ErrGlobalsReplaced", err)
+	}
+
+	logger.RestoreGlobals()
+	logger.RestoreGlobals()
+	if zap.L() != before {
+		t.Fatal("zap.L was not restored")
+	}
+
+	second, err := NewLogger(Config{DiscardWriter: true, ReplaceGlobals: true})
+	if err != nil {
+		t.Fatalf("after RestoreGlobals: %v", err)
+	}
+	// Close 同样恢复
+	_ = second.Close()
+	if zap.L() != before {
+		t.Fatal("zap.L was not restored by Close")
+	}
+}
+
+func TestReplaceGlobalsPermanent(t *testing.T) {
+	// 只返回 *zap.Logger 的构造函数安装后无法恢复，测试结束时手动还原
+	before := zap.L()
+	flags, prefix, writer := log.Flags(), log.Prefix(), log.Writer()
+	t.Cleanup(func() {
+		zap.ReplaceGlobals(before)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(writer)
+	})
+
+	var lite, pp, opts syncBuffer
+	liteLogger, _, err := NewPPLoggerLiteE(filepath.Join(t.TempDir(), "lite.log"), "info", WithWriter(&lite), WithoutStdout(), WithReplaceGlobals())
+	if err != nil {
+		t.Fatalf("NewPPLoggerLiteE: %v", err)
+	}
+	if zap.L() != liteLogger {
+		t.Fatal("NewPPLoggerLiteE did not replace zap.L")
+	}
+
+	// 后安装的覆盖先安装的
+	ppLogger, _, err := NewPPLoggerE(Config{ExtraWriters: []io.Writer{&pp}, ReplaceGlobals: true})
+	if err != nil {
+		t.Fatalf("NewPPLoggerE: %v", err)
+	}
+	log.Print("from std log")
+	if zap.L() != ppLogger || !strings.Contains(pp.String(), "from std log") || lite.String() != "" {
+		t.Fatalf("NewPPLoggerE did not replace the globals: pp %q, lite %q", pp.String(), lite.String())
+	}
+
+	newLogger, err := New(WithWriter(&opts), WithReplaceGlobals())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if zap.L() != newLogger {
+		t.Fatal("New did not replace zap.L")
+	}
+
+	// 可以恢复的 Logger 覆盖后，关闭时还原为之前安装的全局日志
+	logger, err := NewLogger(Config{DiscardWriter: true, ReplaceGlobals: true})
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	if _, _, err := NewPPLoggerE(Config{DiscardWriter: true, ReplaceGlobals: true}); err != ErrGlobalsReplaced {
+		t.Fatalf("NewPPLoggerE while owned: err = %v, want ErrGlobalsReplaced", err)
+	}
+	_ = logger.Close()
+	if zap.L() != newLogger {
+		t.Fatal("Close did not restore the globals installed by New")
+	}
+}
+
+func TestReplaceLogger(t *testing.T) {
+	buf := &syncBuffer{}
+	ReplaceLogger(zap.New(newSinkCore(Config{}, "", false, buf)))
+	t.Cleanup(func() { ReplaceLogger(nil) })
+
+	L().Info("from L")
+	S().Infow("from S", "n", 1)
+	if out := buf.String(); !strings.Contains(out, "from L") || !strings.Contains(out, "from S") {
+		t.Fatalf("output = %q", out)
+	}
+
+	ReplaceLogger(nil)
+	if L().Core().Enabled(zapcore.FatalLevel) || S().Desugar().Core().Enabled(zapcore.FatalLevel) {
+		t.Fatal("L and S are not no-op loggers after ReplaceLogger(nil)")
+	}
+}
@@ -0,0 +1,81 @@
+package pplogger
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer 是并发安全的 bytes.Buffer，用于在测试中收集日志输出
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) Sync() error {
+	return nil
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.b.String()
+}
+
+// lines 返回去掉末尾换行后按行拆分的输出
+func (b *syncBuffer) lines() []string {
+	s := strings.TrimRight(b.String(), "\n")
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
+
+// captureStdout 把 os.Stdout 替换为临时文件，返回读取已写入内容的函数，测试结束后恢复
+func captureStdout(t *testing.T) func() string {
+	t.Helper()
+
+	return captureFile(t, &os.Stdout)
+}
+
+// captureStderr 同 captureStdout，替换 os.Stderr
+func captureStderr(t *testing.T) func() string {
+	t.Helper()
+
+	return captureFile(t, &os.Stderr)
+}
+
+func captureFile(t *testing.T, std **os.File) func() string {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "std")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := *std
+	*std = f
+	t.Cleanup(func() {
+		*std = old
+		_ = f.Close()
+	})
+
+	return func() string {
+		b, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(b)
+	}
+}
@@ -0,0 +1,83 @@
+package pplogger
+
+import (
+	"encoding/binary"
+	"go.uber.org/zap/zapcore"
+	"strconv"
+	"strings"
+)
+
+// journalEntry 按 journald 原生协议编码日志：PRIORITY、MESSAGE、CODE_FILE、CODE_LINE 等固定字段，
+// zap 字段转换为大写的字段名，嵌套对象以 _ 连接
+func journalEntry(cfg *zapcore.EncoderConfig, identifier string, context []flatField, ent zapcore.Entry, fields []zapcore.Field) []byte {
+	final := newFlatEncoder(cfg)
+	final.fields = append(final.fields, context...)
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+
+	var b []byte
+	b = appendJournalField(b, "MESSAGE", ent.Message)
+	b = appendJournalField(b, "PRIORITY", strconv.Itoa(syslogSeverity(ent.Level)))
+	b = appendJournalField(b, "SYSLOG_IDENTIFIER", identifier)
+	if ent.LoggerName != "" {
+		b = appendJournalField(b, "LOGGER", ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		b = appendJournalField(b, "CODE_FILE", ent.Caller.File)
+		b = appendJournalField(b, "CODE_LINE", strconv.Itoa(ent.Caller.Line))
+		if ent.Caller.Function != "" {
+			b = appendJournalField(b, "CODE_FUNC", ent.Caller.Function)
+		}
+	}
+	if ent.Stack != "" {
+		b = appendJournalField(b, "STACKTRACE", ent.Stack)
+	}
+	for _, f := range final.fields {
+		b = appendJournalField(b, journalFieldName(f.Key), f.Value)
+	}
+
+	return b
+}
+
+// appendJournalField 写入一个字段，值中含换行时使用 8 字节小端长度前缀的二进制格式
+func appendJournalField(b []byte, key, value string) []byte {
+	if !strings.Contains(value, "\n") {
+		b = append(b, key...)
+		b = append(b, '=')
+		b = append(b, value...)
+		return append(b, '\n')
+	}
+
+	b = append(b, key...)
+	b = append(b, '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// journalFieldName 将 key 转换为合法的字段名：大写字母、数字和 _，不能以 _ 或数字开头，最长 64 个字符
+func journalFieldName(key string) string {
+	name := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			name = append(name, c-'a'+'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			name = append(name, c)
+		default:
+			name = append(name, '_')
+		}
+	}
+
+	trimmed := strings.TrimLeft(string(name), "_")
+	if trimmed == "" || trimmed[0] >= '0' && trimmed[0] <= '9' {
+		trimmed = "F_" + trimmed
+	}
+	if len(trimmed) > 64 {
+		trimmed = trimmed[:64]
+	}
+
+	return trimmed
+}
@@ -0,0 +1,111 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net"
+	"os"
+	"syscall"
+)
+
+// journalSocket 为 journald 原生协议的套接字
+var journalSocket = "/run/systemd/journal/socket"
+
+// journaldCore 通过原生协议写入 systemd journal，写入失败时计数并丢弃，不影响其他输出
+type journaldCore struct {
+	zapcore.LevelEnabler
+	cfg        *zapcore.EncoderConfig
+	identifier string
+	context    *flatEncoder
+	conn       *net.UnixConn
+	addr       *net.UnixAddr
+	stats      *sinkStats
+}
+
+func newJournaldCore(config Config, stats *sinkStats) (zapcore.Core, io.Closer, error) {
+	if _, err := os.Stat(journalSocket); err != nil {
+		return nil, nil, fmt.Errorf("pplogger: connect journald: %w", err)
+	}
+
+	// 使用未连接的套接字，传递文件描述符时需要 sendmsg 指定地址
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("pplogger: connect journald: %w", err)
+	}
+
+	cfg := encoderConfig(config, JSONEncoding, false)
+	core := &journaldCore{
+		LevelEnabler: zapcore.DebugLevel,
+		cfg:          &cfg,
+		identifier:   syslogTag("", config.AppName),
+		context:      newFlatEncoder(&cfg),
+		conn:         conn,
+		addr:         &net.UnixAddr{Name: journalSocket, Net: "unixgram"},
+		stats:        stats,
+	}
+
+	return core, conn, nil
+}
+
+func (c *journaldCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.context = c.context.clone()
+	for _, f := range fields {
+		f.AddTo(clone.context)
+	}
+
+	return &clone
+}
+
+func (c *journaldCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *journaldCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	entry := journalEntry(c.cfg, c.identifier, c.context.fields, ent, fields)
+
+	_, err := c.conn.WriteToUnix(entry, c.addr)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		// 超过数据报大小限制时，按协议将内容写入临时文件并传递文件描述符
+		err = c.writeViaFile(entry)
+	}
+	if err != nil {
+		c.stats.writeErrors.Add(1)
+	}
+
+	return nil
+}
+
+// writeViaFile 将 entry 写入已删除的临时文件，通过 SCM_RIGHTS 将文件描述符发送给 journald
+func (c *journaldCore) writeViaFile(entry []byte) error {
+	dir := "/dev/shm"
+	if _, err := os.Stat(dir); err != nil {
+		dir = os.TempDir()
+	}
+
+	f, err := os.CreateTemp(dir, "pplogger-journal-")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(entry); err != nil {
+		return err
+	}
+
+	_, _, err = c.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), c.addr)
+	return err
+}
+
+func (c *journaldCore) Sync() error {
+	return nil
+}
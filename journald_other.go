@@ -0,0 +1,13 @@
+//go:build !linux
+
+package pplogger
+
+import (
+	"errors"
+	"go.uber.org/zap/zapcore"
+	"io"
+)
+
+func newJournaldCore(config Config, stats *sinkStats) (zapcore.Core, io.Closer, error) {
+	return nil, nil, errors.New("pplogger: journald is only supported on linux")
+}
@@ -0,0 +1,318 @@
+// Package kafka 将日志批量、异步地写入 Kafka topic，通过 pplogger.Config.Sinks 接入：
+//
+//	w, err := kafka.NewWriter(kafka.Config{Brokers: []string{"kafka:9092"}, Topic: "logs"})
+//	config.Sinks = append(config.Sinks, w)
+//
+// 发送失败或缓冲区已满的日志写入降级输出，开启 FileWriter 时即为本地日志文件
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultBatchSize    = 100
+	defaultBatchBytes   = 1 << 20
+	defaultLinger       = 100 * time.Millisecond
+	defaultBufferSize   = 10000
+	defaultWriteTimeout = 10 * time.Second
+)
+
+// Message 是发送到 Kafka 的一条消息
+type Message struct {
+	Key   []byte
+	Value []byte
+}
+
+// Producer 将一批消息发送到 Kafka，返回错误时整批写入降级输出。
+// 默认使用 segmentio/kafka-go，测试时可替换为内存实现
+type Producer interface {
+	Produce(ctx context.Context, messages []Message) error
+	Close() error
+}
+
+// Config 是 Kafka 输出的配置
+type Config struct {
+	Brokers []string // broker 地址，例如 kafka:9092
+	Topic   string
+
+	// 消息 key 的 text/template 模板，以 json 编码的日志字段为数据，例如 {{.service}}，
+	// 为空时不设置 key
+	KeyTemplate string
+
+	BatchSize    int           // 每批最多的消息条数，默认 100
+	BatchBytes   int           // 每批最多的字节数，默认 1M
+	Linger       time.Duration // 未攒满一批时最多等待的时间，默认 100ms
+	BufferSize   int           // 内存中最多缓冲的日志条数，默认 10000
+	WriteTimeout time.Duration // 单批发送的超时时间，默认 10s
+
+	Producer Producer // 自定义 Producer，设置后忽略 Brokers 与 Topic
+}
+
+// Stats 是 Writer 的运行统计
+type Stats struct {
+	Sent    uint64 // 成功发送的日志条数
+	Spilled uint64 // 发送失败或缓冲区已满而写入降级输出的日志条数
+	Dropped uint64 // 没有降级输出或降级输出写入失败而丢弃的日志条数
+}
+
+// Writer 实现 pplogger.FallbackSink，写入从不阻塞，由后台 goroutine 攒批发送
+type Writer struct {
+	config   Config
+	producer Producer
+	key      *template.Template
+
+	queue chan []byte
+	flush chan chan struct{}
+	quit  chan struct{}
+	done  chan struct{}
+
+	mu       sync.Mutex
+	fallback io.Writer
+
+	sent      atomic.Uint64
+	spilled   atomic.Uint64
+	dropped   atomic.Uint64
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewWriter 创建 Writer，使用完毕后应调用 Close，交给 Logger 时由 Logger 关闭
+func NewWriter(config Config) (*Writer, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.BatchSize == 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.BatchBytes == 0 {
+		config.BatchBytes = defaultBatchBytes
+	}
+	if config.Linger == 0 {
+		config.Linger = defaultLinger
+	}
+	if config.BufferSize == 0 {
+		config.BufferSize = defaultBufferSize
+	}
+	if config.WriteTimeout == 0 {
+		config.WriteTimeout = defaultWriteTimeout
+	}
+
+	w := &Writer{
+		config:   config,
+		producer: config.Producer,
+		queue:    make(chan []byte, config.BufferSize),
+		flush:    make(chan chan struct{}),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if config.KeyTemplate != "" {
+		w.key, _ = template.New("key").Option("missingkey=zero").Parse(config.KeyTemplate)
+	}
+	if w.producer == nil {
+		w.producer = newKafkaProducer(config)
+	}
+	go w.run()
+
+	return w, nil
+}
+
+func (c Config) validate() error {
+	var errs []error
+
+	if c.Producer == nil {
+		if len(c.Brokers) == 0 {
+			errs = append(errs, errors.New("missing Brokers"))
+		}
+		if c.Topic == "" {
+			errs = append(errs, errors.New("missing Topic"))
+		}
+	}
+
+	if c.KeyTemplate != "" {
+		if _, err := template.New("key").Parse(c.KeyTemplate); err != nil {
+			errs = append(errs, fmt.Errorf("KeyTemplate: %w", err))
+		}
+	}
+
+	if c.BatchSize < 0 || c.BatchBytes < 0 || c.Linger < 0 || c.BufferSize < 0 || c.WriteTimeout < 0 {
+		errs = append(errs, errors.New("negative BatchSize, BatchBytes, Linger, BufferSize or WriteTimeout"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("pplogger/kafka: invalid config: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// SetFallback 设置降级输出，发送失败或缓冲区已满的日志写入 w
+func (w *Writer) SetFallback(fallback io.Writer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.fallback = fallback
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	msg := append([]byte(nil), p...)
+	select {
+	case <-w.quit:
+		w.spill([][]byte{msg})
+	case w.queue <- msg:
+	default:
+		w.spill([][]byte{msg})
+	}
+
+	return len(p), nil
+}
+
+// Sync 等待此前写入的日志发送完成或写入降级输出
+func (w *Writer) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case w.flush <- ack:
+		<-ack
+	case <-w.done:
+	}
+
+	return nil
+}
+
+// Close 发送缓冲区中剩余的日志后关闭 Producer
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.quit)
+		<-w.done
+		w.closeErr = w.producer.Close()
+	})
+
+	return w.closeErr
+}
+
+// Stats 返回 Writer 的运行统计
+func (w *Writer) Stats() Stats {
+	return Stats{
+		Sent:    w.sent.Load(),
+		Spilled: w.spilled.Load(),
+		Dropped: w.dropped.Load(),
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	var batch [][]byte
+	var size int
+	linger := time.NewTimer(w.config.Linger)
+	linger.Stop()
+
+	send := func() {
+		linger.Stop()
+		if len(batch) > 0 {
+			w.send(batch)
+		}
+		batch, size = nil, 0
+	}
+	add := func(msg []byte) {
+		if len(batch) == 0 {
+			linger.Reset(w.config.Linger)
+		}
+		batch = append(batch, msg)
+		size += len(msg)
+		if len(batch) >= w.config.BatchSize || size >= w.config.BatchBytes {
+			send()
+		}
+	}
+	// drain 取出缓冲区中已有的日志
+	drain := func() {
+		for {
+			select {
+			case msg := <-w.queue:
+				add(msg)
+			default:
+				send()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case msg := <-w.queue:
+			add(msg)
+		case <-linger.C:
+			send()
+		case ack := <-w.flush:
+			drain()
+			close(ack)
+		case <-w.quit:
+			drain()
+			return
+		}
+	}
+}
+
+// send 同步发送一批日志，失败时整批写入降级输出
+func (w *Writer) send(batch [][]byte) {
+	messages := make([]Message, len(batch))
+	for i, msg := range batch {
+		messages[i] = Message{Key: w.messageKey(msg), Value: bytes.TrimRight(msg, "\r\n")}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.WriteTimeout)
+	defer cancel()
+
+	if err := w.producer.Produce(ctx, messages); err != nil {
+		w.spill(batch)
+		return
+	}
+	w.sent.Add(uint64(len(batch)))
+}
+
+// messageKey 按 KeyTemplate 生成消息 key，日志不是 json 或模板执行失败时不设置 key
+func (w *Writer) messageKey(msg []byte) []byte {
+	if w.key == nil {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(msg, &fields); err != nil {
+		return nil
+	}
+
+	var key strings.Builder
+	if err := w.key.Execute(&key, fields); err != nil || key.Len() == 0 {
+		return nil
+	}
+
+	return []byte(key.String())
+}
+
+func (w *Writer) spill(batch [][]byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, msg := range batch {
+		if w.fallback == nil {
+			w.dropped.Add(1)
+			continue
+		}
+		if _, err := w.fallback.Write(msg); err != nil {
+			w.dropped.Add(1)
+			continue
+		}
+		w.spilled.Add(1)
+	}
+}
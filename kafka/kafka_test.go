@@ -0,0 +1,165 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"github.com/piaoyunsoft/pplogger"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProducer 是内存中的 Producer，fail 为 true 时模拟 Kafka 不可用
+type fakeProducer struct {
+	mu      sync.Mutex
+	fail    bool
+	batches [][]Message
+	closed  bool
+}
+
+func (p *fakeProducer) Produce(ctx context.Context, messages []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+	p.batches = append(p.batches, messages)
+
+	return nil
+}
+
+func (p *fakeProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+
+	return nil
+}
+
+func (p *fakeProducer) snapshot() ([][]Message, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([][]Message(nil), p.batches...), p.closed
+}
+
+func TestWriterBatches(t *testing.T) {
+	producer := &fakeProducer{}
+	w, err := NewWriter(Config{Producer: producer, KeyTemplate: "{{.svc}}", BatchSize: 3, Linger: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := pplogger.NewLogger(pplogger.Config{Sinks: []pplogger.Sink{w}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		logger.Info("hello", zap.String("svc", "api"))
+	}
+	// 攒满 3 条发送一批，Sync 发送剩余的 1 条
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	batches, _ := producer.snapshot()
+	if len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 1 {
+		t.Fatalf("got batches %+v", batches)
+	}
+	msg := batches[0][0]
+	if string(msg.Key) != "api" || !strings.HasPrefix(string(msg.Value), "{") || strings.HasSuffix(string(msg.Value), "\n") {
+		t.Errorf("unexpected message key %q value %q", msg.Key, msg.Value)
+	}
+
+	logger.Info("last")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, closed := producer.snapshot(); !closed {
+		t.Error("Close did not close the producer")
+	}
+	if stats := w.Stats(); stats != (Stats{Sent: 5}) {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestWriterLinger(t *testing.T) {
+	producer := &fakeProducer{}
+	w, err := NewWriter(Config{Producer: producer, Linger: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte(`{"msg":"hello"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Stats().Sent != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("batch was not sent after Linger")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWriterSpillsToFile(t *testing.T) {
+	dir := t.TempDir()
+	producer := &fakeProducer{fail: true}
+	w, err := NewWriter(Config{Producer: producer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := pplogger.NewLogger(pplogger.Config{Sinks: []pplogger.Sink{w}, FileWriter: true, LogPath: dir, Filename: "app.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("kafka is down")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := w.Stats(); stats != (Stats{Spilled: 1}) {
+		t.Errorf("Stats = %+v", stats)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "kafka is down") {
+		t.Errorf("spilled entry missing from file: %q", b)
+	}
+}
+
+func TestWriterWithoutFallback(t *testing.T) {
+	w, err := NewWriter(Config{Producer: &fakeProducer{fail: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("lost\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := w.Stats(); stats != (Stats{Dropped: 1}) {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestNewWriterInvalid(t *testing.T) {
+	for _, config := range []Config{
+		{},
+		{Brokers: []string{"kafka:9092"}},
+		{Topic: "logs"},
+		{Producer: &fakeProducer{}, KeyTemplate: "{{.svc"},
+		{Producer: &fakeProducer{}, BatchSize: -1},
+	} {
+		if _, err := NewWriter(config); err == nil {
+			t.Errorf("NewWriter(%+v) succeeded", config)
+		}
+	}
+}
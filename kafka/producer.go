@@ -0,0 +1,40 @@
+package kafka
+
+import (
+	"context"
+	"github.com/segmentio/kafka-go"
+	"time"
+)
+
+// kafkaProducer 基于 kafka-go 同步发送，Writer 已经攒好批次，因此不再额外等待
+type kafkaProducer struct {
+	writer *kafka.Writer
+}
+
+func newKafkaProducer(config Config) *kafkaProducer {
+	return &kafkaProducer{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(config.Brokers...),
+			Topic:        config.Topic,
+			Balancer:     &kafka.Hash{},
+			BatchSize:    config.BatchSize,
+			BatchBytes:   int64(config.BatchBytes),
+			BatchTimeout: time.Millisecond,
+			WriteTimeout: config.WriteTimeout,
+			RequiredAcks: kafka.RequireOne,
+		},
+	}
+}
+
+func (p *kafkaProducer) Produce(ctx context.Context, messages []Message) error {
+	msgs := make([]kafka.Message, len(messages))
+	for i, m := range messages {
+		msgs[i] = kafka.Message{Key: m.Key, Value: m.Value}
+	}
+
+	return p.writer.WriteMessages(ctx, msgs...)
+}
+
+func (p *kafkaProducer) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,324 @@
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// moduleLevels 保存按 logger 名称配置的日志等级，创建后不再修改，修改时整体替换，写日志时无需加锁
+type moduleLevels struct {
+	levels map[string]zapcore.Level
+	min    zapcore.Level
+}
+
+// lookup 返回 name 对应的等级：依次去掉 name 最后一段（按 . 分隔）查找，最长的前缀优先
+func (m *moduleLevels) lookup(name string) (zapcore.Level, bool) {
+	if len(m.levels) == 0 {
+		return zapcore.InvalidLevel, false
+	}
+
+	for {
+		if level, ok := m.levels[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return zapcore.InvalidLevel, false
+		}
+		name = name[:i]
+	}
+}
+
+// newModuleLevels 由 levels 创建 moduleLevels，levels 不再修改
+func newModuleLevels(levels map[string]zapcore.Level) *moduleLevels {
+	m := &moduleLevels{levels: levels, min: zapcore.InvalidLevel}
+	for _, level := range levels {
+		if m.min == zapcore.InvalidLevel || level < m.min {
+			m.min = level
+		}
+	}
+
+	return m
+}
+
+func parseModuleLevels(levels map[string]string) (*moduleLevels, error) {
+	parsed := make(map[string]zapcore.Level, len(levels))
+	for name, str := range levels {
+		level, err := ParseLevel(str)
+		if err != nil {
+			return nil, fmt.Errorf("module %q: %w", name, err)
+		}
+		parsed[name] = level
+	}
+
+	return newModuleLevels(parsed), nil
+}
+
+// levelCore 按 logger 名称决定日志等级，未单独配置的名称使用全局等级
+type levelCore struct {
+	zapcore.Core
+	level   zapcore.LevelEnabler
+	modules *atomic.Pointer[moduleLevels]
+}
+
+func newLevelCore(core zapcore.Core, level zapcore.LevelEnabler, modules *atomic.Pointer[moduleLevels]) *levelCore {
+	return &levelCore{Core: core, level: level, modules: modules}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	if c.level.Enabled(level) {
+		return true
+	}
+
+	m := c.modules.Load()
+	return len(m.levels) > 0 && level >= m.min
+}
+
+func (c *levelCore) Level() zapcore.Level {
+	level := zapcore.LevelOf(c.level)
+	if m := c.modules.Load(); len(m.levels) > 0 && m.min < level {
+		return m.min
+	}
+
+	return level
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return newLevelCore(c.Core.With(fields), c.level, c.modules)
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if level, ok := c.modules.Load().lookup(ent.LoggerName); ok {
+		if ent.Level >= level {
+			return ce.AddCore(ent, c)
+		}
+		return ce
+	}
+
+	if c.level.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *levelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, fields)
+}
+
+// AtomicLevel 返回控制全局日志等级的 zap.AtomicLevel
+func (l *Logger) AtomicLevel() zap.AtomicLevel {
+	return l.level
+}
+
+// SetLevel 修改全局日志等级，可与日志写入并发调用
+func (l *Logger) SetLevel(level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("pplogger: %w", err)
+	}
+	l.level.SetLevel(lvl)
+
+	return nil
+}
+
+// changeLevel 修改全局日志等级，等级变化时输出一条 Info，附加 from、to 及 fields。
+// Info 在较低的等级下输出，调高等级时同样可见
+func (l *Logger) changeLevel(level zapcore.Level, msg string, fields ...zap.Field) {
+	old := l.level.Level()
+	if old == level {
+		return
+	}
+
+	fields = append(fields, zap.String("from", levelString(old)), zap.String("to", levelString(level)))
+	if level < old {
+		l.level.SetLevel(level)
+		l.Info(msg, fields...)
+		return
+	}
+	l.Info(msg, fields...)
+	l.level.SetLevel(level)
+}
+
+// GetLevel 返回当前的全局日志等级，格式同 DebugLevel 等常量
+func (l *Logger) GetLevel() string {
+	return levelString(l.level.Level())
+}
+
+// levelString 将 zapcore.Level 转换为本包的等级常量
+func levelString(level zapcore.Level) string {
+	switch level {
+	case zapcore.DebugLevel:
+		return DebugLevel
+	case zapcore.InfoLevel:
+		return InfoLevel
+	case zapcore.WarnLevel:
+		return WarnLevel
+	case zapcore.ErrorLevel:
+		return ErrorLevel
+	case zapcore.DPanicLevel:
+		return DPanicLevel
+	case zapcore.PanicLevel:
+		return PanicLevel
+	case zapcore.FatalLevel:
+		return FatalLevel
+	default:
+		return level.String()
+	}
+}
+
+// SetModuleLevels 替换按 logger 名称配置的日志等级，立即生效
+func (l *Logger) SetModuleLevels(levels map[string]string) error {
+	m, err := parseModuleLevels(levels)
+	if err != nil {
+		return fmt.Errorf("pplogger: %w", err)
+	}
+	l.modules.Store(m)
+
+	return nil
+}
+
+// SetModuleLevel 设置名称为 name 及以 name. 开头的 logger 的日志等级，立即生效，保留其他名称的设置
+func (l *Logger) SetModuleLevel(name, level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("pplogger: %w", err)
+	}
+	l.updateModuleLevels(func(levels map[string]zapcore.Level) {
+		levels[name] = lvl
+	})
+
+	return nil
+}
+
+// ResetModuleLevel 移除 SetModuleLevel 或 ModuleLevels 对 name 的设置，之后按更短的前缀或全局等级
+func (l *Logger) ResetModuleLevel(name string) {
+	l.updateModuleLevels(func(levels map[string]zapcore.Level) {
+		delete(levels, name)
+	})
+}
+
+// updateModuleLevels 复制当前的设置交给 update 修改后替换，与并发的修改互不覆盖
+func (l *Logger) updateModuleLevels(update func(map[string]zapcore.Level)) {
+	for {
+		old := l.modules.Load()
+		levels := make(map[string]zapcore.Level, len(old.levels)+1)
+		for name, level := range old.levels {
+			levels[name] = level
+		}
+		update(levels)
+		if l.modules.CompareAndSwap(old, newModuleLevels(levels)) {
+			return
+		}
+	}
+}
+
+// levelFilterCore 只写入 enab 允许的等级，用于按等级将日志分流到不同输出。
+// 过滤放在 Write 中，因为外层 levelCore 通过 NewTee 写入时不会再调用各输出的 Check
+type levelFilterCore struct {
+	zapcore.Core
+	enab zapcore.LevelEnabler
+}
+
+func newLevelFilterCore(core zapcore.Core, enab zapcore.LevelEnabler) zapcore.Core {
+	return &levelFilterCore{Core: core, enab: enab}
+}
+
+func (c *levelFilterCore) Enabled(level zapcore.Level) bool {
+	return c.enab.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return newLevelFilterCore(c.Core.With(fields), c.enab)
+}
+
+func (c *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *levelFilterCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.enab.Enabled(ent.Level) {
+		return nil
+	}
+
+	return c.Core.Write(ent, fields)
+}
+
+// levelSet 是一组日志等级，实现 zapcore.LevelEnabler
+type levelSet map[zapcore.Level]bool
+
+func (s levelSet) Enabled(level zapcore.Level) bool {
+	return s[level]
+}
+
+// levelRoute 是 LevelOutputs 中指向同一文件的等级
+type levelRoute struct {
+	filename string
+	levels   levelSet
+}
+
+// parseLevelOutputs 解析 Config.LevelOutputs，按文件名合并等级并排序，等级重复或无法解析时返回错误
+func parseLevelOutputs(outputs map[string]string) ([]levelRoute, error) {
+	keys := make([]string, 0, len(outputs))
+	for key := range outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var routes []levelRoute
+	index := make(map[string]int)
+	seen := make(map[zapcore.Level]string)
+	for _, key := range keys {
+		filename := outputs[key]
+		if filename == "" {
+			return nil, fmt.Errorf("LevelOutputs %q: empty filename", key)
+		}
+
+		i, ok := index[filename]
+		if !ok {
+			i = len(routes)
+			index[filename] = i
+			routes = append(routes, levelRoute{filename: filename, levels: levelSet{}})
+		}
+
+		for _, token := range strings.Split(key, "|") {
+			if strings.TrimSpace(token) == "" {
+				return nil, fmt.Errorf("LevelOutputs %q: empty level", key)
+			}
+			level, err := ParseLevel(token)
+			if err != nil {
+				return nil, fmt.Errorf("LevelOutputs %q: %w", key, err)
+			}
+			if other, ok := seen[level]; ok {
+				return nil, fmt.Errorf("LevelOutputs: level %s appears in both %q and %q", levelString(level), other, key)
+			}
+			seen[level] = key
+			routes[i].levels[level] = true
+		}
+	}
+
+	return routes, nil
+}
+
+// unroutedLevels 返回没有出现在 routes 中的等级
+func unroutedLevels(routes []levelRoute) levelSet {
+	levels := levelSet{}
+	for level := zapcore.DebugLevel; level <= zapcore.FatalLevel; level++ {
+		levels[level] = true
+	}
+	for _, route := range routes {
+		for level := range route.levels {
+			delete(levels, level)
+		}
+	}
+
+	return levels
+}
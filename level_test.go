@@ -0,0 +1,62 @@
+package pplogger
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestModuleLevels(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{
+		LogLevel:          WarnLevel,
+		Encoding:          ConsoleEncoding,
+		DisableCaller:     true,
+		DisableStacktrace: true,
+		ExtraWriters:      []io.Writer{&buf},
+		ModuleLevels: map[string]string{
+			"api":    "debug",
+			"api.v2": "error",
+			"":       "error",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	api := logger.Named("api")
+	api.Debug("api debug")            // 低于全局等级的覆盖仍然输出
+	api.Named("v1").Debug("v1 debug") // 按前缀继承 api
+	api.Named("v2").Warn("v2 warn")   // 较长的前缀优先
+	api.Named("v2").Error("v2 error")
+	logger.Named("apiserver").Info("apiserver info") // 前缀按名称分段匹配
+	logger.Warn("root warn")                         // 空名称对应根 Logger
+	logger.Named("db").Warn("db warn")
+
+	// 运行时修改立即生效
+	if err := logger.SetModuleLevel("db", "error"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Named("db").Warn("db warn after set")
+	logger.ResetModuleLevel("api.v2")
+	api.Named("v2").Debug("v2 debug after reset")
+	logger.ResetModuleLevel("")
+	logger.Warn("root warn after reset")
+
+	if err := logger.SetModuleLevel("db", "loud"); err == nil {
+		t.Error("SetModuleLevel with an invalid level should fail")
+	}
+
+	_ = logger.Sync()
+	got := buf.lines()
+	want := []string{"api debug", "v1 debug", "v2 error", "db warn", "v2 debug after reset", "root warn after reset"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d: %q", len(got), len(want), got)
+	}
+	for i, msg := range want {
+		if !strings.HasSuffix(got[i], "\t"+msg) {
+			t.Errorf("line %d = %q, want message %q", i, got[i], msg)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package pplogger
+
+import (
+	"os"
+	"strings"
+)
+
+// DefaultLevelEnv 是 DefaultConfig 及各预设中 Config.LevelFromEnv 的默认值
+const DefaultLevelEnv = "PPLOGGER_LEVEL"
+
+// levelFromEnv 读取 LevelFromEnv 指定的环境变量，返回去掉首尾空白后的值。
+// LevelFromEnv 为空或变量未设置、为空时返回空字符串，err 为值无法被 ParseLevel 解析时的错误
+func (config Config) levelFromEnv() (string, error) {
+	if config.LevelFromEnv == "" {
+		return "", nil
+	}
+
+	value := strings.TrimSpace(os.Getenv(config.LevelFromEnv))
+	if value == "" {
+		return "", nil
+	}
+	if _, err := ParseLevel(value); err != nil {
+		return value, err
+	}
+
+	return value, nil
+}
@@ -0,0 +1,86 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLevelFromEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		fromEnv   string
+		levelFile string
+		want      zapcore.Level
+	}{
+		{"disabled by default", map[string]string{DefaultLevelEnv: "debug"}, "", "", zapcore.WarnLevel},
+		{"env overrides LogLevel", map[string]string{DefaultLevelEnv: " DEBUG "}, DefaultLevelEnv, "", zapcore.DebugLevel},
+		{"unset falls back to LogLevel", nil, DefaultLevelEnv, "", zapcore.WarnLevel},
+		{"empty falls back to LogLevel", map[string]string{DefaultLevelEnv: " "}, DefaultLevelEnv, "", zapcore.WarnLevel},
+		{"invalid falls back to LogLevel", map[string]string{DefaultLevelEnv: "loud"}, DefaultLevelEnv, "", zapcore.WarnLevel},
+		{"custom variable", map[string]string{DefaultLevelEnv: "debug", "APP_LEVEL": "error"}, "APP_LEVEL", "", zapcore.ErrorLevel},
+		{"LevelFile overrides env", map[string]string{DefaultLevelEnv: "debug"}, DefaultLevelEnv, "error", zapcore.ErrorLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DefaultLevelEnv, "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if _, ok := tt.env[DefaultLevelEnv]; !ok {
+				os.Unsetenv(DefaultLevelEnv)
+			}
+
+			config := Config{DiscardWriter: true, LogLevel: "warn", LevelFromEnv: tt.fromEnv}
+			if tt.levelFile != "" {
+				config.LevelFile = filepath.Join(t.TempDir(), "level")
+				if err := os.WriteFile(config.LevelFile, []byte(tt.levelFile), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			logger, err := NewLogger(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer logger.Close()
+
+			if got := logger.AtomicLevel().Level(); got != tt.want {
+				t.Fatalf("level = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelFromEnvInvalidWarns(t *testing.T) {
+	t.Setenv(DefaultLevelEnv, "loud")
+	buf := &syncBuffer{}
+	logger, err := NewLogger(Config{ExtraWriters: []io.Writer{buf}, LevelFromEnv: DefaultLevelEnv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	if out := buf.String(); !strings.Contains(out, "invalid log level in environment variable") || !strings.Contains(out, "loud") {
+		t.Fatalf("output = %q", out)
+	}
+}
+
+func TestLevelFromEnvDefaultConfig(t *testing.T) {
+	t.Setenv(DefaultLevelEnv, "debug")
+	config := DefaultConfig()
+	config.DiscardWriter = true
+	logger, err := NewLogger(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	// 环境变量的等级作为生效的 LogLevel，SIGUSR2 及 LevelFile 删除后恢复为该等级
+	if logger.AtomicLevel().Level() != zapcore.DebugLevel || logger.Config().LogLevel != "debug" {
+		t.Fatalf("level = %s, LogLevel = %q", logger.AtomicLevel().Level(), logger.Config().LogLevel)
+	}
+}
@@ -0,0 +1,75 @@
+package pplogger
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// levelFilePollInterval 是检查 LevelFile 的间隔
+const levelFilePollInterval = time.Second
+
+// levelFile 按 Config.LevelFile 的内容设置 Logger 的全局等级
+type levelFile struct {
+	logger  *Logger
+	path    string
+	content string // 上次读取的内容，去掉首尾空白
+	exists  bool
+}
+
+// watchLevelFile 立即按 path 的内容设置 l 的等级，之后每秒检查一次，返回的函数停止检查
+func watchLevelFile(l *Logger, path string) func() {
+	f := &levelFile{logger: l, path: path}
+	f.check()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(levelFilePollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				f.check()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// check 在文件内容变化时应用其中的等级，无法解析时保留当前等级并输出 Warn，文件被删除时恢复为 LogLevel
+func (f *levelFile) check() {
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && f.exists {
+			f.exists, f.content = false, ""
+			level, _ := ParseLevel(f.logger.config.LogLevel)
+			f.logger.changeLevel(level, "pplogger: log level restored after LevelFile was removed", zap.String("path", f.path))
+		}
+		return
+	}
+
+	content := strings.TrimSpace(string(b))
+	if f.exists && content == f.content {
+		return
+	}
+	f.exists, f.content = true, content
+
+	// 空文件按 ParseLevel 视为 Info，与 LogLevel 为空时一致
+	level, err := ParseLevel(content)
+	if err != nil {
+		f.logger.Warn("pplogger: ignoring invalid LevelFile", zap.String("path", f.path), zap.Error(err))
+		return
+	}
+	f.logger.changeLevel(level, "pplogger: log level changed by LevelFile", zap.String("path", f.path))
+}
@@ -0,0 +1,86 @@
+package pplogger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLevelFileCheck(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{
+		LogLevel:     WarnLevel,
+		Encoding:     ConsoleEncoding,
+		ExtraWriters: []io.Writer{&buf},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	path := filepath.Join(t.TempDir(), "level")
+	f := &levelFile{logger: logger, path: path}
+
+	// 依次改写文件并直接调用 check，不依赖轮询间隔
+	for _, tt := range []struct {
+		name    string
+		content *string
+		want    string
+		wantLog string
+	}{
+		{"missing at startup", nil, WarnLevel, ""},
+		{"whitespace", ptr("  debug\n"), DebugLevel, "log level changed by LevelFile"},
+		{"alias", ptr("WARNING"), WarnLevel, "log level changed by LevelFile"},
+		{"invalid keeps level", ptr("bogus"), WarnLevel, "ignoring invalid LevelFile"},
+		{"valid after invalid", ptr("info"), InfoLevel, ""},
+		{"removed", nil, WarnLevel, "log level restored after LevelFile was removed"},
+	} {
+		if tt.content == nil {
+			_ = os.Remove(path)
+		} else if err := os.WriteFile(path, []byte(*tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		before := len(buf.String())
+		f.check()
+
+		if got := logger.GetLevel(); got != tt.want {
+			t.Errorf("%s: level = %s, want %s", tt.name, got, tt.want)
+		}
+		if tt.wantLog != "" && !strings.Contains(buf.String()[before:], tt.wantLog) {
+			t.Errorf("%s: log = %q, want %q", tt.name, buf.String()[before:], tt.wantLog)
+		}
+	}
+}
+
+func TestLevelFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "level")
+	if err := os.WriteFile(path, []byte("debug\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewLogger(Config{DiscardWriter: true, LogLevel: WarnLevel, LevelFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	// 启动时立即读取文件
+	if got := logger.GetLevel(); got != DebugLevel {
+		t.Fatalf("level = %s, want %s", got, DebugLevel)
+	}
+
+	if err := os.WriteFile(path, []byte("error"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "level change", func() bool { return logger.GetLevel() == ErrorLevel })
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "level revert", func() bool { return logger.GetLevel() == WarnLevel })
+}
+
+func ptr(s string) *string {
+	return &s
+}
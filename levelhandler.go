@@ -0,0 +1,86 @@
+package pplogger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strings"
+)
+
+// validLevels 是 LevelHandler 出错时提示的可用等级
+var validLevels = []string{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel, PanicLevel, FatalLevel}
+
+// levelPayload 是 LevelHandler 的请求及响应
+type levelPayload struct {
+	Level  string `json:"level,omitempty"`
+	Logger string `json:"logger,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// LevelHandler 返回在运行时查看及修改日志等级的 http.Handler，例如挂载到 /debug/loglevel：
+// GET 返回 {"level":"Info"}，PUT 或 POST {"level":"debug"} 修改等级，等级无效时返回 400。
+// 带有 ?logger=db 时针对该名称的 ModuleLevels，GET 按前缀匹配返回其实际等级，未配置时返回全局等级
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(l.serveLevel)
+}
+
+func (l *Logger) serveLevel(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("logger")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req levelPayload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeLevel(w, http.StatusBadRequest, levelPayload{Error: fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+		level, err := parseRequestLevel(req.Level)
+		if err != nil {
+			writeLevel(w, http.StatusBadRequest, levelPayload{Error: err.Error()})
+			return
+		}
+		if name == "" {
+			l.level.SetLevel(level)
+		} else {
+			l.updateModuleLevels(func(levels map[string]zapcore.Level) {
+				levels[name] = level
+			})
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeLevel(w, http.StatusMethodNotAllowed, levelPayload{Error: "method not allowed, use GET, PUT or POST"})
+		return
+	}
+
+	level := l.level.Level()
+	if name != "" {
+		if lvl, ok := l.modules.Load().lookup(name); ok {
+			level = lvl
+		}
+	}
+	writeLevel(w, http.StatusOK, levelPayload{Level: levelString(level), Logger: name})
+}
+
+// parseRequestLevel 解析请求中的等级，与 ParseLevel 不同，空字符串视为错误
+func parseRequestLevel(str string) (zapcore.Level, error) {
+	valid := "valid levels are " + strings.Join(validLevels, ", ")
+	if strings.TrimSpace(str) == "" {
+		return zapcore.InvalidLevel, errors.New(`missing "level", ` + valid)
+	}
+
+	level, err := ParseLevel(str)
+	if err != nil {
+		return level, fmt.Errorf("%w, %s", err, valid)
+	}
+
+	return level, nil
+}
+
+func writeLevel(w http.ResponseWriter, status int, payload levelPayload) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
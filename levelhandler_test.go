@@ -0,0 +1,65 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	logger, err := NewLogger(Config{
+		DiscardWriter: true,
+		ModuleLevels:  map[string]string{"cache": "warn"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := logger.LevelHandler()
+
+	// 各请求依次执行，后面的请求依赖前面修改后的等级
+	tests := []struct {
+		method string
+		query  string
+		body   string
+		code   int
+		want   string
+	}{
+		{http.MethodGet, "", "", http.StatusOK, `{"level":"Info"}`},
+		{http.MethodPut, "", `{"level":"debug"}`, http.StatusOK, `{"level":"Debug"}`},
+		{http.MethodGet, "", "", http.StatusOK, `{"level":"Debug"}`},
+		{http.MethodPost, "", `{"level":"warning"}`, http.StatusOK, `{"level":"Warn"}`},
+		{http.MethodPut, "", `{"level":"loud"}`, http.StatusBadRequest, `valid levels are Debug, Info, Warn, Error, DPanic, Panic, Fatal`},
+		{http.MethodPut, "", `{}`, http.StatusBadRequest, `missing \"level\"`},
+		{http.MethodPost, "", `not json`, http.StatusBadRequest, `invalid request body`},
+		{http.MethodDelete, "", "", http.StatusMethodNotAllowed, `method not allowed`},
+		{http.MethodGet, "", "", http.StatusOK, `{"level":"Warn"}`},
+		{http.MethodGet, "?logger=cache", "", http.StatusOK, `{"level":"Warn","logger":"cache"}`},
+		{http.MethodGet, "?logger=db", "", http.StatusOK, `{"level":"Warn","logger":"db"}`},
+		{http.MethodPut, "?logger=db", `{"level":"debug"}`, http.StatusOK, `{"level":"Debug","logger":"db"}`},
+		{http.MethodGet, "?logger=db.query", "", http.StatusOK, `{"level":"Debug","logger":"db.query"}`},
+		{http.MethodGet, "", "", http.StatusOK, `{"level":"Warn"}`},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/debug/loglevel"+tt.query, strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		body := strings.TrimSpace(rec.Body.String())
+		if rec.Code != tt.code || !strings.Contains(body, tt.want) {
+			t.Errorf("%s %s %s = %d %s, want %d %s", tt.method, tt.query, tt.body, rec.Code, body, tt.code, tt.want)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s %s Content-Type = %q", tt.method, tt.query, ct)
+		}
+	}
+
+	// 修改 db 的等级只影响该模块
+	if logger.Named("db").Check(zapcore.DebugLevel, "") == nil {
+		t.Error("db logger should enable debug")
+	}
+	if logger.Named("api").Check(zapcore.InfoLevel, "") != nil {
+		t.Error("api logger should follow the global warn level")
+	}
+}
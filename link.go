@@ -0,0 +1,95 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// validSymlinkName 检查 Config.SymlinkName 是 LogPath 下的文件名，且不会覆盖日志文件
+func (config Config) validSymlinkName() error {
+	name := config.SymlinkName
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid SymlinkName %q, want a file name without directory", name)
+	}
+	if !config.FileWriter {
+		return errors.New("SymlinkName requires FileWriter")
+	}
+	for _, filename := range []string{config.Filename, config.CombinedFilename, config.ErrorFilename} {
+		if name == filename {
+			return fmt.Errorf("SymlinkName %q is the same as a log file", name)
+		}
+	}
+
+	return nil
+}
+
+// currentLink 维护指向当前日志文件的链接，见 Config.SymlinkName。
+// 先创建临时链接再重命名覆盖，读取方不会看到链接不存在的中间状态
+type currentLink struct {
+	mu       sync.Mutex
+	path     string
+	target   string
+	hard     bool // 无法创建符号链接时改用硬链接，硬链接在每次切割后都需要更新
+	disabled bool // 硬链接同样失败时不再尝试
+	warn     func(error)
+}
+
+func newCurrentLink(path string) *currentLink {
+	return &currentLink{path: path}
+}
+
+// update 将链接指向 target，失败时只提示一次，之后不再更新
+func (l *currentLink) update(target string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.disabled || (!l.hard && target == l.target) {
+		return
+	}
+
+	if err := l.link(target); err != nil {
+		l.disabled = true
+		if l.warn != nil {
+			// 调用方可能持有日志文件的锁，提示在单独的 goroutine 中输出
+			go l.warn(err)
+		}
+		return
+	}
+	l.target = target
+}
+
+func (l *currentLink) link(target string) error {
+	tmp := l.path + ".tmp"
+	_ = os.Remove(tmp)
+
+	if !l.hard {
+		// 使用相对路径，整个目录移动后链接仍然有效
+		rel, err := filepath.Rel(filepath.Dir(l.path), target)
+		if err != nil {
+			rel = target
+		}
+		if err := os.Symlink(rel, tmp); err == nil {
+			return l.rename(tmp)
+		}
+		l.hard = true
+	}
+
+	if err := os.Link(target, tmp); err != nil {
+		return fmt.Errorf("pplogger: link %s: %w", l.path, err)
+	}
+
+	return l.rename(tmp)
+}
+
+func (l *currentLink) rename(tmp string) error {
+	if err := os.Rename(tmp, l.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("pplogger: link %s: %w", l.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"unicode/utf8"
+)
+
+var bufferPool = buffer.NewPool()
+
+// logfmtEncoder 输出 key=value 形式的 logfmt 日志，嵌套对象的字段以 . 连接展开
+type logfmtEncoder struct {
+	*flatEncoder
+	cfg *zapcore.EncoderConfig
+}
+
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{flatEncoder: newFlatEncoder(&cfg), cfg: &cfg}
+}
+
+func (enc *logfmtEncoder) Clone() zapcore.Encoder {
+	return &logfmtEncoder{flatEncoder: enc.flatEncoder.clone(), cfg: enc.cfg}
+}
+
+func (enc *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := newFlatEncoder(enc.cfg)
+	final.addHeader(ent)
+	final.fields = append(final.fields, enc.fields...)
+	final.prefix = enc.prefix
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+	final.prefix = ""
+	if ent.Stack != "" && enc.cfg.StacktraceKey != "" {
+		final.add(enc.cfg.StacktraceKey, ent.Stack)
+	}
+
+	buf := bufferPool.Get()
+	for i, f := range final.fields {
+		if i > 0 {
+			buf.AppendByte(' ')
+		}
+		appendLogfmtKey(buf, f.Key)
+		buf.AppendByte('=')
+		appendLogfmtValue(buf, f.Value)
+	}
+	lineEnding := enc.cfg.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	buf.AppendString(lineEnding)
+
+	return buf, nil
+}
+
+// appendLogfmtKey 写入 key，空白、= 及引号等不允许出现在 key 中的字符替换为 _
+func appendLogfmtKey(buf *buffer.Buffer, key string) {
+	if key == "" {
+		buf.AppendByte('_')
+		return
+	}
+
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			buf.AppendByte('_')
+		} else {
+			buf.AppendString(string(r))
+		}
+	}
+}
+
+// appendLogfmtValue 写入 value，包含空白、=、引号或控制字符时加引号并转义
+func appendLogfmtValue(buf *buffer.Buffer, value string) {
+	if !logfmtNeedsQuote(value) {
+		buf.AppendString(value)
+		return
+	}
+
+	const hex = "0123456789abcdef"
+	buf.AppendByte('"')
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		i += size
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf.AppendString("\ufffd")
+		case r == '"' || r == '\\':
+			buf.AppendByte('\\')
+			buf.AppendByte(byte(r))
+		case r == '\n':
+			buf.AppendString(`\n`)
+		case r == '\r':
+			buf.AppendString(`\r`)
+		case r == '\t':
+			buf.AppendString(`\t`)
+		case r < 0x20:
+			buf.AppendString(`\u00`)
+			buf.AppendByte(hex[r>>4])
+			buf.AppendByte(hex[r&0xf])
+		default:
+			buf.AppendString(string(r))
+		}
+	}
+	buf.AppendByte('"')
+}
+
+func logfmtNeedsQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || r == 0x7f {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,109 @@
+package pplogger
+
+import (
+	"github.com/go-logfmt/logfmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	city string
+	zip  int
+}
+
+func (a testAddress) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("city", a.city)
+	return enc.AddObject("code", testObject{name: "zip", count: a.zip})
+}
+
+// decodeLogfmt 用 logfmt 解析器解析一条日志
+func decodeLogfmt(t *testing.T, s string) map[string]string {
+	t.Helper()
+
+	d := logfmt.NewDecoder(strings.NewReader(s))
+	records := 0
+	m := map[string]string{}
+	for d.ScanRecord() {
+		records++
+		for d.ScanKeyval() {
+			m[string(d.Key())] = string(d.Value())
+		}
+	}
+	if err := d.Err(); err != nil {
+		t.Fatalf("invalid logfmt %q: %v", s, err)
+	}
+	if records != 1 {
+		t.Fatalf("got %d records, want 1: %q", records, s)
+	}
+
+	return m
+}
+
+func TestLogfmtEncoding(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{Encoding: LogfmtEncoding, ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.With(zap.String("svc", "a=b")).Sugar().Infow("user logged in",
+		"user", `say "hi"`,
+		"addr", testAddress{city: "北京 市", zip: 100000},
+		"ids", []int{1, 2},
+	)
+	_ = logger.Sync()
+
+	m := decodeLogfmt(t, buf.String())
+	want := map[string]string{
+		"level":           "info",
+		"msg":             "user logged in",
+		"svc":             "a=b",
+		"user":            `say "hi"`,
+		"addr.city":       "北京 市",
+		"addr.code.name":  "zip",
+		"addr.code.count": "100000",
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %q, want %q", k, m[k], v)
+		}
+	}
+	if m["ts"] == "" || !strings.Contains(m["caller"], "/logfmt_test.go:") {
+		t.Errorf("unexpected header %v", m)
+	}
+}
+
+func TestLogfmtRoundTrip(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"plain",
+		"with space",
+		"a=b",
+		`quote " inside`,
+		`back\slash`,
+		"new\nline",
+		"carriage\rreturn",
+		"tab\there",
+		"中文 日志",
+		"emoji 🚀",
+	} {
+		var buf syncBuffer
+		logger, err := NewLogger(Config{Encoding: LogfmtEncoding, ExtraWriters: []io.Writer{&buf}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Info(value, zap.String("v", value))
+		_ = logger.Sync()
+
+		if n := len(buf.lines()); n != 1 {
+			t.Errorf("%q produced %d lines", value, n)
+			continue
+		}
+		m := decodeLogfmt(t, buf.String())
+		if m["msg"] != value || m["v"] != value {
+			t.Errorf("round trip of %q: msg=%q v=%q", value, m["msg"], m["v"])
+		}
+	}
+}
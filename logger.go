@@ -0,0 +1,119 @@
+package pplogger
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Logger 封装 *zap.Logger，负责在关闭时刷新并关闭底层的日志文件
+type Logger struct {
+	*zap.Logger
+
+	config      Config
+	level       zap.AtomicLevel
+	modules     atomic.Pointer[moduleLevels]
+	undoGlobals func()
+	stopSIGHUP  func()
+	stopSignals func() // 见 Config.SignalLevelToggle
+	stopFile    func() // 见 Config.LevelFile
+	closers     []io.Closer
+	closeOnce   sync.Once
+	closeErr    error
+	stats       sinkStats
+	ring        *ringBuffer
+	drops       []*dropPattern // 见 Config.DropPatterns
+}
+
+// Stats 是 Logger 各输出的运行统计
+type Stats struct {
+	WriteErrors uint64 // syslog 等网络输出写入失败的次数，失败的日志会被丢弃，不影响其他输出
+	Dropped     uint64 // 异步输出因缓冲区已满而丢弃的日志条数
+	Reconnects  uint64 // TCP 输出断线后重新连接的次数
+	Sampled     uint64 // 因 Config.Sampling 采样而丢弃的日志条数
+	RateLimited uint64 // 因 Config.RateLimit 限流而丢弃的日志条数
+
+	// Config.DropPatterns 各规则丢弃的日志条数，key 为规则，未设置时为 nil
+	DroppedByPattern map[string]uint64
+}
+
+// sinkStats 由各输出共享，原子计数
+type sinkStats struct {
+	writeErrors atomic.Uint64
+	dropped     atomic.Uint64
+	reconnects  atomic.Uint64
+	sampled     atomic.Uint64
+	rateLimited atomic.Uint64
+}
+
+// Stats 返回 Logger 的运行统计
+func (l *Logger) Stats() Stats {
+	stats := Stats{
+		WriteErrors: l.stats.writeErrors.Load(),
+		Dropped:     l.stats.dropped.Load(),
+		Reconnects:  l.stats.reconnects.Load(),
+		Sampled:     l.stats.sampled.Load(),
+		RateLimited: l.stats.rateLimited.Load(),
+	}
+	if len(l.drops) > 0 {
+		stats.DroppedByPattern = make(map[string]uint64, len(l.drops))
+		for _, p := range l.drops {
+			stats.DroppedByPattern[p.pattern] += p.dropped.Load()
+		}
+	}
+
+	return stats
+}
+
+// NewLogger 根据 Config 创建 Logger，使用完毕后应调用 Close
+func NewLogger(config Config) (*Logger, error) {
+	return newLogger([]Option{WithConfig(config)})
+}
+
+// Config 返回填充默认值之后实际生效的配置
+func (l *Logger) Config() Config {
+	return l.config
+}
+
+// RestoreGlobals 撤销 Config.ReplaceGlobals 对 zap 全局日志的替换，可重复调用
+func (l *Logger) RestoreGlobals() {
+	if l.undoGlobals != nil {
+		l.undoGlobals()
+	}
+}
+
+// Close 刷新缓冲并关闭日志文件，可重复调用，关闭后的日志写入会被丢弃
+func (l *Logger) Close() error {
+	l.closeOnce.Do(func() {
+		if l.stopSIGHUP != nil {
+			l.stopSIGHUP()
+		}
+		if l.stopSignals != nil {
+			l.stopSignals()
+		}
+		if l.stopFile != nil {
+			l.stopFile()
+		}
+		l.RestoreGlobals()
+		errs := []error{l.Logger.Sync()}
+		for _, c := range l.closers {
+			errs = append(errs, c.Close())
+		}
+		l.closeErr = errors.Join(errs...)
+	})
+
+	return l.closeErr
+}
+
+// stdoutSyncer 忽略终端或管道不支持 Sync 时返回的错误
+type stdoutSyncer struct {
+	*os.File
+}
+
+func (s stdoutSyncer) Sync() error {
+	_ = s.File.Sync()
+	return nil
+}
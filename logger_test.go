@@ -0,0 +1,37 @@
+package pplogger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoggerClose(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger, err := NewLogger(Config{FileWriter: true, LogPath: dir, Filename: "app.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("before close")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+	if b, _ := os.ReadFile(path); !strings.Contains(string(b), "before close") {
+		t.Fatalf("log file = %q", b)
+	}
+
+	// 关闭后的写入被丢弃，不会 panic 也不会重新创建文件
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after close")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("log file reopened after Close: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package pplogger
+
+import (
+	"reflect"
+)
+
+// ConfigOverride 作为 Merge 的参数。
+// 每个布尔字段都有对应的指针字段，用于区分"未设置"与"显式 false"，为 nil 时以内嵌 Config 中的 true 为准。
+// Config 新增布尔字段时需在此添加同名的指针字段
+type ConfigOverride struct {
+	Config
+	StdoutWriter        *bool
+	FileWriter          *bool
+	Compress            *bool
+	LocalTime           *bool
+	ReplaceGlobals      *bool
+	DisableCaller       *bool
+	DisableStacktrace   *bool
+	Development         *bool
+	IncludeHostInfo     *bool
+	K8s                 *bool
+	DiscardWriter       *bool
+	EscapeNewlines      *bool
+	EscapeStacktrace    *bool
+	VerboseErrors       *bool
+	SortFields          *bool
+	DropMatchLoggerName *bool
+	RotateOnSIGHUP      *bool
+	SignalLevelToggle   *bool
+	Banner              *bool
+	ReopenOnRename      *bool
+	SplitStdStreams     *bool
+	Journald            *bool
+}
+
+// Bool 返回 b 的指针，便于填写 ConfigOverride
+func Bool(b bool) *bool {
+	return &b
+}
+
+// Merge 以 config 为基础合并 override，override 中的非零值字段生效
+func (config Config) Merge(override ConfigOverride) Config {
+	merged := config
+	dst := reflect.ValueOf(&merged).Elem()
+	src := reflect.ValueOf(override.Config)
+	overrideValue := reflect.ValueOf(override)
+
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		value := src.Field(i)
+		if field.Type.Kind() == reflect.Bool {
+			if ptr := overrideValue.FieldByName(field.Name); ptr.Kind() == reflect.Pointer && !ptr.IsNil() {
+				dst.Field(i).SetBool(ptr.Elem().Bool())
+				continue
+			}
+		}
+
+		if !value.IsZero() {
+			dst.Field(i).Set(value)
+		}
+	}
+
+	return merged
+}
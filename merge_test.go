@@ -0,0 +1,148 @@
+package pplogger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testValue 返回类型 typ 的非零值，用于逐个字段测试 Merge
+func testValue(t *testing.T, typ reflect.Type) reflect.Value {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int64:
+		v.SetInt(7)
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(typ, 1, 1))
+	case reflect.Map:
+		v.Set(reflect.MakeMap(typ))
+		v.SetMapIndex(reflect.ValueOf("k"), reflect.Zero(typ.Elem()))
+	case reflect.Pointer:
+		v.Set(reflect.New(typ.Elem()))
+	case reflect.Func:
+		v.Set(reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+			out := make([]reflect.Value, typ.NumOut())
+			for i := range out {
+				out[i] = reflect.Zero(typ.Out(i))
+			}
+			return out
+		}))
+	case reflect.Interface:
+		if !reflect.TypeOf(&syncBuffer{}).Implements(typ) {
+			t.Fatalf("no test value for %s", typ)
+		}
+		v.Set(reflect.ValueOf(&syncBuffer{}))
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if typ.Field(i).IsExported() {
+				v.Field(i).Set(testValue(t, typ.Field(i).Type))
+				break
+			}
+		}
+	default:
+		t.Fatalf("no test value for %s", typ)
+	}
+
+	return v
+}
+
+// sameValue 比较 Merge 前后的字段，函数按地址比较
+func sameValue(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Func {
+		return a.Pointer() == b.Pointer()
+	}
+
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func TestMergeEachField(t *testing.T) {
+	typ := reflect.TypeOf(Config{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		t.Run(field.Name, func(t *testing.T) {
+			value := testValue(t, field.Type)
+
+			// override 中的非零值生效
+			var override ConfigOverride
+			reflect.ValueOf(&override.Config).Elem().Field(i).Set(value)
+			merged := Config{}.Merge(override)
+			if got := reflect.ValueOf(merged).Field(i); !sameValue(got, value) {
+				t.Errorf("override not applied: got %v", got)
+			}
+
+			// override 中的零值保留基础配置
+			var base Config
+			reflect.ValueOf(&base).Elem().Field(i).Set(value)
+			merged = base.Merge(ConfigOverride{})
+			if got := reflect.ValueOf(merged).Field(i); !sameValue(got, value) {
+				t.Errorf("base value lost: got %v", got)
+			}
+		})
+	}
+}
+
+func TestConfigOverrideCoversBools(t *testing.T) {
+	typ := reflect.TypeOf(Config{})
+	override := reflect.TypeOf(ConfigOverride{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() || field.Type.Kind() != reflect.Bool {
+			continue
+		}
+		ptr, ok := override.FieldByName(field.Name)
+		if !ok || len(ptr.Index) != 1 || ptr.Type != reflect.TypeOf((*bool)(nil)) {
+			t.Errorf("ConfigOverride has no *bool field %s", field.Name)
+		}
+	}
+}
+
+func TestMergeBool(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     bool
+		embedded bool
+		ptr      *bool
+		want     bool
+	}{
+		{"unset keeps false", false, false, nil, false},
+		{"unset keeps true", true, false, nil, true},
+		{"embedded true without pointer", false, true, nil, true},
+		{"explicit true", false, false, Bool(true), true},
+		{"explicit false", true, false, Bool(false), false},
+		{"pointer wins over embedded", false, true, Bool(false), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := Config{Compress: tt.base, DisableCaller: tt.base}
+			override := ConfigOverride{
+				Config:        Config{Compress: tt.embedded, DisableCaller: tt.embedded},
+				Compress:      tt.ptr,
+				DisableCaller: tt.ptr,
+			}
+			merged := base.Merge(override)
+			if merged.Compress != tt.want || merged.DisableCaller != tt.want {
+				t.Fatalf("Compress = %v, DisableCaller = %v, want %v", merged.Compress, merged.DisableCaller, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDefaultConfig(t *testing.T) {
+	merged := DefaultConfig().Merge(ConfigOverride{
+		Config:       Config{MaxSize: 100, Filename: "svc.log", CompressDelay: time.Second},
+		StdoutWriter: Bool(true),
+	})
+	if merged.MaxSize != 100 || merged.MaxBackups != 3 || merged.MaxAge != 30 || merged.LogPath != "./logs" {
+		t.Fatalf("rotation settings = %d/%d/%d %s", merged.MaxSize, merged.MaxBackups, merged.MaxAge, merged.LogPath)
+	}
+	if merged.Filename != "svc.log" || !merged.StdoutWriter {
+		t.Fatalf("merged = %+v", merged)
+	}
+}
@@ -0,0 +1,200 @@
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"sync"
+	"time"
+)
+
+// FileTarget 是 Config.AdditionalFiles 中的一个镜像文件，切割参数为 0 时与主文件相同
+type FileTarget struct {
+	LogPath    string // 日志文件路径
+	Filename   string // 日志文件名称
+	MaxSize    int    // 单个文件最大限制，单位 M
+	MaxBackups int    // 最多保留备份数
+	MaxAge     int    // 最多保留天数
+	Compress   bool   // 是否压缩
+}
+
+const (
+	mirrorBufferSize   = 1000
+	mirrorRetryOpen    = time.Second
+	mirrorFlushTimeout = 2 * time.Second
+)
+
+func (t FileTarget) validate() []error {
+	var errs []error
+
+	if t.Filename == "" {
+		errs = append(errs, fmt.Errorf("AdditionalFiles: missing Filename in %+v", t))
+	}
+
+	if t.MaxSize < 0 || t.MaxBackups < 0 || t.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("AdditionalFiles: negative rotation setting in %+v", t))
+	}
+
+	return errs
+}
+
+// config 返回 target 对应的文件配置，未设置的项沿用主文件
+func (t FileTarget) config(config Config) Config {
+	config.LogPath, config.Filename = t.LogPath, t.Filename
+	config.FilenamePattern = sharedFilenamePattern(config.FilenamePattern)
+	if t.MaxSize != 0 {
+		config.MaxSize = t.MaxSize
+	}
+	if t.MaxBackups != 0 {
+		config.MaxBackups = t.MaxBackups
+	}
+	if t.MaxAge != 0 {
+		config.MaxAge = t.MaxAge
+	}
+	if t.Compress {
+		config.Compress = true
+	}
+
+	return config
+}
+
+// newMirrorWriter 为镜像文件创建写入端，文件在后台打开，打开失败时每隔 mirrorRetryOpen 重试
+func newMirrorWriter(config Config, target FileTarget, stats *sinkStats) *mirrorWriter {
+	config = target.config(config)
+
+	return startMirrorWriter(func() (zapcore.WriteSyncer, error) {
+		logPath, err := resolveLogPath(config.LogPath)
+		if err != nil {
+			return nil, err
+		}
+		config.LogPath = logPath
+
+		return acquireFileWriter(config)
+	}, stats)
+}
+
+func startMirrorWriter(open func() (zapcore.WriteSyncer, error), stats *sinkStats) *mirrorWriter {
+	w := &mirrorWriter{
+		open:     open,
+		stats:    stats,
+		queue:    make(chan []byte, mirrorBufferSize),
+		rotation: make(chan chan error),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+
+	return w
+}
+
+// mirrorWriter 异步写入镜像文件，慢速或失败的镜像不会阻塞主文件，也不会向其返回错误：
+// 缓冲区满时丢弃并计入 Stats.Dropped，写入失败计入 Stats.WriteErrors
+type mirrorWriter struct {
+	open      func() (zapcore.WriteSyncer, error)
+	stats     *sinkStats
+	file      zapcore.WriteSyncer
+	lastOpen  time.Time
+	queue     chan []byte
+	rotation  chan chan error // Logger.Rotate 的请求，由 run 写完缓冲区中的日志后切割
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (w *mirrorWriter) Write(p []byte) (int, error) {
+	msg := append([]byte(nil), p...)
+	select {
+	case w.queue <- msg:
+	default:
+		w.stats.dropped.Add(1)
+	}
+
+	return len(p), nil
+}
+
+// forceRotate 等待之前写入的日志落盘后切割镜像文件，文件尚未打开时忽略
+func (w *mirrorWriter) forceRotate() error {
+	reply := make(chan error, 1)
+	select {
+	case w.rotation <- reply:
+		return <-reply
+	case <-w.done:
+		return nil
+	}
+}
+
+func (w *mirrorWriter) Sync() error {
+	return nil
+}
+
+// Close 最多等待 mirrorFlushTimeout 写入缓冲区中剩余的日志，之后关闭文件
+func (w *mirrorWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.quit)
+		select {
+		case <-w.done:
+		case <-time.After(mirrorFlushTimeout):
+		}
+	})
+
+	return nil
+}
+
+func (w *mirrorWriter) run() {
+	defer close(w.done)
+	defer func() {
+		if closer, ok := w.file.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}()
+
+	for {
+		select {
+		case msg := <-w.queue:
+			w.write(msg)
+		case reply := <-w.rotation:
+			w.drain()
+			var err error
+			if r, ok := w.file.(rotator); ok {
+				err = r.forceRotate()
+			}
+			reply <- err
+		case <-w.quit:
+			w.drain()
+			return
+		}
+	}
+}
+
+// drain 写入缓冲区中剩余的日志
+func (w *mirrorWriter) drain() {
+	for {
+		select {
+		case msg := <-w.queue:
+			w.write(msg)
+		default:
+			return
+		}
+	}
+}
+
+func (w *mirrorWriter) write(msg []byte) {
+	if w.file == nil {
+		if time.Since(w.lastOpen) < mirrorRetryOpen {
+			w.stats.writeErrors.Add(1)
+			return
+		}
+		w.lastOpen = time.Now()
+
+		file, err := w.open()
+		if err != nil {
+			w.stats.writeErrors.Add(1)
+			return
+		}
+		w.file = file
+	}
+
+	if _, err := w.file.Write(msg); err != nil {
+		w.stats.writeErrors.Add(1)
+	}
+}
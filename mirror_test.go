@@ -0,0 +1,146 @@
+package pplogger
+
+import (
+	"errors"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failingSyncer 模拟不可用的镜像目标，每次写入都返回错误
+type failingSyncer struct{}
+
+func (failingSyncer) Write(p []byte) (int, error) {
+	return 0, errors.New("nfs down")
+}
+
+func (failingSyncer) Sync() error {
+	return nil
+}
+
+// blockingSyncer 模拟慢速的镜像目标，release 关闭前写入一直阻塞
+type blockingSyncer struct {
+	release chan struct{}
+}
+
+func (s blockingSyncer) Write(p []byte) (int, error) {
+	<-s.release
+	return len(p), nil
+}
+
+func (s blockingSyncer) Sync() error {
+	return nil
+}
+
+func TestAdditionalFiles(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(Config{
+		FileWriter:      true,
+		LogPath:         dir,
+		Filename:        "app.log",
+		AdditionalFiles: []FileTarget{{LogPath: filepath.Join(dir, "mirror"), Filename: "copy.log", MaxSize: 5}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"app.log", filepath.Join("mirror", "copy.log")} {
+		b, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), "hello") {
+			t.Errorf("%s = %q", file, b)
+		}
+	}
+}
+
+func TestFileTargetConfig(t *testing.T) {
+	primary := Config{LogPath: "/logs", Filename: "app.log", MaxSize: 100, MaxBackups: 7, MaxAge: 30}
+	got := FileTarget{LogPath: "/nfs", Filename: "copy.log", MaxSize: 5, Compress: true}.config(primary)
+	if got.LogPath != "/nfs" || got.Filename != "copy.log" || got.MaxSize != 5 || got.MaxBackups != 7 || got.MaxAge != 30 || !got.Compress {
+		t.Errorf("unexpected config %+v", got)
+	}
+}
+
+func TestMirrorWriterFailing(t *testing.T) {
+	var stats sinkStats
+	mirror := startMirrorWriter(func() (zapcore.WriteSyncer, error) { return failingSyncer{}, nil }, &stats)
+	var primary syncBuffer
+	ws := zapcore.NewMultiWriteSyncer(&primary, mirror)
+
+	// 镜像失败不影响主输出，也不返回错误
+	if _, err := ws.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.Write([]byte("second\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mirror.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if primary.String() != "first\nsecond\n" {
+		t.Errorf("primary = %q", primary.String())
+	}
+	if n := stats.writeErrors.Load(); n != 2 {
+		t.Errorf("writeErrors = %d, want 2", n)
+	}
+}
+
+func TestMirrorWriterOpenFails(t *testing.T) {
+	var stats sinkStats
+	mirror := startMirrorWriter(func() (zapcore.WriteSyncer, error) { return nil, errors.New("mount missing") }, &stats)
+	_, _ = mirror.Write([]byte("first\n"))
+	_, _ = mirror.Write([]byte("second\n"))
+	if err := mirror.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 打开失败后 mirrorRetryOpen 内不再重试，两条都计为写入失败
+	if n := stats.writeErrors.Load(); n != 2 {
+		t.Errorf("writeErrors = %d, want 2", n)
+	}
+}
+
+func TestMirrorWriterSlow(t *testing.T) {
+	var stats sinkStats
+	release := make(chan struct{})
+	mirror := startMirrorWriter(func() (zapcore.WriteSyncer, error) { return blockingSyncer{release}, nil }, &stats)
+	var primary syncBuffer
+	ws := zapcore.NewMultiWriteSyncer(&primary, mirror)
+
+	// 镜像阻塞时主输出照常写入，缓冲区满后镜像丢弃日志
+	total := mirrorBufferSize + 10
+	for i := 0; i < total; i++ {
+		if _, err := ws.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(primary.lines()); n != total {
+		t.Errorf("primary got %d lines, want %d", n, total)
+	}
+	if stats.dropped.Load() == 0 {
+		t.Error("slow mirror did not drop entries")
+	}
+	close(release)
+	if err := mirror.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdditionalFilesInvalid(t *testing.T) {
+	for _, target := range []FileTarget{
+		{},
+		{Filename: "copy.log", MaxSize: -1},
+	} {
+		if err := (Config{StdoutWriter: true, AdditionalFiles: []FileTarget{target}}).Validate(); err == nil {
+			t.Errorf("Validate accepted %+v", target)
+		}
+	}
+}
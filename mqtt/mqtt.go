@@ -0,0 +1,302 @@
+// Package mqtt 将日志发布到 MQTT topic，适用于没有日志采集的边缘设备，通过 pplogger.Config.Sinks 接入：
+//
+//	w, err := mqtt.NewWriter(mqtt.Config{Brokers: []string{"tcp://127.0.0.1:1883"}, TopicTemplate: "logs/{deviceID}/{level}"})
+//	config.Sinks = append(config.Sinks, w)
+//
+// 日志需使用 json 编码（Config.SinkEncoding 的默认值）
+package mqtt
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTopic          = "logs/{deviceID}/{level}"
+	defaultMaxBuffered    = 1000
+	defaultMaxBufferBytes = 4 << 20
+	defaultCloseTimeout   = 5 * time.Second
+	publishTimeout        = 5 * time.Second
+	maxReconnectInterval  = 30 * time.Second
+)
+
+// Config 是 MQTT 输出的配置
+type Config struct {
+	Brokers   []string // 服务器地址，例如 tcp://127.0.0.1:1883、ssl://broker:8883，默认 tcp://127.0.0.1:1883
+	ClientID  string   // 默认 pplogger-<DeviceID>-<pid>
+	Username  string   // 用户名密码认证
+	Password  string
+	TLSConfig *tls.Config // ssl:// 时使用的 TLS 配置
+
+	// topic 模板，{deviceID} 替换为 DeviceID，其余 {name} 替换为 json 日志中的同名字段，例如 {level}、{logger}，
+	// 默认 logs/{deviceID}/{level}。字段缺失时替换为 unknown，值中的 /、+、# 替换为 _
+	TopicTemplate string
+	DeviceID      string // 默认主机名
+
+	QoS byte // 0（默认）、1 或 2，retained 固定为 false
+
+	// 断线期间缓冲的日志条数及字节数上限，默认 1000 条、4M，包括已发布但未确认的日志，超过时丢弃。
+	// QoS 为 1、2 时断线期间的日志保存在 Store 中，重连后补发；QoS 为 0 时断线期间的日志直接丢弃
+	MaxBuffered    int
+	MaxBufferBytes int
+	Store          paho.Store // 客户端的消息存储，默认内存存储，可用 paho.NewFileStore 在重启后继续补发
+
+	CloseTimeout time.Duration // Close 等待发送及确认的最长时间，默认 5s
+}
+
+// Stats 是 Writer 的运行统计
+type Stats struct {
+	Published  uint64 // 发布成功（QoS 为 1、2 时为已确认）的日志条数
+	Dropped    uint64 // 因缓冲区已满、断线或发布失败而丢弃的日志条数
+	Reconnects uint64 // 重新连接的次数
+}
+
+var placeholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// Writer 实现 pplogger.Sink，写入只放入缓冲区，由后台 goroutine 发布，断线时不会阻塞
+type Writer struct {
+	config Config
+	client paho.Client
+	fields bool // topic 是否需要日志字段
+
+	queue    chan []byte
+	inflight chan *publishToken
+	buffered atomic.Int64 // 缓冲区及未确认的日志条数
+	bytes    atomic.Int64 // 缓冲区及未确认的日志字节数
+
+	published  atomic.Uint64
+	dropped    atomic.Uint64
+	reconnects atomic.Uint64
+	connected  atomic.Bool
+
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWriter 创建 Writer 并在后台连接服务器，服务器暂时不可用时持续重试，
+// 使用完毕后应调用 Close，交给 Logger 时由 Logger 关闭
+func NewWriter(config Config) (*Writer, error) {
+	if config.QoS > 2 {
+		return nil, fmt.Errorf("pplogger/mqtt: invalid QoS %d", config.QoS)
+	}
+	if config.MaxBuffered < 0 || config.MaxBufferBytes < 0 || config.CloseTimeout < 0 {
+		return nil, errors.New("pplogger/mqtt: negative MaxBuffered, MaxBufferBytes or CloseTimeout")
+	}
+	if len(config.Brokers) == 0 {
+		config.Brokers = []string{"tcp://127.0.0.1:1883"}
+	}
+	if config.TopicTemplate == "" {
+		config.TopicTemplate = defaultTopic
+	}
+	if config.DeviceID == "" {
+		config.DeviceID, _ = os.Hostname()
+	}
+	if config.ClientID == "" {
+		config.ClientID = fmt.Sprintf("pplogger-%s-%d", config.DeviceID, os.Getpid())
+	}
+	if config.MaxBuffered == 0 {
+		config.MaxBuffered = defaultMaxBuffered
+	}
+	if config.MaxBufferBytes == 0 {
+		config.MaxBufferBytes = defaultMaxBufferBytes
+	}
+	if config.CloseTimeout == 0 {
+		config.CloseTimeout = defaultCloseTimeout
+	}
+
+	config.TopicTemplate = strings.ReplaceAll(config.TopicTemplate, "{deviceID}", topicLevel(config.DeviceID))
+	if strings.ContainsAny(config.TopicTemplate, "+#") {
+		return nil, fmt.Errorf("pplogger/mqtt: wildcard in TopicTemplate %q", config.TopicTemplate)
+	}
+
+	w := &Writer{
+		config:   config,
+		fields:   placeholder.MatchString(config.TopicTemplate),
+		queue:    make(chan []byte, config.MaxBuffered),
+		inflight: make(chan *publishToken, config.MaxBuffered),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	opts := paho.NewClientOptions().
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		// CleanSession 时首次连接会清空 Store，连接成功前发布的日志不会补发
+		SetCleanSession(false).
+		SetMaxReconnectInterval(maxReconnectInterval).
+		SetWriteTimeout(publishTimeout).
+		SetOrderMatters(false).
+		SetOnConnectHandler(func(paho.Client) {
+			if w.connected.Swap(true) {
+				w.reconnects.Add(1)
+			}
+		})
+	for _, broker := range config.Brokers {
+		opts.AddBroker(broker)
+	}
+	if config.TLSConfig != nil {
+		opts.SetTLSConfig(config.TLSConfig)
+	}
+	if config.Store != nil {
+		opts.SetStore(config.Store)
+	}
+
+	w.client = paho.NewClient(opts)
+	// ConnectRetry 时 Connect 在后台重试，连接成功前发布的日志先保存在 Store 中
+	w.client.Connect()
+
+	go w.run()
+	go w.wait()
+
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	payload := append([]byte(nil), bytes.TrimRight(p, "\r\n")...)
+
+	buffered := w.buffered.Add(1)
+	size := w.bytes.Add(int64(len(payload)))
+	if buffered > int64(w.config.MaxBuffered) || size > int64(w.config.MaxBufferBytes) {
+		w.release(len(payload))
+		w.dropped.Add(1)
+		return len(p), nil
+	}
+
+	select {
+	case w.queue <- payload:
+	default:
+		w.release(len(payload))
+		w.dropped.Add(1)
+	}
+
+	return len(p), nil
+}
+
+// Sync 不等待发布，日志由后台 goroutine 尽快发出
+func (w *Writer) Sync() error {
+	return nil
+}
+
+// Close 最多等待 CloseTimeout 发布缓冲区中的日志及等待确认，之后断开连接
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.quit)
+		select {
+		case <-w.done:
+		case <-time.After(w.config.CloseTimeout):
+		}
+		w.client.Disconnect(0)
+	})
+
+	return nil
+}
+
+// Stats 返回 Writer 的运行统计
+func (w *Writer) Stats() Stats {
+	return Stats{
+		Published:  w.published.Load(),
+		Dropped:    w.dropped.Load(),
+		Reconnects: w.reconnects.Load(),
+	}
+}
+
+// release 释放一条日志占用的缓冲额度
+func (w *Writer) release(size int) {
+	w.buffered.Add(-1)
+	w.bytes.Add(-int64(size))
+}
+
+// run 发布缓冲区中的日志，关闭时发布剩余的日志后通知 wait 退出
+func (w *Writer) run() {
+	defer close(w.inflight)
+
+	for {
+		select {
+		case msg := <-w.queue:
+			w.publish(msg)
+		case <-w.quit:
+			for {
+				select {
+				case msg := <-w.queue:
+					w.publish(msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *Writer) publish(payload []byte) {
+	if w.config.QoS == 0 && !w.client.IsConnectionOpen() {
+		// QoS 0 的消息在断线期间不会保存
+		w.release(len(payload))
+		w.dropped.Add(1)
+		return
+	}
+
+	token := w.client.Publish(w.topicOf(payload), w.config.QoS, false, payload)
+	w.inflight <- &publishToken{Token: token, size: len(payload)}
+}
+
+// publishToken 记录发布的日志大小，完成后释放缓冲额度
+type publishToken struct {
+	paho.Token
+	size int
+}
+
+// wait 按发布顺序等待完成，QoS 为 1、2 时断线期间的日志在重连补发并确认后才完成
+func (w *Writer) wait() {
+	defer close(w.done)
+
+	for token := range w.inflight {
+		<-token.Done()
+		if token.Error() != nil {
+			w.dropped.Add(1)
+		} else {
+			w.published.Add(1)
+		}
+		w.release(token.size)
+	}
+}
+
+// topicOf 按 TopicTemplate 生成 topic
+func (w *Writer) topicOf(payload []byte) string {
+	if !w.fields {
+		return w.config.TopicTemplate
+	}
+
+	var fields map[string]interface{}
+	_ = json.Unmarshal(payload, &fields)
+
+	return placeholder.ReplaceAllStringFunc(w.config.TopicTemplate, func(s string) string {
+		value, ok := fields[s[1:len(s)-1]]
+		if !ok || value == nil {
+			return "unknown"
+		}
+		return topicLevel(fmt.Sprint(value))
+	})
+}
+
+// topicLevel 替换 topic 中有特殊含义的字符
+func topicLevel(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(s)
+}
@@ -0,0 +1,237 @@
+// Package nats 将日志发布到 NATS subject，可选通过 JetStream 等待确认，通过 pplogger.Config.Sinks 接入：
+//
+//	w, err := nats.NewWriter(nats.Config{URLs: []string{"nats://127.0.0.1:4222"}, SubjectTemplate: "logs.app.{{.level}}"})
+//	config.Sinks = append(config.Sinks, w)
+//
+// 日志需使用 json 编码（Config.SinkEncoding 的默认值）
+package nats
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultSubject     = "logs"
+	defaultMaxPending  = 4000
+	defaultSyncTimeout = 5 * time.Second
+	publishStallWait   = time.Millisecond
+)
+
+// Config 是 NATS 输出的配置
+type Config struct {
+	URLs []string // 服务器地址，默认 nats://127.0.0.1:4222
+
+	// subject 的 text/template 模板，以 json 编码的日志字段为数据，例如 logs.app.{{.level}}，
+	// 默认 logs。字段缺失或执行失败时使用模板中第一个 {{ 之前的部分去掉末尾的 .
+	SubjectTemplate string
+
+	CredsFile    string // .creds 文件
+	NKeySeedFile string // nkey 种子文件
+	User         string // 用户名密码认证
+	Password     string
+	Token        string      // token 认证
+	TLSConfig    *tls.Config // 不为空时使用 TLS 连接
+
+	JetStream   bool          // 是否通过 JetStream 发布并等待确认，subject 需属于某个 stream
+	MaxPending  int           // JetStream 最多未确认的消息数，默认 4000，超过时丢弃
+	SyncTimeout time.Duration // Sync 及 Close 等待发送或确认的最长时间，默认 5s
+}
+
+// Stats 是 Writer 的运行统计
+type Stats struct {
+	Published  uint64 // 发布（JetStream 时为已确认）的日志条数
+	Dropped    uint64 // 因断线缓冲区已满、未确认消息过多或确认失败而丢弃的日志条数
+	Reconnects uint64 // 重新连接的次数
+}
+
+// Writer 实现 pplogger.Sink，发布是异步的，断线期间由 NATS 客户端缓冲并自动重连
+type Writer struct {
+	config   Config
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	subject  *template.Template
+	fallback string // 模板无法执行时使用的 subject
+
+	published  atomic.Uint64
+	dropped    atomic.Uint64
+	reconnects atomic.Uint64
+	closeOnce  sync.Once
+}
+
+// NewWriter 连接 NATS 并创建 Writer，服务器暂时不可用时在后台重试，
+// 使用完毕后应调用 Close，交给 Logger 时由 Logger 关闭
+func NewWriter(config Config) (*Writer, error) {
+	if config.MaxPending < 0 || config.SyncTimeout < 0 {
+		return nil, errors.New("pplogger/nats: negative MaxPending or SyncTimeout")
+	}
+	if config.SubjectTemplate == "" {
+		config.SubjectTemplate = defaultSubject
+	}
+	if config.MaxPending == 0 {
+		config.MaxPending = defaultMaxPending
+	}
+	if config.SyncTimeout == 0 {
+		config.SyncTimeout = defaultSyncTimeout
+	}
+
+	w := &Writer{config: config}
+	if strings.Contains(config.SubjectTemplate, "{{") {
+		t, err := template.New("subject").Option("missingkey=error").Parse(config.SubjectTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("pplogger/nats: SubjectTemplate: %w", err)
+		}
+		w.subject = t
+		w.fallback, _, _ = strings.Cut(config.SubjectTemplate, "{{")
+		w.fallback = strings.TrimRight(w.fallback, ".")
+		if w.fallback == "" {
+			w.fallback = defaultSubject
+		}
+	} else {
+		w.fallback = config.SubjectTemplate
+	}
+
+	opts := []nats.Option{
+		nats.Name("pplogger"),
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+		nats.ReconnectHandler(func(*nats.Conn) {
+			w.reconnects.Add(1)
+		}),
+	}
+	if config.CredsFile != "" {
+		opts = append(opts, nats.UserCredentials(config.CredsFile))
+	}
+	if config.NKeySeedFile != "" {
+		opt, err := nats.NkeyOptionFromSeed(config.NKeySeedFile)
+		if err != nil {
+			return nil, fmt.Errorf("pplogger/nats: %w", err)
+		}
+		opts = append(opts, opt)
+	}
+	if config.User != "" {
+		opts = append(opts, nats.UserInfo(config.User, config.Password))
+	}
+	if config.Token != "" {
+		opts = append(opts, nats.Token(config.Token))
+	}
+	if config.TLSConfig != nil {
+		opts = append(opts, nats.Secure(config.TLSConfig))
+	}
+
+	urls := strings.Join(config.URLs, ",")
+	if urls == "" {
+		urls = nats.DefaultURL
+	}
+	conn, err := nats.Connect(urls, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("pplogger/nats: %w", err)
+	}
+	w.conn = conn
+
+	if config.JetStream {
+		js, err := jetstream.New(conn,
+			jetstream.WithPublishAsyncMaxPending(config.MaxPending),
+			jetstream.WithPublishAsyncErrHandler(func(jetstream.JetStream, *nats.Msg, error) {
+				w.dropped.Add(1)
+			}),
+			jetstream.WithPublishAsyncAckHandler(func(jetstream.JetStream, *nats.Msg, *jetstream.PubAck) {
+				w.published.Add(1)
+			}),
+		)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("pplogger/nats: %w", err)
+		}
+		w.js = js
+	}
+
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	msg := append([]byte(nil), bytes.TrimRight(p, "\r\n")...)
+	subject := w.subjectOf(msg)
+
+	if w.js != nil {
+		if w.js.PublishAsyncPending() >= w.config.MaxPending {
+			w.dropped.Add(1)
+			return len(p), nil
+		}
+		if _, err := w.js.PublishAsync(subject, msg, jetstream.WithStallWait(publishStallWait)); err != nil {
+			w.dropped.Add(1)
+		}
+		return len(p), nil
+	}
+
+	if err := w.conn.Publish(subject, msg); err != nil {
+		w.dropped.Add(1)
+		return len(p), nil
+	}
+	w.published.Add(1)
+
+	return len(p), nil
+}
+
+// Sync 最多等待 SyncTimeout，直到已发布的日志发送到服务器，JetStream 时等待全部确认
+func (w *Writer) Sync() error {
+	if w.js != nil {
+		select {
+		case <-w.js.PublishAsyncComplete():
+		case <-time.After(w.config.SyncTimeout):
+		}
+		return nil
+	}
+
+	// 断线时 Flush 会失败，日志仍在客户端的重连缓冲区中
+	_ = w.conn.FlushTimeout(w.config.SyncTimeout)
+	return nil
+}
+
+// Close 等待发送或确认后关闭连接
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() {
+		_ = w.Sync()
+		w.conn.Close()
+	})
+
+	return nil
+}
+
+// Stats 返回 Writer 的运行统计
+func (w *Writer) Stats() Stats {
+	return Stats{
+		Published:  w.published.Load(),
+		Dropped:    w.dropped.Load(),
+		Reconnects: w.reconnects.Load(),
+	}
+}
+
+// subjectOf 按 SubjectTemplate 生成 subject
+func (w *Writer) subjectOf(msg []byte) string {
+	if w.subject == nil {
+		return w.fallback
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(msg, &fields); err != nil {
+		return w.fallback
+	}
+
+	var subject strings.Builder
+	if err := w.subject.Execute(&subject, fields); err != nil || subject.Len() == 0 {
+		return w.fallback
+	}
+
+	return subject.String()
+}
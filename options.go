@@ -0,0 +1,259 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+)
+
+// Option 用于 New 的函数式配置，多个 Option 按顺序生效，后者覆盖前者
+type Option func(*Config) error
+
+// New 根据 Option 创建日志，未指定任何输出时默认输出到控制台。
+// 返回的日志无法关闭，需要关闭日志文件或停止后台任务时使用 NewLogger
+func New(opts ...Option) (*zap.Logger, error) {
+	logger, err := newLogger(append(opts[:len(opts):len(opts)], withDefaultStdout(), withPermanentGlobals()))
+	if err != nil {
+		return nil, err
+	}
+
+	return logger.Logger, nil
+}
+
+// newConfig 从零值开始按顺序应用 opts
+func newConfig(opts []Option) (Config, error) {
+	var config Config
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return Config{}, err
+		}
+	}
+
+	return config, nil
+}
+
+// newLogger 应用 opts 后创建 Logger。以 Config 为参数的构造函数都通过 WithConfig 转换为 Option 后调用
+func newLogger(opts []Option, zapOpts ...zap.Option) (*Logger, error) {
+	config, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return build(config, zapOpts...)
+}
+
+// withDefaultStdout 在未指定任何输出时输出到控制台，用于 New 及 Builder
+func withDefaultStdout() Option {
+	return func(c *Config) error {
+		if !c.hasSink() {
+			c.StdoutWriter = true
+		}
+		return nil
+	}
+}
+
+// withPermanentGlobals 用于只返回 *zap.Logger 的构造函数：调用方拿不到 Logger，ReplaceGlobals 安装的全局日志无法恢复
+func withPermanentGlobals() Option {
+	return func(c *Config) error {
+		c.permanentGlobals = true
+		return nil
+	}
+}
+
+// WithConfig 以完整的 Config 作为基础配置
+func WithConfig(config Config) Option {
+	return func(c *Config) error {
+		*c = config
+		return nil
+	}
+}
+
+// WithFile 输出到 path 目录下的 filename 文件
+func WithFile(path, filename string) Option {
+	return func(c *Config) error {
+		if filename == "" {
+			return errors.New("pplogger: WithFile requires a filename")
+		}
+		c.FileWriter = true
+		c.LogPath = path
+		c.Filename = filename
+		return nil
+	}
+}
+
+// WithStdout 输出到控制台
+func WithStdout() Option {
+	return func(c *Config) error {
+		c.StdoutWriter = true
+		return nil
+	}
+}
+
+// WithoutStdout 不输出到控制台，可用于关闭 NewPPLoggerLite 默认的控制台输出
+func WithoutStdout() Option {
+	return func(c *Config) error {
+		c.StdoutWriter = false
+		return nil
+	}
+}
+
+// WithDiscard 丢弃全部输出，用于基准测试
+func WithDiscard() Option {
+	return func(c *Config) error {
+		c.DiscardWriter = true
+		return nil
+	}
+}
+
+// WithWriter 追加额外的输出
+func WithWriter(w io.Writer) Option {
+	return func(c *Config) error {
+		if w == nil {
+			return errors.New("pplogger: WithWriter requires a non-nil writer")
+		}
+		c.ExtraWriters = append(c.ExtraWriters, w)
+		return nil
+	}
+}
+
+// WithLevel 设置日志输出等级
+func WithLevel(level string) Option {
+	return func(c *Config) error {
+		if _, err := ParseLevel(level); err != nil {
+			return fmt.Errorf("pplogger: %w", err)
+		}
+		c.LogLevel = level
+		return nil
+	}
+}
+
+// WithRotation 设置日志文件的切割参数
+func WithRotation(maxSize, maxBackups, maxAge int, compress bool) Option {
+	return func(c *Config) error {
+		if maxSize < 0 || maxBackups < 0 || maxAge < 0 {
+			return fmt.Errorf("pplogger: negative rotation setting (%d, %d, %d)", maxSize, maxBackups, maxAge)
+		}
+		c.MaxSize = maxSize
+		c.MaxBackups = maxBackups
+		c.MaxAge = maxAge
+		c.Compress = compress
+		return nil
+	}
+}
+
+// WithLocalTime 使备份文件名中的时间使用本地时间
+func WithLocalTime() Option {
+	return func(c *Config) error {
+		c.LocalTime = true
+		return nil
+	}
+}
+
+// WithReopenOnRename 在日志文件被外部删除或重命名后重新打开，见 Config.ReopenOnRename
+func WithReopenOnRename() Option {
+	return func(c *Config) error {
+		c.ReopenOnRename = true
+		return nil
+	}
+}
+
+// WithBanner 在创建 Logger 及每次切割后输出 Banner，见 Config.Banner
+func WithBanner(appName, appVersion string) Option {
+	return func(c *Config) error {
+		c.Banner = true
+		c.AppName, c.AppVersion = appName, appVersion
+		return nil
+	}
+}
+
+// WithSignalLevelToggle 通过 SIGUSR1、SIGUSR2 切换 Debug 等级，见 Config.SignalLevelToggle
+func WithSignalLevelToggle() Option {
+	return func(c *Config) error {
+		c.SignalLevelToggle = true
+		return nil
+	}
+}
+
+// WithLevelFile 从 path 读取并监视全局日志等级，见 Config.LevelFile
+func WithLevelFile(path string) Option {
+	return func(c *Config) error {
+		c.LevelFile = path
+		return nil
+	}
+}
+
+// WithLevelFromEnv 从环境变量 name 读取日志等级并覆盖 LogLevel，见 Config.LevelFromEnv
+func WithLevelFromEnv(name string) Option {
+	return func(c *Config) error {
+		c.LevelFromEnv = name
+		return nil
+	}
+}
+
+// WithRotateOnSIGHUP 在收到 SIGHUP 时切割日志文件，见 Config.RotateOnSIGHUP
+func WithRotateOnSIGHUP() Option {
+	return func(c *Config) error {
+		c.RotateOnSIGHUP = true
+		return nil
+	}
+}
+
+// WithOnRotate 设置日志文件切割后的回调，见 Config.OnRotate
+func WithOnRotate(onRotate func(path string) error) Option {
+	return func(c *Config) error {
+		c.OnRotate = onRotate
+		return nil
+	}
+}
+
+// WithJSON 使用 json 编码输出
+func WithJSON() Option {
+	return func(c *Config) error {
+		c.Encoding = JSONEncoding
+		return nil
+	}
+}
+
+// WithEncoderConfig 设置 Config.EncoderConfigOverride，在创建 Encoder 前修改 EncoderConfig
+func WithEncoderConfig(override func(*zapcore.EncoderConfig)) Option {
+	return func(c *Config) error {
+		c.EncoderConfigOverride = override
+		return nil
+	}
+}
+
+// WithCallerSkip 设置调用者跳过的层数，同样适用于 NewPPLoggerLite
+func WithCallerSkip(n int) Option {
+	return func(c *Config) error {
+		if n < 0 {
+			return fmt.Errorf("pplogger: negative caller skip %d", n)
+		}
+		c.CallerSkip = n
+		return nil
+	}
+}
+
+// WithReplaceGlobals 将创建的日志安装为 zap 的全局日志，见 Config.ReplaceGlobals
+func WithReplaceGlobals() Option {
+	return func(c *Config) error {
+		c.ReplaceGlobals = true
+		return nil
+	}
+}
+
+// WithInitialFields 为每条日志附加固定字段，多次调用时合并
+func WithInitialFields(fields map[string]interface{}) Option {
+	return func(c *Config) error {
+		merged := make(map[string]interface{}, len(c.InitialFields)+len(fields))
+		for key, value := range c.InitialFields {
+			merged[key] = value
+		}
+		for key, value := range fields {
+			merged[key] = value
+		}
+		c.InitialFields = merged
+		return nil
+	}
+}
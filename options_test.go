@@ -0,0 +1,80 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"reflect"
+	"testing"
+)
+
+func TestNewDefaults(t *testing.T) {
+	config, err := newConfig([]Option{withDefaultStdout()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Config{StdoutWriter: true}); !reflect.DeepEqual(config, want) {
+		t.Fatalf("config = %+v, want %+v", config, want)
+	}
+
+	logger, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger.Core().Enabled(zapcore.DebugLevel) || !logger.Core().Enabled(zapcore.InfoLevel) {
+		t.Fatal("default level is not Info")
+	}
+}
+
+func TestNewConflictingOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		check func(Config) bool
+	}{
+		{"last level wins", []Option{WithLevel("debug"), WithLevel("error")}, func(c Config) bool { return c.LogLevel == "error" }},
+		{"last stdout wins", []Option{WithStdout(), WithoutStdout()}, func(c Config) bool { return !c.StdoutWriter }},
+		{"last rotation wins", []Option{WithRotation(10, 1, 1, true), WithRotation(20, 2, 2, false)}, func(c Config) bool {
+			return c.MaxSize == 20 && c.MaxBackups == 2 && c.MaxAge == 2 && !c.Compress
+		}},
+		{"options after WithConfig override it", []Option{WithLevel("debug"), WithConfig(Config{LogLevel: "warn"}), WithJSON()}, func(c Config) bool {
+			return c.LogLevel == "warn" && c.Encoding == JSONEncoding
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := newConfig(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(config) {
+				t.Fatalf("config = %+v", config)
+			}
+		})
+	}
+
+	logger, err := New(WithLevel("debug"), WithLevel("error"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger.Core().Enabled(zapcore.WarnLevel) || !logger.Core().Enabled(zapcore.ErrorLevel) {
+		t.Fatal("the last WithLevel did not take effect")
+	}
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{"unknown level", WithLevel("loud")},
+		{"negative rotation", WithRotation(-1, 0, 0, false)},
+		{"negative caller skip", WithCallerSkip(-1)},
+		{"file without name", WithFile(t.TempDir(), "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opt); err == nil {
+				t.Fatal("want error")
+			}
+		})
+	}
+}
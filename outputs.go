@@ -0,0 +1,200 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config.Outputs 支持的 URL scheme，未带 scheme 的视为文件路径
+const (
+	OutputSchemeFile       = "file"
+	OutputSchemeLumberjack = "lumberjack"
+	OutputSchemeUDP        = "udp"
+)
+
+var outputSchemes = []string{"stdout", "stderr", OutputSchemeFile, OutputSchemeLumberjack, OutputSchemeUDP}
+
+func init() {
+	// 其他包已注册同名 scheme 时沿用其实现
+	_ = zap.RegisterSink(OutputSchemeLumberjack, newLumberjackSink)
+	_ = zap.RegisterSink(OutputSchemeUDP, newUDPSink)
+}
+
+// validOutput 检查 Config.Outputs 中的一项，未知 scheme 时返回的错误中列出支持的 scheme
+func validOutput(output string) error {
+	if output == "stdout" || output == "stderr" || filepath.IsAbs(output) {
+		return nil
+	}
+
+	u, err := url.Parse(output)
+	if err != nil {
+		return fmt.Errorf("invalid Outputs %q: %w", output, err)
+	}
+
+	switch u.Scheme {
+	case "", OutputSchemeFile:
+	case OutputSchemeLumberjack:
+		if _, err := lumberjackConfig(u); err != nil {
+			return fmt.Errorf("invalid Outputs %q: %w", output, err)
+		}
+	case OutputSchemeUDP:
+		if u.Hostname() == "" || u.Port() == "" {
+			return fmt.Errorf("invalid Outputs %q: want udp://host:port", output)
+		}
+	default:
+		return fmt.Errorf("unknown scheme %q in Outputs %q, supported: %s", u.Scheme, output, strings.Join(outputSchemes, ", "))
+	}
+
+	return nil
+}
+
+// outputFileWriter 是 FileWriter 转换成的输出，按 Config 中 LogPath、Filename、FilenamePattern 等文件设置打开。
+// 不是合法的 Outputs 项，不会与用户配置冲突
+const outputFileWriter = "pplogger:filewriter"
+
+// output 是一个控制台或文件输出。StdoutWriter、FileWriter 在创建日志时转换为 output，与 Outputs 中的各项走同一流程，
+// 并保留各自的编码、颜色及文件设置
+type output struct {
+	target   string               // "stdout"、"stderr"、文件路径、URL 或 outputFileWriter
+	encoding string               // 为空时使用 Encoding
+	color    bool                 // 等级是否带颜色
+	levels   zapcore.LevelEnabler // 只输出这些等级，为 nil 时不过滤
+}
+
+// outputs 将 FileWriter、StdoutWriter 及 Outputs 转换为输出列表。
+// FileWriter 排除 LevelOutputs 已分流的等级；SplitStdStreams 时 StdoutWriter 按 StderrMinLevel 拆为 stdout、stderr 两项；
+// K8s 模式的控制台输出另行创建
+func (config Config) outputs(routes []levelRoute) []output {
+	var outputs []output
+	if config.FileWriter {
+		file := output{target: outputFileWriter, encoding: config.FileEncoding}
+		if len(routes) > 0 {
+			file.levels = unroutedLevels(routes)
+		}
+		outputs = append(outputs, file)
+	}
+
+	if config.StdoutWriter && !config.K8s {
+		color := useColor(config)
+		if config.SplitStdStreams {
+			minLevel := zapcore.WarnLevel
+			if config.StderrMinLevel != "" {
+				minLevel, _ = ParseLevel(config.StderrMinLevel)
+			}
+			belowMin := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+				return level < minLevel
+			})
+			outputs = append(outputs,
+				output{target: "stdout", encoding: config.StdoutEncoding, color: color, levels: belowMin},
+				output{target: "stderr", encoding: config.StdoutEncoding, color: color, levels: minLevel})
+		} else {
+			outputs = append(outputs, output{target: "stdout", encoding: config.StdoutEncoding, color: color})
+		}
+	}
+
+	for _, target := range config.Outputs {
+		outputs = append(outputs, output{target: target})
+	}
+
+	return outputs
+}
+
+// outputCloser 是 zap.Open 返回的关闭函数
+type outputCloser func()
+
+func (c outputCloser) Close() error {
+	c()
+	return nil
+}
+
+// lumberjackConfig 从 lumberjack:///var/log/app.log?maxsize=100&backups=5&maxage=7&compress=true&localtime=true 解析文件及切割参数，
+// 未设置的参数使用 DefaultConfig 中的值，相对路径写作 lumberjack:logs/app.log
+func lumberjackConfig(u *url.URL) (Config, error) {
+	path := u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return Config{}, fmt.Errorf("unexpected host %q, use lumberjack:///absolute/path or lumberjack:relative/path", u.Host)
+	}
+	if path == "" || strings.HasSuffix(path, "/") {
+		return Config{}, errors.New("missing file name")
+	}
+
+	defaults := DefaultConfig()
+	config := Config{
+		MaxSize:    defaults.MaxSize,
+		MaxBackups: defaults.MaxBackups,
+		MaxAge:     defaults.MaxAge,
+	}
+	config.LogPath, config.Filename = filepath.Split(filepath.FromSlash(path))
+
+	var errs []error
+	query := u.Query()
+	for key := range query {
+		value := query.Get(key)
+		var err error
+		switch key {
+		case "maxsize":
+			config.MaxSize, err = strconv.Atoi(value)
+		case "backups", "maxbackups":
+			config.MaxBackups, err = strconv.Atoi(value)
+		case "maxage":
+			config.MaxAge, err = strconv.Atoi(value)
+		case "compress":
+			config.Compress, err = strconv.ParseBool(value)
+		case "localtime":
+			config.LocalTime, err = strconv.ParseBool(value)
+		default:
+			err = errors.New("unknown parameter")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s=%s: %w", key, value, err))
+		}
+	}
+
+	return config, errors.Join(errs...)
+}
+
+// newLumberjackSink 创建按大小切割的文件输出，与 FileWriter 指向同一文件时共享同一个 lumberjack.Logger
+func newLumberjackSink(u *url.URL) (zap.Sink, error) {
+	config, err := lumberjackConfig(u)
+	if err != nil {
+		return nil, err
+	}
+
+	// 相对路径相对于当前工作目录
+	if config.LogPath != "" {
+		if err := os.MkdirAll(config.LogPath, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("pplogger: create log path: %w", err)
+		}
+	}
+
+	return acquireFileWriter(config)
+}
+
+// newUDPSink 创建 udp 输出，每条日志一个数据报
+func newUDPSink(u *url.URL) (zap.Sink, error) {
+	conn, err := net.Dial("udp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	return udpSink{conn}, nil
+}
+
+type udpSink struct {
+	net.Conn
+}
+
+func (s udpSink) Sync() error {
+	return nil
+}
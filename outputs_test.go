@@ -0,0 +1,190 @@
+package pplogger
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputs(t *testing.T) {
+	dir := t.TempDir()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	logger, err := NewLogger(Config{Encoding: JSONEncoding, Outputs: []string{
+		"lumberjack://" + filepath.ToSlash(dir) + "/rotated/app.log?maxsize=1&backups=2&compress=true",
+		filepath.Join(dir, "plain.log"),
+		"udp://" + conn.LocalAddr().String(),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil || !strings.Contains(string(buf[:n]), "hello") {
+		t.Fatalf("udp datagram = %q, err = %v", buf[:n], err)
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"rotated/app.log", "plain.log"} {
+		if b, _ := os.ReadFile(filepath.Join(dir, name)); !strings.Contains(string(b), "hello") {
+			t.Errorf("%s = %q", name, b)
+		}
+	}
+}
+
+func TestOutputsShareFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(Config{
+		FileWriter: true,
+		LogPath:    dir,
+		Filename:   "app.log",
+		Outputs:    []string{"lumberjack://" + filepath.ToSlash(dir) + "/app.log"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("shared")
+	_ = logger.Close()
+
+	// 两个输出写入同一个文件，共享同一个写入器，内容不会互相覆盖
+	b, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if got := strings.Count(string(b), "shared"); got != 2 {
+		t.Fatalf("app.log contains %d entries, want 2:\n%s", got, b)
+	}
+}
+
+func TestOutputsInvalid(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"kafka://broker:9092", "supported: stdout, stderr, file, lumberjack, udp"},
+		{"lumberjack:///var/log/app.log?maxsize=big", "maxsize=big"},
+		{"lumberjack:///var/log/app.log?rotate=1", "unknown parameter"},
+		{"lumberjack:///var/log/", "missing file name"},
+		{"udp://127.0.0.1", "want udp://host:port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			err := Config{Outputs: []string{tt.output}}.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputsFromLegacyFlags(t *testing.T) {
+	type want struct {
+		target   string
+		encoding string
+		color    bool
+		levels   bool
+	}
+	tests := []struct {
+		name   string
+		config Config
+		want   []want
+	}{
+		{"none", Config{}, nil},
+		{"file and stdout", Config{
+			FileWriter:     true,
+			StdoutWriter:   true,
+			FileEncoding:   JSONEncoding,
+			StdoutEncoding: ConsoleEncoding,
+			Color:          ColorAlways,
+			Outputs:        []string{"stderr"},
+		}, []want{
+			{outputFileWriter, JSONEncoding, false, false},
+			{"stdout", ConsoleEncoding, true, false},
+			{"stderr", "", false, false},
+		}},
+		{"level outputs", Config{
+			FileWriter:   true,
+			LevelOutputs: map[string]string{"error": "error.log"},
+		}, []want{
+			{outputFileWriter, "", false, true},
+		}},
+		{"split std streams", Config{
+			StdoutWriter:    true,
+			SplitStdStreams: true,
+			Color:           ColorNever,
+		}, []want{
+			{"stdout", "", false, true},
+			{"stderr", "", false, true},
+		}},
+		{"k8s", Config{StdoutWriter: true, K8s: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := parseLevelOutputs(tt.config.LevelOutputs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputs := tt.config.outputs(routes)
+			if len(outputs) != len(tt.want) {
+				t.Fatalf("got %d outputs, want %d: %+v", len(outputs), len(tt.want), outputs)
+			}
+			for i, out := range outputs {
+				got := want{out.target, out.encoding, out.color, out.levels != nil}
+				if got != tt.want[i] {
+					t.Errorf("output %d = %+v, want %+v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOutputsKeepFileSettings(t *testing.T) {
+	dir := t.TempDir()
+	stdout := captureStdout(t)
+	logger, err := NewLogger(Config{
+		FileWriter:      true,
+		StdoutWriter:    true,
+		LogPath:         dir,
+		Filename:        "app.log",
+		RotateInterval:  RotateDaily,
+		FilenamePattern: "app-%Y%m%d.log",
+		FileEncoding:    JSONEncoding,
+		StdoutEncoding:  ConsoleEncoding,
+		Color:           ColorAlways,
+		Outputs:         []string{filepath.Join(dir, "plain.log")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	if err := logger.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 转换后的 FileWriter、StdoutWriter 仍使用各自的编码、颜色及备份文件名格式
+	backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want one named by FilenamePattern", backups)
+	}
+	if file, _ := os.ReadFile(backups[0]); !strings.HasPrefix(string(file), `{"level":"INFO"`) {
+		t.Errorf("%s = %q, want JSON", backups[0], file)
+	}
+	if out := stdout(); !strings.Contains(out, "\x1b[34mINFO\x1b[0m\t") {
+		t.Errorf("stdout = %q, want a colored console level", out)
+	}
+	if plain, _ := os.ReadFile(filepath.Join(dir, "plain.log")); strings.Contains(string(plain), "\x1b[") || !strings.Contains(string(plain), "\tINFO\t") {
+		t.Errorf("plain.log = %q, want the default encoding without color", plain)
+	}
+}
@@ -0,0 +1,199 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// patternVerbs 是 FilenamePattern 支持的 strftime 格式及对应的 Go 时间格式
+var patternVerbs = []struct {
+	verb   byte
+	layout string
+}{
+	{'Y', "2006"},
+	{'m', "01"},
+	{'d', "02"},
+	{'H', "15"},
+	{'M', "04"},
+	{'S', "05"},
+}
+
+// patternPart 是 FilenamePattern 中的一段，verb 为 0 时是原样输出的 text
+type patternPart struct {
+	verb byte
+	text string
+}
+
+// filenamePattern 是解析后的 Config.FilenamePattern
+type filenamePattern struct {
+	parts []patternPart
+	ext   string         // 扩展名，重名时序号加在扩展名之前
+	match *regexp.Regexp // 匹配按该格式命名的文件，包括序号及压缩后的 .gz、.zst
+}
+
+// parseFilenamePattern 解析 FilenamePattern，包含 % 时按 strftime 格式（%Y %m %d %H %M %S %%），
+// 否则按 Go 时间格式（2006 01 02 15 04 05），{name} 替换为 name
+func parseFilenamePattern(pattern, name string) (*filenamePattern, error) {
+	if strings.ContainsAny(pattern, `/\`) {
+		return nil, fmt.Errorf("invalid FilenamePattern %q, want a file name without directory", pattern)
+	}
+
+	var parts []patternPart
+	for i, chunk := range strings.Split(pattern, "{name}") {
+		if i > 0 {
+			parts = append(parts, patternPart{text: name})
+		}
+		chunkParts, err := parsePatternChunk(chunk, strings.Contains(pattern, "%"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid FilenamePattern %q: %w", pattern, err)
+		}
+		parts = append(parts, chunkParts...)
+	}
+
+	p := &filenamePattern{}
+	for _, part := range parts {
+		// 合并相邻的文本，便于取扩展名
+		if n := len(p.parts); part.verb == 0 && n > 0 && p.parts[n-1].verb == 0 {
+			p.parts[n-1].text += part.text
+			continue
+		}
+		p.parts = append(p.parts, part)
+	}
+
+	var verbs int
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i, part := range p.parts {
+		if part.verb != 0 {
+			verbs++
+			expr.WriteString(`\d+`)
+			continue
+		}
+		text := part.text
+		if i == len(p.parts)-1 {
+			p.ext = filepath.Ext(text)
+			text = strings.TrimSuffix(text, p.ext)
+		}
+		expr.WriteString(regexp.QuoteMeta(text))
+	}
+	if verbs == 0 {
+		return nil, fmt.Errorf("invalid FilenamePattern %q, want at least one time element such as %%Y or 2006", pattern)
+	}
+	expr.WriteString(`(\.\d+)?` + regexp.QuoteMeta(p.ext) + `(\.gz|\.zst)?$`)
+	p.match = regexp.MustCompile(expr.String())
+
+	return p, nil
+}
+
+// parsePatternChunk 解析不含 {name} 的一段格式
+func parsePatternChunk(chunk string, strftime bool) ([]patternPart, error) {
+	var parts []patternPart
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			parts = append(parts, patternPart{text: text.String()})
+			text.Reset()
+		}
+	}
+
+next:
+	for i := 0; i < len(chunk); i++ {
+		if strftime {
+			if chunk[i] != '%' {
+				text.WriteByte(chunk[i])
+				continue
+			}
+			if i+1 == len(chunk) {
+				return nil, errors.New("trailing %")
+			}
+			i++
+			if chunk[i] == '%' {
+				text.WriteByte('%')
+				continue
+			}
+			for _, v := range patternVerbs {
+				if chunk[i] == v.verb {
+					flush()
+					parts = append(parts, patternPart{verb: v.verb})
+					continue next
+				}
+			}
+			return nil, fmt.Errorf("unknown verb %%%c", chunk[i])
+		}
+
+		for _, v := range patternVerbs {
+			if strings.HasPrefix(chunk[i:], v.layout) {
+				flush()
+				parts = append(parts, patternPart{verb: v.verb})
+				i += len(v.layout) - 1
+				continue next
+			}
+		}
+		text.WriteByte(chunk[i])
+	}
+	flush()
+
+	return parts, nil
+}
+
+// format 按时间 t 生成文件名，n 大于 0 时在扩展名前加上序号，例如 app-20240615.1.log
+func (p *filenamePattern) format(t time.Time, n int) string {
+	var b strings.Builder
+	for _, part := range p.parts {
+		switch part.verb {
+		case 0:
+			b.WriteString(part.text)
+		case 'Y':
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		}
+	}
+
+	name := b.String()
+	if n > 0 {
+		name = strings.TrimSuffix(name, p.ext) + "." + strconv.Itoa(n) + p.ext
+	}
+
+	return name
+}
+
+// sharedFilenamePattern 返回 Filename 以外的日志文件（Error、路由、合并及镜像文件等）使用的 FilenamePattern，
+// 只有包含 {name} 时才能区分各个文件，否则这些文件仍按 lumberjack 的格式命名备份
+func sharedFilenamePattern(pattern string) string {
+	if strings.Contains(pattern, "{name}") {
+		return pattern
+	}
+
+	return ""
+}
+
+// patternBackup 返回 period 所属周期的备份文件路径，已存在时依次加上序号
+func (w *fileWriter) patternBackup(period time.Time) string {
+	dir := filepath.Dir(w.path)
+	for n := 0; ; n++ {
+		name := filepath.Join(dir, w.pattern.format(period.In(w.loc), n))
+		if !fileExists(name) && !fileExists(name+gzipExt) && !fileExists(name+zstdExt) {
+			return name
+		}
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
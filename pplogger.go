@@ -1,14 +1,19 @@
 package pplogger
 
 import (
+	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -22,6 +27,282 @@ type Config struct {
 	MaxBackups   int    // 最多保留备份数
 	MaxAge       int    // 最多保留天数
 	Compress     bool   // 是否压缩
+	LocalTime    bool   // 备份文件名中的时间使用本地时间，默认 UTC
+	Encoding     string // 编码格式 console、json、json-pretty、logfmt、gelf、syslog5424 或 ecs，默认 console
+	CallerSkip   int    // 调用者跳过的层数，通过一层封装函数打日志时设为 1
+
+	// 备份文件的压缩方式 none、gzip 或 zstd，为空时按 Compress 使用 gzip，设置后优先于 Compress。
+	// 压缩由后台逐个完成，MaxBackups、MaxAge 同时计算已压缩及未压缩的备份
+	Compression      string
+	CompressionLevel int           // 压缩等级 1（最快）到 9（最小），zstd 对应到其相近的等级，默认各自的默认等级
+	CompressDelay    time.Duration // 切割后延迟压缩的时间，避免切割时与请求处理争抢 CPU，默认立即压缩
+
+	StdoutEncoding string // 控制台的编码格式，为空时使用 Encoding
+	FileEncoding   string // 文件的编码格式，为空时使用 Encoding
+
+	TimeZone   string // 时间的时区，UTC、Local 或 Asia/Shanghai 等 IANA 名称，默认本地时间
+	TimeFormat string // 时间格式，为空时使用 TimeEncoder，可选 epoch_millis、epoch_nanos、rfc3339nano
+
+	Keys EncoderKeys // 自定义字段名
+
+	// 控制台输出的等级是否带颜色，auto（默认）在终端中带颜色，always 总是带颜色，never 不带颜色，
+	// 文件等其他输出始终不带颜色。auto 时遵循 NO_COLOR 与 FORCE_COLOR/CLICOLOR_FORCE 环境变量
+	Color string
+
+	// 额外的输出，实现了 zapcore.WriteSyncer 的会直接使用以保留其 Sync。
+	// 写入是同步的，慢速 writer 会阻塞日志调用，必要时可用 zapcore.BufferedWriteSyncer 包装
+	ExtraWriters []io.Writer
+
+	// 以 URL 描述的输出，例如 "stdout"、"stderr"、"/var/log/app.log"、
+	// "lumberjack:///var/log/app.log?maxsize=100&backups=5&compress=true"、"udp://1.2.3.4:514"，
+	// 便于从配置文件描述全部输出，各项使用 Encoding 且不带颜色。lumberjack 与 FileWriter 指向同一文件时共享切割。
+	// StdoutWriter、FileWriter 在内部转换为同一输出列表中排在 Outputs 之前的项，并保留各自的编码（StdoutEncoding、FileEncoding）、
+	// 颜色、SplitStdStreams 及 FilenamePattern、SymlinkName、Banner 等文件设置
+	Outputs []string
+
+	// 按 logger 名称（logger.Named）单独设置日志等级，例如 {"db": "Debug"}。按 . 分隔的前缀匹配，"api" 同时用于
+	// "api.v1"、"api.v2"，多个前缀匹配时最长的优先；"" 只用于未命名的 logger。等级可以低于 LogLevel。
+	// 可通过 Logger.SetModuleLevels、SetModuleLevel、ResetModuleLevel 在运行时修改
+	ModuleLevels map[string]string
+
+	// 是否安装为 zap.L()/zap.S() 并重定向标准库 log。NewLogger、NewWrapped、Init 等返回的 Logger 可通过
+	// Logger.RestoreGlobals 或 Close 恢复，安装期间其他 Logger 再设置时返回 ErrGlobalsReplaced；
+	// NewPPLogger、New 等只返回 *zap.Logger 的构造函数安装后无法恢复，后安装的覆盖先安装的
+	ReplaceGlobals bool
+
+	DisableCaller     bool // 是否关闭调用者信息，关闭后不再输出 C 列
+	DisableStacktrace bool // 是否关闭 Error 及以上等级的堆栈信息
+
+	// 开发模式：DPanic 会触发 panic，Warn 及以上输出堆栈
+	Development bool
+
+	// 输出堆栈的最低等级，取值同 LogLevel，为 StacktraceNone 时不输出任何堆栈，
+	// 为空时默认 Error（开发模式下为 Warn）
+	StacktraceLevel string
+
+	// 附加到每条日志上的固定字段，按 key 排序后通过 zap.Any 转换
+	InitialFields map[string]interface{}
+
+	IncludeHostInfo bool         // 是否为每条日志附加主机名、进程号及 AppName
+	AppName         string       // 应用名称，为空时不输出
+	AppVersion      string       // 应用版本，用于 Banner，为空时不输出
+	HostInfoKeys    HostInfoKeys // 主机信息字段名，为空时使用 host、pid、app
+
+	// 容器模式：输出到控制台，每条日志严格占一行（消息与堆栈中的换行转义为 \n），
+	// 时间默认使用 RFC3339Nano，Warn 及以上写到 stderr，其余写到 stdout
+	K8s bool
+
+	// 在内存中保存最近的若干条日志，供 Logger.Dump、Logger.Entries 读取，0 表示不保存。
+	// 保存全部等级的日志，不受 LogLevel 限制，编码格式同 Encoding
+	RingBufferSize int
+
+	// 完整执行编码等处理但丢弃输出，用于基准测试或不需要日志输出的测试
+	DiscardWriter bool
+
+	LineEnding string // 行尾，lf（默认）或 crlf
+
+	// 消息及字符串字段的最大字节数，0 表示不限制。超出部分按字符边界截断，
+	// 并追加 "...(truncated, N bytes)"，N 为截掉的字节数
+	MaxMessageBytes int
+	MaxFieldBytes   int
+
+	EscapeNewlines   bool // 是否将消息及字符串字段中的换行和回车转义为字面的 \n、\r，保证单行输出
+	EscapeStacktrace bool // EscapeNewlines 时是否也转义堆栈，默认堆栈保持多行
+
+	CallerFormat string // 调用者格式，short（默认，pkg/file.go:42）、full（完整路径）或 func（pkg.Func:42）
+
+	// 等级格式，capital（默认，INFO）、lower（info）、capitalColor、lowerColor 或 short3（INF）。
+	// 带颜色的格式只在控制台允许颜色时（见 Color）输出颜色，文件等其他输出仍不带颜色。logfmt 编码默认 lower
+	LevelFormat string
+
+	// zap.Duration 字段的格式，string（默认，1.5s）、seconds（浮点秒数）、millis（整数毫秒）或 nanos（整数纳秒）
+	DurationFormat string
+
+	// 在 Config 的各项设置生效后、创建 Encoder 前调用，可修改 EncoderConfig 的任意字段，
+	// 例如 EncodeName、ConsoleSeparator。每个输出各调用一次
+	EncoderConfigOverride func(*zapcore.EncoderConfig)
+
+	StacktraceMaxFrames    int      // 堆栈最多保留的帧数，超出部分以 "... N more" 代替，0 表示不限制
+	StacktraceSkipPrefixes []string // 去掉函数名以这些前缀开头的堆栈帧，例如 go.uber.org/zap、net/http
+
+	VerboseErrors bool // 是否将 zap.Error 等错误字段按 ErrorField 输出错误链及堆栈
+
+	SortFields bool // 是否将附加字段按 key 排序后输出，时间、等级、调用者、消息等固定部分位置不变
+
+	// 丢弃消息匹配其中任一正则的日志，用于屏蔽无法修改的第三方库反复输出的无用日志。
+	// 各规则丢弃的条数见 Stats.DroppedByPattern，DropMatchLoggerName 为 true 时同时匹配 logger 名称
+	DropPatterns        []string
+	DropMatchLoggerName bool
+
+	// 按字段等决定是否输出，返回 false 时丢弃，例如丢弃 tenant 为 loadtest 的 Debug 日志，或只保留带有 request_id 的日志。
+	// 在编码之前调用，fields 包括 With 附加的字段，panic 时保留该日志。每条通过等级过滤的日志都会调用，应尽量轻量；为 nil 时没有额外开销
+	Filter FilterFunc
+
+	// 对高频的日志采样，减少流量高峰时的写入，为 nil 时不采样，见 SamplingConfig。丢弃的条数见 Stats.Sampled
+	Sampling *SamplingConfig
+
+	// 按消息限流，例如同一条警告每秒最多输出 10 条，超出的丢弃并定期输出汇总，为 nil 时不限流，见 RateLimitConfig。
+	// 与 Sampling 同时生效，在 Filter、DropPatterns 之后判断，被它们丢弃的日志不计入限流。丢弃的条数见 Stats.RateLimited
+	RateLimit *RateLimitConfig
+
+	// 在该时间内连续输出的相同日志（等级、logger 名称、消息及字段都相同）只输出第一条，
+	// 出现不同的日志、时间结束或 Sync 时输出一条 "last message repeated N times"，原消息在 original_message 字段中。为 0 时不去重
+	DedupWindow time.Duration
+
+	// logger 名称各层之间的分隔符，默认与 zap 相同为 .，例如 Named("api").Named("v2") 输出 api.v2。
+	// 名称的字段名通过 Keys.NameKey 修改，为 OmitKey 时不输出
+	NameSeparator string
+
+	// Error 及以上等级的日志额外写入的文件，为空时不输出。ErrorLogPath 为空时使用 LogPath，
+	// 切割参数为 0 时与主文件相同。ErrorFilename 可单独使用，不要求 FileWriter
+	ErrorFilename   string
+	ErrorLogPath    string
+	ErrorMaxSize    int
+	ErrorMaxBackups int
+	ErrorMaxAge     int
+
+	// 按等级将日志写到不同文件，key 为以 | 分隔的等级，value 为 LogPath 下的文件名，
+	// 例如 {"Debug|Info": "app.log", "Warn": "warn.log", "Error|Fatal": "error.log"}。
+	// 同一等级只能出现一次，未列出的等级在 FileWriter 为 true 时写入 Filename
+	LevelOutputs map[string]string
+
+	// FileWriter 为 true 时，写入 Filename 的日志同时写入这些文件，例如本地盘与 NFS 各一份。
+	// 镜像文件异步写入，写入失败或过慢时不影响主文件，失败及丢弃的条数计入 Stats
+	AdditionalFiles []FileTarget
+
+	// FileWriter 为 true 时写入 Filename 的日志同时写入 LogPath 下的该文件，例如 combined.log。
+	// 多个 Logger 设置同一文件时共享，可用于汇总 NewFactory 创建的各个日志
+	CombinedFilename string
+
+	// FileWriter 为 true 时代替日志文件写入，例如测试中的内存缓冲或嵌入式设备的 flash 写入。
+	// 此时 LogPath、Filename、切割参数、PathPattern 及 OnRotate 均不生效，设置了的会在创建时输出一条 Warn。
+	// FileSyncer 由调用方负责关闭
+	FileSyncer zapcore.WriteSyncer
+
+	// 按时间切割日志文件，hourly 为每个整点、daily 为每天零点切割，也可以是能整除 24h 的时长，例如 "15m"、"6h"，
+	// 切割时间从当天零点（按 TimeZone）起对齐，与进程启动时间无关。与 MaxSize 同时生效，先达到的触发切割，
+	// 一个周期内可能产生多个备份。备份文件名中的时间为切割时间，MaxAge 按该时间清理。上一周期没有写入时不切割
+	RotateInterval string
+
+	// 日志文件及其全部备份占用空间的上限，例如 "2GB"、"500MB" 或字节数，每个日志文件（Error、路由文件等）分别计算。
+	// 每次切割后在后台按修改时间从旧到新删除备份直到低于上限，不会删除当前文件，删除的文件通过日志输出。
+	// 与 MaxBackups、MaxAge 同时生效，使用 PathPattern 时只统计当前目录
+	MaxTotalSize string
+
+	// 设置了 RotateInterval 时备份文件的命名格式，例如 "app-%Y%m%d-%H.log" 或 Go 时间格式 "app-2006-01-02.log"，
+	// 支持 %Y %m %d %H %M %S（Go 格式为 2006 01 02 15 04 05），时间为文件所属周期的开始时间（按 TimeZone）。
+	// 当前写入的文件仍为 Filename，切割时重命名为该格式，按大小切割的备份同样按该格式命名，重名时在扩展名前加上序号，
+	// 例如 app-20240615-10.1.log。MaxBackups、MaxAge 按文件修改时间清理这些备份。
+	// 只用于 Filename，包含 {name} 时（例如 "{name}-%Y%m%d.log"）替换为各文件名去掉扩展名的部分，同时用于 Error、路由等其他文件
+	FilenamePattern string
+
+	// 在 LogPath 下维护指向当前日志文件的符号链接，例如 "current.log"，切割或切换文件（PathPattern）后更新，
+	// 便于 tail -F 跟踪。无法创建符号链接时（例如 Windows 没有权限）改用硬链接，同样失败时输出一次警告后不再更新
+	SymlinkName string
+
+	// 收到 SIGHUP 时调用 Logger.Rotate 切割日志文件，配合外部 logrotate 等工具。多个 Logger 共用一个信号处理，
+	// Close 时移除，Windows 及 plan9 上不生效
+	RotateOnSIGHUP bool
+
+	// 收到 SIGUSR1 时将全局等级改为 Debug，收到 SIGUSR2 时恢复为 LogLevel，等级变化时输出一条 Info，便于线上临时排查。
+	// 多个 Logger 共用一个信号处理，Close 时移除，Windows 及 plan9 上不生效，创建时输出一条 Warn
+	SignalLevelToggle bool
+
+	// 从该文件读取全局日志等级（内容同 LogLevel，忽略首尾空白），创建时读取一次，之后每秒检查，内容变化时立即生效。
+	// 无法解析时输出 Warn 并保留之前的等级，文件被删除后恢复为 LogLevel，便于只能修改文件的部署工具调整等级
+	LevelFile string
+
+	// 创建时从该环境变量读取日志等级并覆盖 LogLevel，同一程序部署到不同环境时无需修改代码，例如 PPLOGGER_LEVEL=debug。
+	// 为空时不读取，DefaultConfig 及各预设中为 DefaultLevelEnv。变量未设置或为空时使用 LogLevel，无法解析时输出 Warn 并使用 LogLevel。
+	// SIGUSR2 及 LevelFile 被删除时恢复为该等级；LevelFile 优先于环境变量
+	LevelFromEnv string
+
+	// 创建 Logger 后输出一条 Info 的 Banner（消息为 "pplogger: banner"），包含主机名、进程号、AppName、AppVersion 及
+	// 等级、编码、切割参数等生效的配置，并在每次切割后作为新文件的第一条写入，使磁盘上的每个日志文件都能说明自身来源。
+	// Banner 不受 LogLevel 及 ModuleLevels 限制，但 ErrorFilename、LevelOutputs 等按等级分流的文件仍只在切割后写入
+	Banner bool
+
+	// 每次写入前（同一秒内只检查一次）检查 LogPath/Filename 是否已被删除或重命名为其他文件，例如 logrotate 默认的
+	// 先移走再创建，此时关闭原文件并重新打开该路径，之后的日志写到新文件。使用 copytruncate 时不需要
+	ReopenOnRename bool
+
+	// 按日期分目录存放 Filename，值为 Go 时间格式，例如 "2006/01/02" 时写到 LogPath/2024/06/15/app.log。
+	// 日期按 TimeZone 计算，跨天时自动切换到新目录并关闭之前的文件，同一天内仍按 MaxSize 切割
+	PathPattern string
+
+	// 创建日志文件的写入及切割实现，为 nil 时使用 lumberjack。传入的 Config 中 LogPath、Filename 为该文件的目录及文件名，
+	// Error 等文件的切割参数已替换为各自的设置。按大小切割及 MaxBackups、MaxAge 等备份的清理均由其实现，
+	// Compression、FilenamePattern、MaxTotalSize 不再生效；RotateInterval、Logger.Rotate 及 ReopenOnRename 通过其 Rotate、Close 实现
+	WriterFactory func(Config) (RotatingWriter, error)
+
+	// 日志文件切割产生备份文件后在单独的 goroutine 中调用，path 为备份文件的最终路径（Compress 时为压缩后的 .gz），
+	// 可用于上传到对象存储后删除本地文件。返回的错误及 panic 通过本 Logger 以 Error 输出
+	OnRotate func(path string) error
+
+	SplitStdStreams bool   // StdoutWriter 为 true 时，StderrMinLevel 及以上等级写到 stderr，其余写到 stdout
+	StderrMinLevel  string // 写到 stderr 的最低等级，取值同 LogLevel，默认 Warn
+
+	Syslog       *SyslogConfig       // 同时写入本机 syslog，为 nil 时不写入
+	RemoteSyslog *RemoteSyslogConfig // 同时异步发送到远程 syslog，为 nil 时不发送
+	GELF         *GELFConfig         // 同时通过 UDP 以 GELF 格式发送到 Graylog，为 nil 时不发送
+	UnixSocket   *UnixSocketConfig   // 同时写入 Unix domain socket，例如本机日志代理，为 nil 时不写入
+	TCP          *TCPConfig          // 同时异步发送到 TCP 服务，断线时缓冲并重连，为 nil 时不发送
+
+	// 同时通过原生协议写入 systemd journal，SYSLOG_IDENTIFIER 使用 AppName，仅支持 linux
+	Journald bool
+
+	// 同时写入 Windows 事件日志的 Application 日志，为事件源名称，为空时不写入，仅支持 windows。
+	// 只写入 EventLogLevel（默认 Warn）及以上等级，Warn 为警告，Error 及以上为错误，其余为信息
+	EventSource   string
+	EventLogLevel string
+
+	// 可插拔的输出，例如 pplogger/kafka，由 Logger 负责关闭。
+	// SinkEncoding 为其编码格式，默认 json
+	Sinks        []Sink
+	SinkEncoding string
+
+	// 达到等级时发送告警 webhook（Slack、钉钉等），为 nil 时不发送。发送失败的记录写入其他输出
+	AlertWebhook *AlertWebhookConfig
+
+	// 达到等级的日志按时间窗口汇总后发送邮件，为 nil 时不发送。无法发送的批次写入其他输出
+	Email *EmailConfig
+
+	// 额外的 Core，例如 pplogger/sentry，与其他输出共用等级及字段处理，实现 io.Closer 的由 Logger 负责关闭
+	Cores []zapcore.Core
+
+	// 日志文件无法打开时不报错，写入时重试，只用于保持 NewPPLoggerLite 的原有行为
+	lazyOpen bool
+
+	// ReplaceGlobals 安装的全局日志无法恢复，见 withPermanentGlobals
+	permanentGlobals bool
+}
+
+// SyslogConfig 是 syslog 输出的配置
+type SyslogConfig struct {
+	Network  string // 为空时连接本机 syslog 守护进程，也可为 unix、unixgram
+	Address  string // Network 不为空时的地址，例如 /dev/log
+	Facility string // 设施，例如 user（默认）、daemon、local0 至 local7
+	Tag      string // 标签，为空时使用 AppName，AppName 也为空时使用程序名
+}
+
+// EncoderKeys 定义日志各部分的字段名，为空时使用默认值，为 OmitKey 时不输出该部分
+type EncoderKeys struct {
+	TimeKey       string
+	LevelKey      string
+	NameKey       string
+	CallerKey     string
+	MessageKey    string
+	StacktraceKey string
+}
+
+// OmitKey 用于 EncoderKeys，表示不输出对应部分
+const OmitKey = "-"
+
+// HostInfoKeys 定义 IncludeHostInfo 输出的字段名
+type HostInfoKeys struct {
+	Host string
+	PID  string
+	App  string
 }
 
 const (
@@ -34,6 +315,61 @@ const (
 	FatalLevel  = "Fatal"
 )
 
+// StacktraceNone 用于 Config.StacktraceLevel，表示不输出堆栈
+const StacktraceNone = "None"
+
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+const (
+	TimeFormatEpochMillis = "epoch_millis"
+	TimeFormatEpochNanos  = "epoch_nanos"
+	TimeFormatRFC3339Nano = "rfc3339nano"
+)
+
+const (
+	LevelFormatCapital      = "capital"
+	LevelFormatLower        = "lower"
+	LevelFormatCapitalColor = "capitalColor"
+	LevelFormatLowerColor   = "lowerColor"
+	LevelFormatShort3       = "short3"
+)
+
+const (
+	DurationFormatString  = "string"
+	DurationFormatSeconds = "seconds"
+	DurationFormatMillis  = "millis"
+	DurationFormatNanos   = "nanos"
+)
+
+const (
+	CallerFormatShort = "short"
+	CallerFormatFull  = "full"
+	CallerFormatFunc  = "func"
+)
+
+const (
+	LineEndingLF   = "lf"
+	LineEndingCRLF = "crlf"
+)
+
+const (
+	ConsoleEncoding = "console"
+	JSONEncoding    = "json"
+	LogfmtEncoding  = "logfmt"
+	GELFEncoding    = "gelf"
+
+	Syslog5424Encoding = "syslog5424"
+	ECSEncoding        = "ecs"
+
+	// JSONPrettyEncoding 输出缩进两格的多行 json，条目之间空一行，只用于本地开发时阅读，
+	// 不要用于日志采集。可只设置在 StdoutEncoding 上，文件仍输出单行 json
+	JSONPrettyEncoding = "json-pretty"
+)
+
 func NewEncoderConfig() zapcore.EncoderConfig {
 	return zapcore.EncoderConfig{
 		// Keys can be anything except the empty string.
@@ -48,6 +384,7 @@ func NewEncoderConfig() zapcore.EncoderConfig {
 		EncodeTime:     TimeEncoder,
 		EncodeDuration: zapcore.StringDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
+		EncodeName:     zapcore.FullNameEncoder,
 	}
 }
 
@@ -55,125 +392,647 @@ func TimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 	enc.AppendString(t.Format("2006-01-02 15:04:05.000"))
 }
 
-func getFileLogger(config Config) lumberjack.Logger {
+func getFileLogger(config Config) *lumberjack.Logger {
 
-	return lumberjack.Logger{
+	logger := &lumberjack.Logger{
 		Filename:   filepath.Join(config.LogPath, config.Filename),
 		MaxSize:    config.MaxSize,
 		MaxBackups: config.MaxBackups,
 		MaxAge:     config.MaxAge,
-		Compress:   config.Compress,
+		LocalTime:  config.LocalTime,
+	}
+	// 压缩时备份的清理及压缩交给 fileWriter.mill，见 Config.Compression
+	if config.compression() != "" {
+		logger.MaxBackups, logger.MaxAge = 0, 0
 	}
+
+	return logger
 }
 
-func getLogLevel(str string) zapcore.Level {
+// ParseLevel 解析日志等级，不区分大小写，支持 warning、err、trace 等别名，
+// 空字符串视为 Info，未知等级返回错误
+func ParseLevel(str string) (zapcore.Level, error) {
 	var level zapcore.Level
-	switch str {
-	case DebugLevel:
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "debug", "trace":
 		level = zap.DebugLevel
-	case InfoLevel:
+	case "info", "":
 		level = zap.InfoLevel
-	case WarnLevel:
+	case "warn", "warning":
 		level = zap.WarnLevel
-	case ErrorLevel:
+	case "error", "err":
 		level = zap.ErrorLevel
-	case DPanicLevel:
+	case "dpanic":
 		level = zap.DPanicLevel
-	case PanicLevel:
+	case "panic":
 		level = zap.PanicLevel
-	case FatalLevel:
+	case "fatal":
 		level = zap.FatalLevel
 	default:
-		level = zap.InfoLevel
+		return level, fmt.Errorf("unknown log level %q", str)
 	}
 
-	return level
+	return level, nil
 }
 
+// callerFile 返回本包之外第一个调用者所在的源文件
+func callerFile() string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPath+".") {
+			return frame.File
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+var pkgPath = reflect.TypeOf(Config{}).PkgPath()
+
 func NewPPLogger(config Config) (*zap.Logger, *zap.SugaredLogger) {
+	// 保持原有行为，未知等级按 Info 处理
+	if _, err := ParseLevel(config.LogLevel); err != nil {
+		config.LogLevel = InfoLevel
+	}
 
-	// 设置默认值
+	logger, sugar, err := NewPPLoggerE(config)
+	if err != nil {
+		log.Fatal("foundation logger: ", err)
+	}
 
-	if config.MaxSize == 0 {
-		config.MaxSize = 500
+	return logger, sugar
+}
+
+// NewPPLoggerE 与 NewPPLogger 相同，但出错时返回 error 而不是退出进程。
+// 返回的日志无法关闭，需要关闭日志文件或停止 RateLimit、LevelFile、信号处理等后台任务时使用 NewLogger
+func NewPPLoggerE(config Config) (*zap.Logger, *zap.SugaredLogger, error) {
+	logger, err := newLogger([]Option{WithConfig(config), withPermanentGlobals()})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if config.MaxBackups == 0 {
-		config.MaxBackups = 3
+	return logger.Logger, logger.Sugar(), nil
+}
+
+// NewNop 返回丢弃所有日志的 Logger，不会访问文件系统
+func NewNop() (*zap.Logger, *zap.SugaredLogger) {
+	logger := zap.NewNop()
+	return logger, logger.Sugar()
+}
+
+// NewDiscardConfig 返回丢弃所有输出的 Config，用于必须经过 NewPPLogger 的场景
+func NewDiscardConfig() Config {
+	return Config{
+		ExtraWriters: []io.Writer{io.Discard},
 	}
+}
 
-	if config.MaxAge == 0 {
-		config.MaxAge = 30
+func initialFields(fields map[string]interface{}) []zap.Field {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	logPath := config.LogPath
+	zapFields := make([]zap.Field, 0, len(keys))
+	for _, key := range keys {
+		zapFields = append(zapFields, zap.Any(key, fields[key]))
+	}
+
+	return zapFields
+}
+
+// hostname 返回主机名，获取失败时返回 unknown
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "unknown"
+	}
+
+	return name
+}
 
+func hostInfoFields(config Config) []zap.Field {
+	keys := config.HostInfoKeys
+	if keys.Host == "" {
+		keys.Host = "host"
+	}
+	if keys.PID == "" {
+		keys.PID = "pid"
+	}
+	if keys.App == "" {
+		keys.App = "app"
+	}
+
+	fields := []zap.Field{zap.String(keys.Host, hostname()), zap.Int(keys.PID, os.Getpid())}
+	if config.AppName != "" {
+		fields = append(fields, zap.String(keys.App, config.AppName))
+	}
+
+	return fields
+}
+
+// resolveLogPath 确定日志目录并确保其存在
+func resolveLogPath(logPath string) (string, error) {
 	if logPath == "" || logPath == "./" {
-		logPath = "./logs"
+		logPath = DefaultConfig().LogPath
 	}
 
 	if _, err := os.Stat(logPath); os.IsNotExist(err) {
 		absRegexp, _ := regexp.Compile(`^(/|([a-zA-Z]:\\)).*`)
 		if !absRegexp.MatchString(logPath) {
-			_, currentFilePath, _, _ := runtime.Caller(1)
-			workPath := filepath.Join(filepath.Dir(currentFilePath), "../")
+			workPath := filepath.Join(filepath.Dir(callerFile()), "../")
 			logPath = filepath.Join(workPath, logPath)
 		}
 	}
 
 	if err := os.MkdirAll(logPath, os.ModePerm); err != nil {
-		log.Fatal("foundation logger: ", err)
+		return "", fmt.Errorf("pplogger: create log path: %w", err)
 	}
 
-	config.LogPath = logPath
+	return logPath, nil
+}
+
+func build(config Config, zapOpts ...zap.Option) (*Logger, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	ignored := config.fileSyncerIgnored()
 
-	var multiWriters zapcore.WriteSyncer
+	// 设置默认值
+	defaults := DefaultConfig()
+
+	if config.MaxSize == 0 {
+		config.MaxSize = defaults.MaxSize
+	}
 
-	if config.StdoutWriter && config.FileWriter {
-		fileLogger := getFileLogger(config)
-		multiWriters = zapcore.NewMultiWriteSyncer(zapcore.AddSync(&fileLogger), zapcore.AddSync(os.Stdout))
-	} else if config.FileWriter {
-		fileLogger := getFileLogger(config)
-		multiWriters = zapcore.NewMultiWriteSyncer(zapcore.AddSync(&fileLogger))
-	} else if config.StdoutWriter {
-		multiWriters = zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout))
+	if config.MaxBackups == 0 {
+		config.MaxBackups = defaults.MaxBackups
+	}
+
+	if config.MaxAge == 0 {
+		config.MaxAge = defaults.MaxAge
+	}
+
+	envLevel, envErr := config.levelFromEnv()
+	if envLevel != "" && envErr == nil {
+		config.LogLevel = envLevel
+	}
+	level, _ := ParseLevel(config.LogLevel)
+
+	var cores []zapcore.Core
+	logger := &Logger{}
+	routes, _ := parseLevelOutputs(config.LevelOutputs)
+	var fallback io.Writer
+	var linked interface{ setLink(*currentLink) }
+
+	if config.FileWriter && config.FileSyncer == nil {
+		if logPath, err := resolveLogPath(config.LogPath); err == nil {
+			config.LogPath = logPath
+		} else if !config.lazyOpen {
+			return nil, err
+		}
+	}
+
+	// StdoutWriter、FileWriter 与 Outputs 转换为同一个输出列表
+	for _, out := range config.outputs(routes) {
+		var ws zapcore.WriteSyncer
+		switch out.target {
+		case outputFileWriter:
+			file, multi, err := openLogFile(config, logger)
+			if err != nil {
+				closeAll(logger.closers)
+				return nil, err
+			}
+			fallback = file
+			linked = file
+			ws = multi
+		case "stdout":
+			// 忽略终端不支持 Sync 的错误
+			ws = stdoutSyncer{os.Stdout}
+		case "stderr":
+			ws = stdoutSyncer{os.Stderr}
+		default:
+			sink, closeFunc, err := zap.Open(out.target)
+			if err != nil {
+				closeAll(logger.closers)
+				return nil, fmt.Errorf("pplogger: open output %q: %w", out.target, err)
+			}
+			logger.closers = append(logger.closers, outputCloser(closeFunc))
+			ws = sink
+		}
+
+		core := newSinkCore(config, out.encoding, out.color, ws)
+		if out.levels != nil {
+			core = newLevelFilterCore(core, out.levels)
+		}
+		cores = append(cores, core)
+	}
+
+	for _, route := range routes {
+		routeConfig := config
+		routeConfig.Filename = route.filename
+		routeConfig.FilenamePattern = sharedFilenamePattern(config.FilenamePattern)
+		logPath, err := resolveLogPath(config.LogPath)
+		if err != nil {
+			closeAll(logger.closers)
+			return nil, err
+		}
+		routeConfig.LogPath = logPath
+
+		routeWriter, err := acquireFileWriter(routeConfig)
+		if err != nil {
+			closeAll(logger.closers)
+			return nil, err
+		}
+		logger.closers = append(logger.closers, routeWriter)
+		if config.OnRotate != nil {
+			routeWriter.setOnRotate(rotateHook(logger, config.OnRotate))
+		}
+		if config.MaxTotalSize != "" {
+			routeWriter.setOnRemove(removeHook(logger))
+		}
+		if config.Banner {
+			routeWriter.setBanner(fileBanner(config))
+		}
+		cores = append(cores, newLevelFilterCore(newSinkCore(config, config.FileEncoding, false, routeWriter), route.levels))
+	}
+
+	if config.ErrorFilename != "" {
+		errorWriter, err := acquireErrorFileWriter(config)
+		if err != nil {
+			closeAll(logger.closers)
+			return nil, err
+		}
+		logger.closers = append(logger.closers, errorWriter)
+		if config.OnRotate != nil {
+			errorWriter.setOnRotate(rotateHook(logger, config.OnRotate))
+		}
+		if config.MaxTotalSize != "" {
+			errorWriter.setOnRemove(removeHook(logger))
+		}
+		if config.Banner {
+			errorWriter.setBanner(fileBanner(config))
+		}
+		cores = append(cores, newLevelFilterCore(newSinkCore(config, config.FileEncoding, false, errorWriter), zapcore.ErrorLevel))
+	}
+
+	if config.K8s {
+		config.StdoutWriter = true
+		cores = append(cores, k8sCores(config)...)
+	}
+
+	if config.DiscardWriter {
+		cores = append(cores, newSinkCore(config, "", false, zapcore.AddSync(io.Discard)))
+	}
+
+	if config.Syslog != nil {
+		core, closer, err := newSyslogCore(config, &logger.stats)
+		if err != nil {
+			closeAll(logger.closers)
+			return nil, err
+		}
+		logger.closers = append(logger.closers, closer)
+		cores = append(cores, core)
+	}
+
+	if config.Journald {
+		core, closer, err := newJournaldCore(config, &logger.stats)
+		if err != nil {
+			closeAll(logger.closers)
+			return nil, err
+		}
+		logger.closers = append(logger.closers, closer)
+		cores = append(cores, core)
+	}
+
+	if config.EventSource != "" {
+		core, closer, err := newEventLogCore(config, &logger.stats)
+		if err != nil {
+			closeAll(logger.closers)
+			return nil, err
+		}
+		eventLogLevel := zapcore.WarnLevel
+		if config.EventLogLevel != "" {
+			eventLogLevel, _ = ParseLevel(config.EventLogLevel)
+		}
+		logger.closers = append(logger.closers, closer)
+		cores = append(cores, newLevelFilterCore(core, eventLogLevel))
+	}
+
+	if config.RemoteSyslog != nil {
+		core, closer := newRemoteSyslogCore(config, &logger.stats)
+		logger.closers = append(logger.closers, closer)
+		cores = append(cores, core)
+	}
+
+	if config.GELF != nil {
+		core, closer, err := newGELFCore(config, &logger.stats)
+		if err != nil {
+			closeAll(logger.closers)
+			return nil, err
+		}
+		logger.closers = append(logger.closers, closer)
+		cores = append(cores, core)
+	}
+
+	if config.UnixSocket != nil {
+		core, closer := newUnixSocketCore(config, &logger.stats)
+		logger.closers = append(logger.closers, closer)
+		cores = append(cores, core)
+	}
+
+	if config.TCP != nil {
+		core, closer := newTCPCore(config, &logger.stats)
+		logger.closers = append(logger.closers, closer)
+		cores = append(cores, core)
+	}
+
+	if len(config.ExtraWriters) > 0 {
+		var writers []zapcore.WriteSyncer
+		for _, w := range config.ExtraWriters {
+			writers = append(writers, zapcore.AddSync(w))
+		}
+		cores = append(cores, newSinkCore(config, "", false, zapcore.NewMultiWriteSyncer(writers...)))
+	}
+
+	if len(config.Sinks) > 0 {
+		sinks, closers := sinkCores(config, fallback)
+		cores = append(cores, sinks...)
+		logger.closers = append(closers, logger.closers...)
+	}
+
+	for _, core := range config.Cores {
+		// 外层写入时不会调用各 Core 的 Check，按 Core 自身的等级过滤
+		cores = append(cores, newLevelFilterCore(core, core))
+		if closer, ok := core.(io.Closer); ok {
+			logger.closers = append([]io.Closer{closer}, logger.closers...)
+		}
+	}
+
+	if config.AlertWebhook != nil {
+		alert := newAlertCore(config, zapcore.NewTee(cores...))
+		logger.closers = append([]io.Closer{alert}, logger.closers...)
+		cores = append(cores, newLevelFilterCore(alert, alert))
+	}
+
+	if config.Email != nil {
+		email := newEmailCore(config, zapcore.NewTee(cores...))
+		logger.closers = append([]io.Closer{email}, logger.closers...)
+		cores = append(cores, newLevelFilterCore(email, email))
+	}
+
+	modules, _ := parseModuleLevels(config.ModuleLevels)
+	logger.modules.Store(modules)
+	logger.level = zap.NewAtomicLevelAt(level)
+
+	core := zapcore.NewTee(cores...)
+	if config.MaxMessageBytes > 0 || config.MaxFieldBytes > 0 {
+		core = newTruncateCore(core, config.MaxMessageBytes, config.MaxFieldBytes)
+	}
+	if config.EscapeNewlines {
+		core = newEscapeCore(core)
+	}
+	if config.StacktraceMaxFrames > 0 || len(config.StacktraceSkipPrefixes) > 0 {
+		core = newStackCore(core, config.StacktraceMaxFrames, config.StacktraceSkipPrefixes)
+	}
+	if config.VerboseErrors {
+		core = newVerboseErrorCore(core)
+	}
+	if config.SortFields {
+		core = newSortCore(core)
+	}
+	// Banner 直接写入，不经过等级过滤
+	bannerCore := core
+	if config.DedupWindow > 0 {
+		core = newDedupCore(core, config.DedupWindow)
+	}
+	// 在 Filter、DropPatterns 之内限流，被它们丢弃的日志不消耗令牌
+	if config.RateLimit != nil {
+		// 汇总直接写入内层，同样遵循等级
+		limiter := newRateLimiter(newLevelCore(core, logger.level, &logger.modules), config.RateLimit, &logger.stats)
+		// 先于各输出关闭，以便输出最后的汇总
+		logger.closers = append([]io.Closer{limiter}, logger.closers...)
+		core = newRateLimitCore(core, limiter)
+	}
+	if config.Filter != nil {
+		core = newFilterCore(core, config.Filter)
+	}
+	if len(config.DropPatterns) > 0 {
+		logger.drops, _ = compileDropPatterns(config.DropPatterns)
+		core = newDropCore(core, logger.drops, config.DropMatchLoggerName)
+	}
+	core = newLevelCore(core, logger.level, &logger.modules)
+	if config.Sampling.enabled() {
+		core = newSamplingCore(core, config.Sampling, &logger.stats)
+	}
+	if config.RingBufferSize > 0 {
+		logger.ring = newRingBuffer(config.RingBufferSize)
+		core = zapcore.NewTee(core, newSinkCore(config, "", false, logger.ring))
+	}
+
+	var opts []zap.Option
+	if !config.DisableCaller {
+		opts = append(opts, zap.AddCaller())
+	}
+	if !config.DisableStacktrace && config.StacktraceLevel != StacktraceNone {
+		stacktraceLevel := zap.ErrorLevel
+		if config.StacktraceLevel != "" {
+			stacktraceLevel, _ = ParseLevel(config.StacktraceLevel)
+		} else if config.Development {
+			stacktraceLevel = zap.WarnLevel
+		}
+		opts = append(opts, zap.AddStacktrace(stacktraceLevel))
+	}
+	if config.Development {
+		opts = append(opts, zap.Development())
+	}
+	var fields []zap.Field
+	if config.IncludeHostInfo {
+		fields = append(fields, hostInfoFields(config)...)
+	}
+	fields = append(fields, initialFields(config.InitialFields)...)
+	if len(fields) > 0 {
+		opts = append(opts, zap.Fields(fields...))
+	}
+	opts = append(opts, zap.AddCallerSkip(config.CallerSkip))
+	opts = append(opts, zapOpts...)
+	logger.Logger = zap.New(core, opts...)
+	logger.config = config
+
+	if len(ignored) > 0 {
+		logger.Warn("pplogger: file settings are ignored when FileSyncer is set", zap.Strings("fields", ignored))
+	}
+
+	if envErr != nil {
+		logger.Warn("pplogger: invalid log level in environment variable, using LogLevel", zap.String("env", config.LevelFromEnv), zap.String("value", envLevel), zap.String("logLevel", config.LogLevel), zap.Error(envErr))
+	}
+
+	if config.SymlinkName != "" && linked != nil {
+		link := newCurrentLink(filepath.Join(config.LogPath, config.SymlinkName))
+		link.warn = func(err error) {
+			logger.Warn("pplogger: SymlinkName is no longer updated", zap.Error(err))
+		}
+		linked.setLink(link)
+	}
+
+	if config.Banner {
+		_ = bannerCore.Write(bannerEntry(), bannerFields(config))
+	}
+
+	if config.RotateOnSIGHUP {
+		logger.stopSIGHUP = watchSIGHUP(logger)
+	}
+
+	if config.SignalLevelToggle {
+		logger.stopSignals = watchLevelSignals(logger)
+	}
+
+	if config.LevelFile != "" {
+		logger.stopFile = watchLevelFile(logger, config.LevelFile)
+	}
+
+	if config.ReplaceGlobals {
+		undo, err := replaceZapGlobals(logger, config.permanentGlobals)
+		if err != nil {
+			_ = logger.Close()
+			return nil, err
+		}
+		logger.undoGlobals = undo
+	}
+
+	return logger, nil
+}
+
+// logFile 是 FileWriter 的主日志文件
+type logFile interface {
+	zapcore.WriteSyncer
+	io.Closer
+	setOnRotate(func(string))
+	setLink(*currentLink)
+	setOnRemove(func(string, int64))
+	setBanner(func() []byte)
+}
+
+// openLogFile 打开 FileWriter 的主日志文件，返回的 ws 同时写入 CombinedFilename 及 AdditionalFiles。
+// 打开的文件加入 logger.closers，出错时由调用方关闭
+func openLogFile(config Config, logger *Logger) (logFile, zapcore.WriteSyncer, error) {
+	var fileWriter logFile
+	var err error
+	if config.FileSyncer != nil {
+		fileWriter = fileSyncer{config.FileSyncer}
+	} else if config.PathPattern != "" {
+		fileWriter, err = newDatedFileWriter(config)
 	} else {
-		log.Fatal("Logfile and Stdout must have one set to true")
+		fileWriter, err = acquireFileWriter(config)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.closers = append(logger.closers, fileWriter)
+	if config.OnRotate != nil {
+		fileWriter.setOnRotate(rotateHook(logger, config.OnRotate))
+	}
+	if config.MaxTotalSize != "" {
+		fileWriter.setOnRemove(removeHook(logger))
+	}
+	if config.Banner {
+		fileWriter.setBanner(fileBanner(config))
+	}
+
+	if len(config.AdditionalFiles) == 0 && config.CombinedFilename == "" {
+		return fileWriter, fileWriter, nil
 	}
 
-	core := zapcore.NewCore(
-		zapcore.NewConsoleEncoder(NewEncoderConfig()),
-		multiWriters,
-		getLogLevel(config.LogLevel),
-	)
+	writers := []zapcore.WriteSyncer{fileWriter}
+	if config.CombinedFilename != "" {
+		combinedConfig := config
+		combinedConfig.Filename = config.CombinedFilename
+		combinedConfig.FilenamePattern = sharedFilenamePattern(config.FilenamePattern)
+		combinedConfig.LogPath, err = resolveLogPath(config.LogPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		combined, err := acquireFileWriter(combinedConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.closers = append(logger.closers, combined)
+		if config.MaxTotalSize != "" {
+			combined.setOnRemove(removeHook(logger))
+		}
+		if config.Banner {
+			combined.setBanner(fileBanner(config))
+		}
+		writers = append(writers, combined)
+	}
+	for _, target := range config.AdditionalFiles {
+		mirror := newMirrorWriter(config, target, &logger.stats)
+		logger.closers = append(logger.closers, mirror)
+		writers = append(writers, mirror)
+	}
+
+	return fileWriter, zapcore.NewMultiWriteSyncer(writers...), nil
+}
+
+// NewPPLoggerLite 同时输出到控制台和 fileName，可通过 Option 调整切割参数等配置，
+// 日志文件无法打开时继续输出到控制台并在写入时重试；使用 WithoutStdout 时只写文件，文件无法打开会直接报错
+func NewPPLoggerLite(fileName string, logLevel string, opts ...Option) (*zap.Logger, *zap.SugaredLogger) {
+	// 保持原有行为，未知等级按 Info 处理
+	if _, err := ParseLevel(logLevel); err != nil {
+		logLevel = InfoLevel
+	}
 
-	opts := []zap.Option{zap.AddCaller()}
-	opts = append(opts, zap.AddStacktrace(zap.ErrorLevel))
-	opts = append(opts, zap.AddCallerSkip(0))
-	logger := zap.New(core, opts...)
-	sugar := logger.Sugar()
+	logger, sugar, err := NewPPLoggerLiteE(fileName, logLevel, opts...)
+	if err != nil {
+		log.Fatal("foundation logger: ", err)
+	}
 
 	return logger, sugar
 }
 
-func NewPPLoggerLite(fileName string, logLevel string) (*zap.Logger, *zap.SugaredLogger) {
+// withLazyOpen 在输出到控制台时允许日志文件暂时无法打开，与原有的 NewPPLoggerLite 一样继续输出到控制台并在写入时重试；
+// 只写文件（WithoutStdout）时仍直接报错
+func withLazyOpen() Option {
+	return func(c *Config) error {
+		c.lazyOpen = c.StdoutWriter
+		return nil
+	}
+}
+
+// NewPPLoggerLiteE 与 NewPPLoggerLite 相同，但出错时返回 error 而不是退出进程。返回的日志同样无法关闭，见 NewPPLoggerE
+func NewPPLoggerLiteE(fileName string, logLevel string, opts ...Option) (*zap.Logger, *zap.SugaredLogger, error) {
 	if fileName == "" {
 		fileName = "./logs/pplogger.log"
 	}
-	w := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   fileName,
-		MaxSize:    500, // megabytes
-		MaxBackups: 3,
-		MaxAge:     30, // days
-	})
-	core := zapcore.NewCore(
-		zapcore.NewConsoleEncoder(NewEncoderConfig()),
-		zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout),
-			w),
-		getLogLevel(logLevel),
-	)
-
-	logger := zap.New(core, zap.AddCaller())
-	sugar := logger.Sugar()
-	return logger, sugar
-}
\ No newline at end of file
+
+	// 相对路径以当前工作目录为准
+	if absFileName, err := filepath.Abs(fileName); err == nil {
+		fileName = absFileName
+	}
+
+	config := Config{
+		StdoutWriter: true,
+		FileWriter:   true,
+		LogPath:      filepath.Dir(fileName),
+		Filename:     filepath.Base(fileName),
+		LogLevel:     logLevel,
+		MaxSize:      500, // megabytes
+		MaxBackups:   3,
+		MaxAge:       30, // days
+
+		DisableStacktrace: true,
+	}
+
+	logger, err := newLogger(append(append([]Option{WithConfig(config)}, opts...), withLazyOpen(), withPermanentGlobals()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return logger.Logger, logger.Sugar(), nil
+}
@@ -0,0 +1,423 @@
+package pplogger
+
+import (
+	"encoding/json"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewPPLoggerE(t *testing.T) {
+	dir := t.TempDir()
+	// 普通文件所在的路径无法创建目录
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"no output", Config{}, "no output configured"},
+		{"unknown level", Config{StdoutWriter: true, LogLevel: "loud"}, "unknown log level"},
+		{"unwritable path", Config{FileWriter: true, LogPath: filepath.Join(blocker, "logs"), Filename: "app.log"}, "create log path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := NewPPLoggerE(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	logger, _, err := NewPPLoggerE(Config{FileWriter: true, LogPath: dir, Filename: "app.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	_ = logger.Sync()
+	if b, _ := os.ReadFile(filepath.Join(dir, "app.log")); !strings.Contains(string(b), "hello") {
+		t.Fatalf("log file = %q", b)
+	}
+}
+
+func TestNewPPLoggerUnknownLevel(t *testing.T) {
+	buf := &syncBuffer{}
+	// 与原有行为一致，未知等级按 Info 处理而不是退出进程
+	logger, _ := NewPPLogger(Config{ExtraWriters: []io.Writer{buf}, LogLevel: "loud"})
+
+	logger.Debug("debug")
+	logger.Info("info")
+	if lines := buf.lines(); len(lines) != 1 || !strings.Contains(lines[0], "info") {
+		t.Fatalf("output = %q", buf)
+	}
+}
+
+func TestNewPPLoggerLiteRotation(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	logger, _ := NewPPLoggerLite(filepath.Join(dir, "lite.log"), "info", WithRotation(1, 2, 0, true), WithoutStdout())
+
+	// 每条约 300KB，1MB 切割一次，保留 2 个压缩后的备份
+	line := strings.Repeat("x", 300*1024)
+	for i := 0; i < 12; i++ {
+		logger.Info(line)
+	}
+
+	var files, compressed int
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files, compressed = len(entries), 0
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), gzipExt) {
+				compressed++
+			}
+		}
+		if files == 3 && compressed == 2 {
+			return
+		}
+	}
+	t.Fatalf("got %d files with %d compressed, want the current file and 2 compressed backups", files, compressed)
+}
+
+func TestNewPPLoggerLiteUnwritableFile(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fileName := filepath.Join(blocker, "logs", "lite.log")
+
+	// 与原有行为一致，仍输出到控制台
+	if _, _, err := NewPPLoggerLiteE(fileName, "info"); err != nil {
+		t.Fatalf("NewPPLoggerLiteE with stdout: %v", err)
+	}
+
+	if _, _, err := NewPPLoggerLiteE(fileName, "info", WithoutStdout()); err == nil {
+		t.Fatal("NewPPLoggerLiteE without stdout: want error")
+	}
+}
+
+func TestDisableCallerAndStacktrace(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		caller     bool
+		stacktrace bool
+	}{
+		{"default", Config{}, true, true},
+		{"DisableCaller", Config{DisableCaller: true}, false, true},
+		{"DisableStacktrace", Config{DisableStacktrace: true}, true, false},
+		{"both", Config{DisableCaller: true, DisableStacktrace: true}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &syncBuffer{}
+			config := tt.config
+			config.ExtraWriters = []io.Writer{buf}
+			logger, err := NewLogger(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer logger.Close()
+
+			logger.Error("failed")
+			out := buf.String()
+			// 第一行为时间、等级、调用者（关闭时不输出该列）及消息，之后为堆栈
+			first, stack, _ := strings.Cut(out, "\n")
+			columns := strings.Split(first, "\t")
+			if got := len(columns) == 4 && strings.Contains(columns[2], "pplogger_test.go:"); got != tt.caller {
+				t.Errorf("caller present = %v, want %v: %q", got, tt.caller, columns)
+			}
+			if !tt.caller && len(columns) != 3 {
+				t.Errorf("got %d columns, want 3 without caller: %q", len(columns), columns)
+			}
+			if got := strings.Contains(stack, "TestDisableCallerAndStacktrace"); got != tt.stacktrace {
+				t.Errorf("stacktrace present = %v, want %v:\n%s", got, tt.stacktrace, out)
+			}
+		})
+	}
+}
+
+func BenchmarkCaller(b *testing.B) {
+	for _, disable := range []bool{false, true} {
+		name := "caller"
+		if disable {
+			name = "DisableCaller"
+		}
+		b.Run(name, func(b *testing.B) {
+			logger, err := NewLogger(Config{DiscardWriter: true, DisableCaller: disable})
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer logger.Close()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Info("benchmark")
+			}
+		})
+	}
+}
+
+func TestDevelopment(t *testing.T) {
+	for _, development := range []bool{false, true} {
+		buf := &syncBuffer{}
+		logger, err := NewLogger(Config{ExtraWriters: []io.Writer{buf}, Development: development})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if logger.Config().Development != development {
+			t.Errorf("Config().Development = %v, want %v", logger.Config().Development, development)
+		}
+
+		// 开发模式下 Warn 即输出堆栈
+		logger.Warn("warn")
+		if got := strings.Contains(buf.String(), "TestDevelopment"); got != development {
+			t.Errorf("Development=%v: Warn stacktrace present = %v", development, got)
+		}
+
+		panicked := func() (panicked bool) {
+			defer func() { panicked = recover() != nil }()
+			logger.DPanic("dpanic")
+			return false
+		}()
+		if panicked != development {
+			t.Errorf("Development=%v: DPanic panicked = %v", development, panicked)
+		}
+		_ = logger.Close()
+	}
+}
+
+func TestStacktraceLevel(t *testing.T) {
+	tests := []struct {
+		level string
+		entry zapcore.Level
+		want  bool
+	}{
+		{"", zapcore.WarnLevel, false},
+		{"", zapcore.ErrorLevel, true},
+		{"warn", zapcore.InfoLevel, false},
+		{"warn", zapcore.WarnLevel, true},
+		{"dpanic", zapcore.ErrorLevel, false},
+		{"dpanic", zapcore.DPanicLevel, true},
+		{StacktraceNone, zapcore.ErrorLevel, false},
+		{StacktraceNone, zapcore.PanicLevel, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level+"/"+tt.entry.String(), func(t *testing.T) {
+			buf := &syncBuffer{}
+			logger, err := NewLogger(Config{ExtraWriters: []io.Writer{buf}, Encoding: JSONEncoding, StacktraceLevel: tt.level})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer logger.Close()
+
+			func() {
+				defer func() { _ = recover() }()
+				if ce := logger.Check(tt.entry, "entry"); ce != nil {
+					ce.Write()
+				}
+			}()
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(buf.String()), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if _, got := entry["stacktrace"]; got != tt.want {
+				t.Fatalf("stacktrace present = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := (Config{StdoutWriter: true, StacktraceLevel: "loud"}).Validate(); err == nil {
+		t.Fatal("unknown StacktraceLevel: want error")
+	}
+}
+
+func TestLevelOutputs(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(Config{
+		FileWriter:        true,
+		LogPath:           dir,
+		Filename:          "default.log",
+		LogLevel:          DebugLevel,
+		DisableStacktrace: true,
+		LevelOutputs:      map[string]string{"Debug|Info": "app.log", "Warn": "warn.log", "error|Fatal": "error.log"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("m-debug")
+	logger.Info("m-info")
+	logger.Warn("m-warn")
+	logger.Error("m-error")
+	func() {
+		defer func() { _ = recover() }()
+		logger.Panic("m-panic")
+	}()
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未列出的等级写入默认的 Filename
+	want := map[string][]string{
+		"app.log":     {"m-debug", "m-info"},
+		"warn.log":    {"m-warn"},
+		"error.log":   {"m-error"},
+		"default.log": {"m-panic"},
+	}
+	for file, messages := range want {
+		b, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+			columns := strings.Split(line, "\t")
+			got = append(got, columns[len(columns)-1])
+		}
+		if strings.Join(got, ",") != strings.Join(messages, ",") {
+			t.Errorf("%s got %q, want %q", file, got, messages)
+		}
+	}
+}
+
+func TestLevelOutputsInvalid(t *testing.T) {
+	for _, outputs := range []map[string]string{
+		{"Info|Warn": "a.log", "warn": "b.log"},
+		{"Nope": "a.log"},
+		{"Info|": "a.log"},
+		{"Info": ""},
+	} {
+		if err := (Config{StdoutWriter: true, LevelOutputs: outputs}).Validate(); err == nil {
+			t.Errorf("Validate accepted LevelOutputs %v", outputs)
+		}
+	}
+}
+
+// backupTime 返回 dir 中 app 的唯一备份文件名中的时间，按 loc 解析
+func backupTime(t *testing.T, dir string, loc *time.Location) time.Time {
+	t.Helper()
+
+	var backups []string
+	for name := range readDir(t, dir) {
+		if strings.HasPrefix(name, "app-") {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) != 1 {
+		t.Fatalf("got backups %v, want one", backups)
+	}
+	ts := strings.TrimSuffix(strings.TrimPrefix(backups[0], "app-"), ".log")
+	backup, err := time.ParseInLocation(lumberjackTimeFormat, ts, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return backup
+}
+
+func TestLocalTime(t *testing.T) {
+	// 本地时区为 UTC 时无法区分两种命名，在固定的非 UTC 时区下重新运行本测试
+	if os.Getenv("PPLOGGER_TEST_LOCALTIME") == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLocalTime$", "-test.v")
+		cmd.Env = append(os.Environ(), "PPLOGGER_TEST_LOCALTIME=1", "TZ=Asia/Shanghai")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v\n%s", err, out)
+		}
+		if strings.Contains(string(out), "SKIP") {
+			t.Skipf("%s", out)
+		}
+		return
+	}
+	if _, offset := time.Now().Zone(); offset == 0 {
+		t.Skip("time zone data for Asia/Shanghai is unavailable")
+	}
+
+	for _, local := range []bool{false, true} {
+		dir := t.TempDir()
+		logger, err := NewLogger(Config{FileWriter: true, LogPath: dir, Filename: "app.log", LocalTime: local})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Info("before")
+		if err := logger.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		loc := time.UTC
+		if local {
+			loc = time.Local
+		}
+		if d := time.Since(backupTime(t, dir, loc)); d < -time.Minute || d > time.Minute {
+			t.Errorf("LocalTime %v: backup time is %v away from now", local, d)
+		}
+	}
+
+	// 同样用于精简版构造函数及 lumberjack:// 输出
+	if !getFileLogger(Config{LocalTime: true}).LocalTime {
+		t.Error("getFileLogger dropped LocalTime")
+	}
+	u, err := url.Parse("lumberjack:///tmp/app.log?localtime=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config, err := lumberjackConfig(u); err != nil || !config.LocalTime {
+		t.Errorf("lumberjackConfig = %+v, %v", config, err)
+	}
+}
+
+func TestFileSyncer(t *testing.T) {
+	var buf syncBuffer
+	logPath := filepath.Join(t.TempDir(), "logs")
+	logger, err := NewLogger(Config{
+		FileWriter: true,
+		FileSyncer: &buf,
+		Encoding:   JSONEncoding,
+		LogPath:    logPath,
+		MaxSize:    3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 文件相关设置被忽略并给出警告，且不会创建任何文件
+	lines := buf.lines()
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want warning and message: %q", len(lines), lines)
+	}
+	warning := decodeLine(t, lines[0])
+	if warning["msg"] != "pplogger: file settings are ignored when FileSyncer is set" {
+		t.Errorf("warning = %v", warning)
+	}
+	if fields, _ := warning["fields"].([]interface{}); len(fields) != 2 || fields[0] != "LogPath" || fields[1] != "MaxSize" {
+		t.Errorf("ignored fields = %v, want [LogPath MaxSize]", warning["fields"])
+	}
+	if msg := decodeLine(t, lines[1])["msg"]; msg != "hello" {
+		t.Errorf("msg = %v, want hello", msg)
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Errorf("LogPath was created: %v", err)
+	}
+}
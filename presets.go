@@ -0,0 +1,39 @@
+package pplogger
+
+// DefaultConfig 返回默认配置，日志等级可由环境变量 DefaultLevelEnv 覆盖
+func DefaultConfig() Config {
+	return Config{
+		LogPath:      "./logs",
+		LogLevel:     InfoLevel,
+		MaxSize:      500,
+		MaxBackups:   3,
+		MaxAge:       30,
+		LevelFromEnv: DefaultLevelEnv,
+	}
+}
+
+// ProductionConfig 返回生产环境预设：json 编码，Info 等级，同时输出到控制台和文件，压缩切割后的文件，
+// 按 zap.NewProduction 的参数采样（每秒相同的日志先输出 100 条，之后每 100 条输出一条，Error 及以上不采样）
+func ProductionConfig() Config {
+	config := DefaultConfig()
+	config.StdoutWriter = true
+	config.FileWriter = true
+	config.Filename = "pplogger.log"
+	config.Encoding = JSONEncoding
+	config.Compress = true
+	config.Sampling = &SamplingConfig{Initial: 100, Thereafter: 100}
+
+	return config
+}
+
+// DevelopmentConfig 返回开发环境预设：console 编码，Debug 等级，彩色等级，只输出到控制台，开启开发模式（DPanic 时 panic）
+func DevelopmentConfig() Config {
+	config := DefaultConfig()
+	config.StdoutWriter = true
+	config.LogLevel = DebugLevel
+	config.Encoding = ConsoleEncoding
+	config.LevelFormat = LevelFormatCapitalColor
+	config.Development = true
+
+	return config
+}
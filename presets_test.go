@@ -0,0 +1,109 @@
+package pplogger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   Config
+	}{
+		{"default", DefaultConfig(), Config{
+			LogPath:      "./logs",
+			LogLevel:     InfoLevel,
+			MaxSize:      500,
+			MaxBackups:   3,
+			MaxAge:       30,
+			LevelFromEnv: DefaultLevelEnv,
+		}},
+		{"production", ProductionConfig(), Config{
+			StdoutWriter: true,
+			FileWriter:   true,
+			LogPath:      "./logs",
+			Filename:     "pplogger.log",
+			LogLevel:     InfoLevel,
+			MaxSize:      500,
+			MaxBackups:   3,
+			MaxAge:       30,
+			Compress:     true,
+			Encoding:     JSONEncoding,
+			Sampling:     &SamplingConfig{Initial: 100, Thereafter: 100},
+			LevelFromEnv: DefaultLevelEnv,
+		}},
+		{"development", DevelopmentConfig(), Config{
+			StdoutWriter: true,
+			LogPath:      "./logs",
+			LogLevel:     DebugLevel,
+			MaxSize:      500,
+			MaxBackups:   3,
+			MaxAge:       30,
+			Encoding:     ConsoleEncoding,
+			LevelFormat:  LevelFormatCapitalColor,
+			Development:  true,
+			LevelFromEnv: DefaultLevelEnv,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.config, tt.want) {
+				t.Fatalf("config = %+v, want %+v", tt.config, tt.want)
+			}
+		})
+	}
+
+	for _, config := range []Config{ProductionConfig(), DevelopmentConfig()} {
+		if err := config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 每次返回新的 Sampling，修改不会影响之后的预设
+	ProductionConfig().Sampling.Initial = 1
+	if ProductionConfig().Sampling.Initial != 100 {
+		t.Fatal("ProductionConfig shares Sampling between calls")
+	}
+}
+
+func TestDevelopmentConfigDPanic(t *testing.T) {
+	config := DevelopmentConfig()
+	config.StdoutWriter = false
+	config.DiscardWriter = true
+	logger, err := NewLogger(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("DPanic did not panic in development mode")
+		}
+	}()
+	logger.DPanic("boom")
+}
+
+func TestDevelopmentConfigNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	stdout := captureStdout(t)
+
+	// DevelopmentConfig 使用带颜色的等级格式，但仍应遵循 NO_COLOR
+	logger, err := NewLogger(DevelopmentConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("hello")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := stdout()
+	if strings.Contains(out, "\x1b[") || !strings.Contains(out, "\tINFO\t") {
+		t.Errorf("stdout = %q, want a plain level", out)
+	}
+}
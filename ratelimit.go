@@ -0,0 +1,225 @@
+package pplogger
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"time"
+)
+
+// defaultRateLimitKeys 是 RateLimitConfig.MaxKeys 的默认值
+const defaultRateLimitKeys = 1024
+
+// RateLimitConfig 是按消息限流的配置：同一消息每 Per 最多输出 PerMessage 条（令牌桶，可短时突发到 Burst 条），
+// 超出的丢弃，并在之后每个 Per 周期输出一条汇总 "suppressed N occurrences of <msg> in the last X"
+type RateLimitConfig struct {
+	PerMessage    int
+	Per           time.Duration // 默认 1 秒
+	Burst         int           // 令牌桶容量，默认等于 PerMessage
+	ByLoggerName  bool          // 为 true 时按 logger 名称及消息分别计数
+	MaxKeys       int           // 最多记录的消息数，超出时淘汰最久未出现的，默认 1024
+	IncludeErrors bool          // Error 及以上等级默认不限流，为 true 时同样限流
+}
+
+func (c *RateLimitConfig) validate() []error {
+	var errs []error
+
+	if c.PerMessage <= 0 {
+		errs = append(errs, errors.New("RateLimit: PerMessage must be positive"))
+	}
+
+	if c.Per < 0 || c.Burst < 0 || c.MaxKeys < 0 {
+		errs = append(errs, fmt.Errorf("RateLimit: negative Per %s, Burst %d or MaxKeys %d", c.Per, c.Burst, c.MaxKeys))
+	}
+
+	return errs
+}
+
+// rateBucket 是一条消息的令牌桶及被丢弃的条数
+type rateBucket struct {
+	key        string
+	tokens     float64
+	last       time.Time
+	suppressed int
+	since      time.Time     // 第一次丢弃的时间
+	ent        zapcore.Entry // 第一次丢弃的日志，汇总沿用其等级及 logger 名称
+}
+
+// rateLimiter 由 rateLimitCore 及其 With 产生的 Core 共享，消息按 LRU 淘汰，内存占用有上限
+type rateLimiter struct {
+	mu       sync.Mutex
+	config   RateLimitConfig
+	per      time.Duration
+	rate     float64 // 每秒补充的令牌数
+	burst    float64
+	keys     map[string]*list.Element
+	lru      *list.List    // 最近出现的在前
+	evicted  []*rateBucket // 被淘汰但还未输出汇总的
+	core     zapcore.Core  // 输出汇总
+	stats    *sinkStats
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newRateLimiter(core zapcore.Core, c *RateLimitConfig, stats *sinkStats) *rateLimiter {
+	r := &rateLimiter{
+		config:  *c,
+		per:     c.Per,
+		burst:   float64(c.Burst),
+		keys:    make(map[string]*list.Element),
+		lru:     list.New(),
+		core:    core,
+		stats:   stats,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if r.per == 0 {
+		r.per = time.Second
+	}
+	if r.burst == 0 {
+		r.burst = float64(c.PerMessage)
+	}
+	if r.config.MaxKeys == 0 {
+		r.config.MaxKeys = defaultRateLimitKeys
+	}
+	r.rate = float64(c.PerMessage) / r.per.Seconds()
+
+	go r.run()
+
+	return r
+}
+
+// allow 返回 ent 是否可以输出，不可以时计入汇总
+func (r *rateLimiter) allow(ent zapcore.Entry) bool {
+	key := ent.Message
+	if r.config.ByLoggerName {
+		key = ent.LoggerName + "\x00" + ent.Message
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var b *rateBucket
+	if e, ok := r.keys[key]; ok {
+		r.lru.MoveToFront(e)
+		b = e.Value.(*rateBucket)
+		b.tokens += now.Sub(b.last).Seconds() * r.rate
+		if b.tokens > r.burst {
+			b.tokens = r.burst
+		}
+		b.last = now
+	} else {
+		b = &rateBucket{key: key, tokens: r.burst, last: now}
+		r.keys[key] = r.lru.PushFront(b)
+		if r.lru.Len() > r.config.MaxKeys {
+			oldest := r.lru.Remove(r.lru.Back()).(*rateBucket)
+			delete(r.keys, oldest.key)
+			if oldest.suppressed > 0 {
+				r.evicted = append(r.evicted, oldest)
+			}
+		}
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+
+	if b.suppressed == 0 {
+		b.since, b.ent = now, ent
+	}
+	b.suppressed++
+	r.stats.rateLimited.Add(1)
+
+	return false
+}
+
+func (r *rateLimiter) run() {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(r.per)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			r.flush()
+			return
+		case <-ticker.C:
+			r.flush()
+		}
+	}
+}
+
+// flush 为丢弃过日志的消息各输出一条汇总
+func (r *rateLimiter) flush() {
+	r.mu.Lock()
+	buckets := r.evicted
+	r.evicted = nil
+	for e := r.lru.Front(); e != nil; e = e.Next() {
+		if b := e.Value.(*rateBucket); b.suppressed > 0 {
+			copied := *b
+			buckets = append(buckets, &copied)
+			b.suppressed = 0
+		}
+	}
+	r.mu.Unlock()
+
+	now := time.Now()
+	for _, b := range buckets {
+		ent := zapcore.Entry{
+			Level:      b.ent.Level,
+			Time:       now,
+			LoggerName: b.ent.LoggerName,
+			Message:    fmt.Sprintf("suppressed %d occurrences of %q in the last %s", b.suppressed, b.ent.Message, now.Sub(b.since).Round(time.Millisecond)),
+		}
+		if ce := r.core.Check(ent, nil); ce != nil {
+			ce.Write()
+		}
+	}
+}
+
+// Close 停止定时汇总并输出尚未输出的汇总
+func (r *rateLimiter) Close() error {
+	r.stopOnce.Do(func() {
+		close(r.done)
+		<-r.stopped
+	})
+
+	return nil
+}
+
+// rateLimitCore 按消息限流，见 RateLimitConfig
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *rateLimiter
+}
+
+func newRateLimitCore(core zapcore.Core, limiter *rateLimiter) zapcore.Core {
+	return &rateLimitCore{Core: core, limiter: limiter}
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return newRateLimitCore(c.Core.With(fields), c.limiter)
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+// Write 时才消耗令牌，外层的 Filter、DropPatterns 丢弃的日志不会走到这里
+func (c *rateLimitCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if (ent.Level < zapcore.ErrorLevel || c.limiter.config.IncludeErrors) && !c.limiter.allow(ent) {
+		return nil
+	}
+
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,95 @@
+package pplogger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{
+		Encoding:      ConsoleEncoding,
+		DisableCaller: true,
+		ExtraWriters:  []io.Writer{&buf},
+		RateLimit:     &RateLimitConfig{PerMessage: 3, Per: time.Minute},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		logger.Warn("noisy")
+		logger.Error("boom") // Error 及以上默认不限流
+	}
+	stats := logger.Stats()
+	// Close 时输出尚未输出的汇总
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, "\tnoisy\n"); n != 3 {
+		t.Errorf("noisy written %d times, want 3", n)
+	}
+	if n := strings.Count(out, "\tboom\n"); n != 10 {
+		t.Errorf("boom written %d times, want 10", n)
+	}
+	if !strings.Contains(out, `suppressed 7 occurrences of "noisy" in the last`) {
+		t.Errorf("missing summary: %q", out)
+	}
+	if stats.RateLimited != 7 {
+		t.Errorf("RateLimited = %d, want 7", stats.RateLimited)
+	}
+}
+
+func TestRateLimitAfterDropAndFilter(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{
+		Encoding:      ConsoleEncoding,
+		DisableCaller: true,
+		ExtraWriters:  []io.Writer{&buf},
+		DropPatterns:  []string{"^noisy"},
+		Filter: func(ent zapcore.Entry, fields []zapcore.Field) bool {
+			for _, f := range fields {
+				if f.Key == "skip" {
+					return false
+				}
+			}
+			return true
+		},
+		RateLimit: &RateLimitConfig{PerMessage: 2, Per: time.Minute},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 被 DropPatterns、Filter 丢弃的日志不消耗令牌，也不产生汇总
+	for i := 0; i < 5; i++ {
+		logger.Warn("noisy")
+		logger.Warn("kept", zap.Bool("skip", true))
+	}
+	logger.Warn("kept")
+	logger.Warn("kept")
+	stats := logger.Stats()
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, "\tkept\n"); n != 2 {
+		t.Errorf("kept written %d times, want 2: %q", n, out)
+	}
+	if strings.Contains(out, "suppressed") || strings.Contains(out, "noisy") {
+		t.Errorf("unexpected output: %q", out)
+	}
+	if stats.RateLimited != 0 {
+		t.Errorf("RateLimited = %d, want 0", stats.RateLimited)
+	}
+	if stats.DroppedByPattern["^noisy"] != 5 {
+		t.Errorf("DroppedByPattern = %v, want 5", stats.DroppedByPattern)
+	}
+}
@@ -0,0 +1,298 @@
+// Package redisstream 通过 XADD 将日志写入 Redis Stream，通过 pplogger.Config.Sinks 接入：
+//
+//	w, err := redisstream.NewWriter(redisstream.Config{Addr: "localhost:6379", Stream: "logs"})
+//	config.Sinks = append(config.Sinks, w)
+//
+// 每条日志为 stream 中的一个条目，日志内容放在 Field 字段中
+package redisstream
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultField        = "log"
+	defaultBatchSize    = 100
+	defaultLinger       = 100 * time.Millisecond
+	defaultBufferSize   = 10000
+	defaultTimeout      = 5 * time.Second
+	defaultFlushTimeout = 5 * time.Second
+	minBackoff          = 100 * time.Millisecond
+	maxBackoff          = 30 * time.Second
+)
+
+// Config 是 Redis Stream 输出的配置
+type Config struct {
+	Addr      string // host:port，默认 localhost:6379
+	Username  string
+	Password  string
+	DB        int
+	TLSConfig *tls.Config // 不为空时使用 TLS 连接
+
+	Stream string // stream 的 key
+	Field  string // 日志内容的字段名，默认 log
+	MaxLen int64  // 以 MAXLEN ~ 近似限制 stream 的长度，0 表示不限制
+
+	BatchSize    int           // 每次 pipeline 最多的条数，默认 100
+	Linger       time.Duration // 未攒满一批时最多等待的时间，默认 100ms
+	BufferSize   int           // 内存中最多缓冲的日志条数，默认 10000，缓冲区满时丢弃
+	Timeout      time.Duration // 连接及读写的超时时间，默认 5s
+	FlushTimeout time.Duration // Close 时发送剩余日志最多等待的时间，默认 5s
+}
+
+// Stats 是 Writer 的运行统计
+type Stats struct {
+	Sent    uint64 // 成功写入的日志条数
+	Dropped uint64 // 因缓冲区已满或关闭时未能写入而丢弃的日志条数
+}
+
+// Writer 实现 pplogger.Sink，写入从不阻塞，由后台 goroutine 以 pipeline 批量写入，
+// Redis 不可用时按指数退避重试，期间的日志缓冲在内存中
+type Writer struct {
+	config Config
+	client *redis.Client
+
+	queue chan []byte
+	flush chan chan struct{}
+	quit  chan struct{}
+	done  chan struct{}
+
+	sent      atomic.Uint64
+	dropped   atomic.Uint64
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewWriter 创建 Writer，连接在第一次写入时建立，使用完毕后应调用 Close，交给 Logger 时由 Logger 关闭
+func NewWriter(config Config) (*Writer, error) {
+	if config.Stream == "" {
+		return nil, errors.New("pplogger/redisstream: missing Stream")
+	}
+	if config.MaxLen < 0 || config.BatchSize < 0 || config.Linger < 0 || config.BufferSize < 0 ||
+		config.Timeout < 0 || config.FlushTimeout < 0 {
+		return nil, errors.New("pplogger/redisstream: negative MaxLen, BatchSize, BufferSize or duration")
+	}
+
+	if config.Addr == "" {
+		config.Addr = "localhost:6379"
+	}
+	if config.Field == "" {
+		config.Field = defaultField
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.Linger == 0 {
+		config.Linger = defaultLinger
+	}
+	if config.BufferSize == 0 {
+		config.BufferSize = defaultBufferSize
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.FlushTimeout == 0 {
+		config.FlushTimeout = defaultFlushTimeout
+	}
+
+	w := &Writer{
+		config: config,
+		client: redis.NewClient(&redis.Options{
+			Addr:         config.Addr,
+			Username:     config.Username,
+			Password:     config.Password,
+			DB:           config.DB,
+			TLSConfig:    config.TLSConfig,
+			DialTimeout:  config.Timeout,
+			ReadTimeout:  config.Timeout,
+			WriteTimeout: config.Timeout,
+			MaxRetries:   -1, // 由 Writer 负责重试
+		}),
+		queue: make(chan []byte, config.BufferSize),
+		flush: make(chan chan struct{}),
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go w.run()
+
+	return w, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	msg := append([]byte(nil), bytes.TrimRight(p, "\r\n")...)
+	select {
+	case w.queue <- msg:
+	default:
+		w.dropped.Add(1)
+	}
+
+	return len(p), nil
+}
+
+// Sync 等待此前写入的日志写入 Redis，Redis 不可用时会一直重试直到 Close
+func (w *Writer) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case w.flush <- ack:
+		<-ack
+	case <-w.done:
+	}
+
+	return nil
+}
+
+// Close 最多等待 FlushTimeout 写入剩余的日志后关闭连接
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.quit)
+		<-w.done
+		w.closeErr = w.client.Close()
+	})
+
+	return w.closeErr
+}
+
+// Stats 返回 Writer 的运行统计
+func (w *Writer) Stats() Stats {
+	return Stats{
+		Sent:    w.sent.Load(),
+		Dropped: w.dropped.Load(),
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	var batch [][]byte
+	linger := time.NewTimer(w.config.Linger)
+	linger.Stop()
+
+	for {
+		select {
+		case msg := <-w.queue:
+			if len(batch) == 0 {
+				linger.Reset(w.config.Linger)
+			}
+			batch = append(batch, msg)
+			if len(batch) < w.config.BatchSize {
+				continue
+			}
+		case <-linger.C:
+			if len(batch) == 0 {
+				continue
+			}
+		case ack := <-w.flush:
+			batch = w.drain(batch, time.Time{})
+			close(ack)
+			continue
+		case <-w.quit:
+			w.drain(batch, time.Now().Add(w.config.FlushTimeout))
+			return
+		}
+
+		linger.Stop()
+		if !w.sendRetry(batch, time.Time{}) {
+			w.drain(batch, time.Now().Add(w.config.FlushTimeout))
+			return
+		}
+		batch = nil
+	}
+}
+
+// drain 写入 batch 及缓冲区中已有的日志，deadline 不为零时超时后丢弃剩余日志，
+// 返回值为 Close 打断写入时尚未写入的日志
+func (w *Writer) drain(batch [][]byte, deadline time.Time) [][]byte {
+	for {
+		for len(batch) < w.config.BatchSize {
+			select {
+			case msg := <-w.queue:
+				batch = append(batch, msg)
+				continue
+			default:
+			}
+			break
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if !w.sendRetry(batch, deadline) {
+			if deadline.IsZero() {
+				return batch
+			}
+			w.dropped.Add(uint64(len(batch)))
+			for {
+				select {
+				case <-w.queue:
+					w.dropped.Add(1)
+				default:
+					return nil
+				}
+			}
+		}
+		batch = nil
+	}
+}
+
+// sendRetry 按指数退避重试直到写入成功，deadline 到达或 Close 时放弃并返回 false
+func (w *Writer) sendRetry(batch [][]byte, deadline time.Time) bool {
+	backoff := minBackoff
+	for {
+		if err := w.send(batch, deadline); err == nil {
+			w.sent.Add(uint64(len(batch)))
+			return true
+		}
+
+		wait := backoff
+		var quit <-chan struct{} = w.quit
+		if !deadline.IsZero() {
+			remain := time.Until(deadline)
+			if remain <= 0 {
+				return false
+			}
+			wait = min(wait, remain)
+			quit = nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-quit:
+			timer.Stop()
+			return false
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// send 以一个 pipeline 写入 batch
+func (w *Writer) send(batch [][]byte, deadline time.Time) error {
+	ctx := context.Background()
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	pipe := w.client.Pipeline()
+	for _, msg := range batch {
+		args := &redis.XAddArgs{
+			Stream: w.config.Stream,
+			Values: []interface{}{w.config.Field, msg},
+		}
+		if w.config.MaxLen > 0 {
+			args.MaxLen = w.config.MaxLen
+			args.Approx = true
+		}
+		pipe.XAdd(ctx, args)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
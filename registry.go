@@ -0,0 +1,84 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sync"
+)
+
+var registry = struct {
+	sync.Mutex
+	configs       map[string]Config
+	loggers       map[string]*Logger
+	defaultConfig *Config
+}{
+	configs: make(map[string]Config),
+	loggers: make(map[string]*Logger),
+}
+
+// Register 为 name 注册日志配置，Filename 为空时使用 <name>.log
+func Register(name string, config Config) error {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.configs[name]; ok {
+		return fmt.Errorf("pplogger: logger %q already registered", name)
+	}
+	registry.configs[name] = config
+
+	return nil
+}
+
+// RegisterDefault 注册默认配置模板，GetLogger 获取未注册的 name 时以此模板创建
+func RegisterDefault(config Config) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.defaultConfig = &config
+}
+
+// GetLogger 返回 name 对应的日志，同一个 name 只会创建一次
+func GetLogger(name string) (*zap.Logger, error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if logger, ok := registry.loggers[name]; ok {
+		return logger.Logger, nil
+	}
+
+	config, ok := registry.configs[name]
+	if !ok {
+		if registry.defaultConfig == nil {
+			return nil, fmt.Errorf("pplogger: logger %q not registered", name)
+		}
+		config = *registry.defaultConfig
+	}
+
+	if config.Filename == "" {
+		config.Filename = name + ".log"
+	}
+
+	logger, err := newLogger([]Option{WithConfig(config)})
+	if err != nil {
+		return nil, err
+	}
+	registry.loggers[name] = logger
+
+	return logger.Logger, nil
+}
+
+// CloseLoggers 关闭 GetLogger 创建的全部日志：刷新缓冲、关闭日志文件并停止其后台任务。
+// 注册的配置保留，之后 GetLogger 会重新创建
+func CloseLoggers() error {
+	registry.Lock()
+	defer registry.Unlock()
+
+	var errs []error
+	for name, logger := range registry.loggers {
+		errs = append(errs, logger.Close())
+		delete(registry.loggers, name)
+	}
+
+	return errors.Join(errs...)
+}
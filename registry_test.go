@@ -0,0 +1,37 @@
+package pplogger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCloseLoggers(t *testing.T) {
+	dir := t.TempDir()
+	if err := Register("close-loggers", Config{FileWriter: true, LogPath: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := GetLogger("close-loggers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Info("first")
+	if err := CloseLoggers(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "close-loggers.log")); !strings.Contains(string(b), "first") {
+		t.Fatalf("log file = %q", b)
+	}
+
+	// 注册的配置保留，重新创建日志
+	second, err := GetLogger("close-loggers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == first {
+		t.Fatal("GetLogger returned the closed logger")
+	}
+	_ = CloseLoggers()
+}
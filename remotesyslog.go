@@ -0,0 +1,302 @@
+package pplogger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	SyslogFormatRFC5424 = "rfc5424"
+	SyslogFormatRFC3164 = "rfc3164"
+)
+
+// RemoteSyslogConfig 是远程 syslog 输出的配置
+type RemoteSyslogConfig struct {
+	URL        string      // 例如 udp://syslog.internal:514、tcp://syslog.internal:6514，端口默认 514
+	Format     string      // 消息格式，rfc5424（默认）或 rfc3164
+	Facility   string      // 设施，取值同 SyslogConfig.Facility
+	Tag        string      // APP-NAME/TAG，为空时使用 AppName，AppName 也为空时使用程序名
+	TLSConfig  *tls.Config // tcp 时使用 TLS 连接
+	BufferSize int         // 内存中最多缓冲的日志条数，默认 1000，缓冲区满时丢弃并计入 Stats.Dropped
+}
+
+const (
+	defaultRemoteSyslogBuffer = 1000
+	remoteSyslogMinBackoff    = 100 * time.Millisecond
+	remoteSyslogMaxBackoff    = 30 * time.Second
+	remoteSyslogTimeout       = 5 * time.Second
+	remoteSyslogFlushTimeout  = 2 * time.Second
+)
+
+func (c *RemoteSyslogConfig) validate() []error {
+	var errs []error
+
+	if _, _, err := c.endpoint(); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch c.Format {
+	case "", SyslogFormatRFC5424, SyslogFormatRFC3164:
+	default:
+		errs = append(errs, fmt.Errorf("RemoteSyslog: unknown Format %q", c.Format))
+	}
+
+	if err := validSyslogFacility(c.Facility); err != nil {
+		errs = append(errs, fmt.Errorf("RemoteSyslog: %w", err))
+	}
+
+	if c.BufferSize < 0 {
+		errs = append(errs, fmt.Errorf("RemoteSyslog: negative BufferSize %d", c.BufferSize))
+	}
+
+	return errs
+}
+
+// endpoint 解析 URL，返回网络类型及地址
+func (c *RemoteSyslogConfig) endpoint() (string, string, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return "", "", fmt.Errorf("RemoteSyslog: %w", err)
+	}
+
+	switch u.Scheme {
+	case "udp", "tcp":
+	default:
+		return "", "", fmt.Errorf("RemoteSyslog: unsupported scheme %q in %q, want udp or tcp", u.Scheme, c.URL)
+	}
+
+	if u.Hostname() == "" {
+		return "", "", fmt.Errorf("RemoteSyslog: missing host in %q", c.URL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "514"
+	}
+
+	return u.Scheme, net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// newRemoteSyslogCore 创建远程 syslog 输出，日志编码后放入缓冲区，由后台 goroutine 发送
+func newRemoteSyslogCore(config Config, stats *sinkStats) (zapcore.Core, *remoteSyslogWriter) {
+	c := config.RemoteSyslog
+	network, addr, _ := c.endpoint()
+	facility := syslogFacility(c.Facility)
+	messageConfig := syslogMessageConfig(config)
+	messageConfig.AppName = syslogTag(c.Tag, config.AppName)
+
+	var enc zapcore.Encoder
+	if c.Format == SyslogFormatRFC3164 {
+		enc = newSyslog3164Encoder(newEncoder(messageConfig, "", false), facility, messageConfig.AppName)
+	} else {
+		enc = newSyslog5424Encoder(encoderConfig(messageConfig, Syslog5424Encoding, false), messageConfig, facility)
+	}
+
+	bufferSize := c.BufferSize
+	if bufferSize == 0 {
+		bufferSize = defaultRemoteSyslogBuffer
+	}
+
+	w := &remoteSyslogWriter{
+		network:   network,
+		addr:      addr,
+		tlsConfig: c.TLSConfig,
+		queue:     make(chan []byte, bufferSize),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+		stats:     stats,
+	}
+	go w.run()
+
+	return zapcore.NewCore(enc, w, zapcore.DebugLevel), w
+}
+
+// remoteSyslogWriter 异步发送日志，写入从不阻塞：缓冲区满时丢弃，连接失败时按指数退避重连
+type remoteSyslogWriter struct {
+	network   string
+	addr      string
+	tlsConfig *tls.Config
+	queue     chan []byte
+	quit      chan struct{}
+	done      chan struct{}
+	stats     *sinkStats
+	conn      net.Conn
+	closeOnce sync.Once
+}
+
+func (w *remoteSyslogWriter) Write(p []byte) (int, error) {
+	msg := append([]byte(nil), bytes.TrimRight(p, "\r\n")...)
+	select {
+	case w.queue <- msg:
+	default:
+		w.stats.dropped.Add(1)
+	}
+
+	return len(p), nil
+}
+
+func (w *remoteSyslogWriter) Sync() error {
+	return nil
+}
+
+// Close 停止后台发送，最多等待 remoteSyslogFlushTimeout 发送缓冲区中剩余的日志
+func (w *remoteSyslogWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.quit)
+		select {
+		case <-w.done:
+		case <-time.After(remoteSyslogFlushTimeout):
+		}
+	})
+
+	return nil
+}
+
+func (w *remoteSyslogWriter) run() {
+	defer close(w.done)
+	defer func() {
+		if w.conn != nil {
+			_ = w.conn.Close()
+		}
+	}()
+
+	backoff := remoteSyslogMinBackoff
+	for {
+		var msg []byte
+		select {
+		case msg = <-w.queue:
+		case <-w.quit:
+			w.drain()
+			return
+		}
+
+		for {
+			if err := w.send(msg); err == nil {
+				backoff = remoteSyslogMinBackoff
+				break
+			}
+			w.stats.writeErrors.Add(1)
+			if w.network == "udp" {
+				// udp 不保证送达，失败时直接丢弃
+				break
+			}
+
+			select {
+			case <-time.After(backoff):
+			case <-w.quit:
+				w.stats.dropped.Add(1)
+				w.drain()
+				return
+			}
+			backoff = min(backoff*2, remoteSyslogMaxBackoff)
+		}
+	}
+}
+
+// drain 关闭时尽力发送缓冲区中剩余的日志，失败的直接丢弃
+func (w *remoteSyslogWriter) drain() {
+	for {
+		select {
+		case msg := <-w.queue:
+			if err := w.send(msg); err != nil {
+				w.stats.dropped.Add(1)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// send 发送一条日志，tcp 按 RFC6587 以长度前缀分帧，udp 每条日志一个数据报
+func (w *remoteSyslogWriter) send(msg []byte) error {
+	if w.conn == nil {
+		conn, err := w.dial()
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+
+	_ = w.conn.SetWriteDeadline(time.Now().Add(remoteSyslogTimeout))
+
+	var err error
+	if w.network == "tcp" {
+		frame := make([]byte, 0, len(msg)+8)
+		frame = strconv.AppendInt(frame, int64(len(msg)), 10)
+		frame = append(frame, ' ')
+		frame = append(frame, msg...)
+		_, err = w.conn.Write(frame)
+	} else {
+		_, err = w.conn.Write(msg)
+	}
+
+	if err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	return err
+}
+
+func (w *remoteSyslogWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: remoteSyslogTimeout}
+	if w.network == "tcp" && w.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", w.addr, w.tlsConfig)
+	}
+
+	return dialer.Dial(w.network, w.addr)
+}
+
+// syslog3164Encoder 按 RFC3164 输出：<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG，MSG 由内部 Encoder 编码
+type syslog3164Encoder struct {
+	zapcore.Encoder
+	facility int
+	hostname string
+	tag      string
+}
+
+func newSyslog3164Encoder(enc zapcore.Encoder, facility int, tag string) zapcore.Encoder {
+	return &syslog3164Encoder{
+		Encoder:  enc,
+		facility: facility,
+		hostname: syslogHeaderField(hostname(), 255),
+		tag:      syslogHeaderField(tag, 32) + "[" + strconv.Itoa(os.Getpid()) + "]",
+	}
+}
+
+func (enc *syslog3164Encoder) Clone() zapcore.Encoder {
+	clone := *enc
+	clone.Encoder = enc.Encoder.Clone()
+	return &clone
+}
+
+func (enc *syslog3164Encoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	msg, err := enc.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer msg.Free()
+
+	buf := bufferPool.Get()
+	buf.AppendByte('<')
+	buf.AppendInt(int64(enc.facility*8 + syslogSeverity(ent.Level)))
+	buf.AppendByte('>')
+	buf.AppendTime(ent.Time, time.Stamp)
+	buf.AppendByte(' ')
+	buf.AppendString(enc.hostname)
+	buf.AppendByte(' ')
+	buf.AppendString(enc.tag)
+	buf.AppendString(": ")
+	_, _ = buf.Write(msg.Bytes())
+
+	return buf, nil
+}
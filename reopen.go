@@ -0,0 +1,45 @@
+package pplogger
+
+import (
+	"os"
+	"time"
+)
+
+// reopenCheckInterval 是 ReopenOnRename 检查文件的最小间隔，避免每次写入都 stat
+const reopenCheckInterval = time.Second
+
+// checkReopen 在文件被外部删除或重命名（例如 logrotate 的 create 方式）后重新打开 path，
+// 按 os.SameFile 比较当前打开时记录的文件与 path 指向的文件，调用方需持有 w.mu
+func (w *fileWriter) checkReopen() error {
+	now := w.now()
+	if now.Sub(w.checked) < reopenCheckInterval && !now.Before(w.checked) {
+		return nil
+	}
+	w.checked = now
+
+	info, err := os.Stat(w.path)
+	if err == nil && (w.file == nil || os.SameFile(info, w.file)) {
+		w.file = info
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := w.logger.Close(); err != nil {
+		return err
+	}
+	// 写入空数据以立即重新打开，文件不存在时由 lumberjack 创建
+	if _, err := w.logger.Write(nil); err != nil {
+		return err
+	}
+	w.file, w.size = nil, 0
+	if info, err := os.Stat(w.path); err == nil {
+		w.file, w.size = info, info.Size()
+	}
+	if w.link != nil {
+		w.link.update(w.path)
+	}
+
+	return nil
+}
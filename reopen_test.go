@@ -0,0 +1,104 @@
+package pplogger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReopenOnRename(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	h, err := acquireFileWriter(Config{LogPath: dir, Filename: "app.log", ReopenOnRename: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	now, advance := fakeClock(time.Now())
+	setClock(h, now)
+
+	write := func(line string) {
+		t.Helper()
+		if _, err := h.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	write("before")
+	// logrotate 的 create 方式：先移走文件，再由程序在原路径创建新文件
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	// 检查结果缓存 reopenCheckInterval，期间仍写入移走的文件
+	write("cached")
+	advance(reopenCheckInterval)
+	write("after rename")
+	if got := read(path + ".1"); got != "before\ncached\n" {
+		t.Errorf("renamed file = %q", got)
+	}
+	if got := read(path); got != "after rename\n" {
+		t.Errorf("reopened file = %q", got)
+	}
+
+	// 文件被删除后同样重新创建
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	advance(reopenCheckInterval)
+	write("after remove")
+	if got := read(path); got != "after remove\n" {
+		t.Errorf("recreated file = %q", got)
+	}
+
+	// 切割产生的新文件不会被误判为被移走
+	if err := h.forceRotate(); err != nil {
+		t.Fatal(err)
+	}
+	advance(reopenCheckInterval)
+	write("after rotate")
+	advance(reopenCheckInterval)
+	write("still open")
+	if got := read(path); got != "after rotate\nstill open\n" {
+		t.Errorf("file after rotation = %q", got)
+	}
+}
+
+func TestReopenOnRenameLogger(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger, err := NewLogger(Config{FileWriter: true, LogPath: dir, Filename: "app.log", ReopenOnRename: true, SymlinkName: "current.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Info("before")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(reopenCheckInterval)
+	logger.Info("after")
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "after") || strings.Contains(string(b), "before") {
+		t.Errorf("reopened file = %q", b)
+	}
+	// 重新打开后链接指向新文件
+	link, err := os.ReadFile(filepath.Join(dir, "current.log"))
+	if err != nil || string(link) != string(b) {
+		t.Errorf("current.log = %q, %v", link, err)
+	}
+}
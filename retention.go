@@ -0,0 +1,253 @@
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// byteUnits 是 parseByteSize 支持的单位，均按 1024 进制，与 MaxSize 的 M 一致
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"TB", 1 << 40}, {"T", 1 << 40},
+	{"GB", 1 << 30}, {"G", 1 << 30},
+	{"MB", 1 << 20}, {"M", 1 << 20},
+	{"KB", 1 << 10}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// parseByteSize 解析 "2GB"、"500MB"、"1.5G" 或字节数，不区分大小写，为空时返回 0
+func parseByteSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	if str == "" {
+		return 0, nil
+	}
+
+	unit := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str, unit = strings.TrimSpace(strings.TrimSuffix(str, u.suffix)), u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q, want bytes or a value such as 500MB, 2GB", s)
+	}
+
+	return int64(n * float64(unit)), nil
+}
+
+// setOnRemove 设置 MaxTotalSize 删除备份后的回调，共享同一文件的多个 Logger 以第一次设置的为准
+func (h *fileHandle) setOnRemove(onRemove func(path string, size int64)) {
+	h.writer.mu.Lock()
+	defer h.writer.mu.Unlock()
+
+	if h.writer.onRemove == nil {
+		h.writer.onRemove = onRemove
+	}
+}
+
+// setJanitor 将该文件交给 Factory 的 retentionJanitor 管理，切割后触发清理
+func (h *fileHandle) setJanitor(j *retentionJanitor, logger *Logger) {
+	h.writer.mu.Lock()
+	if h.writer.janitor == nil {
+		h.writer.janitor = j
+	}
+	h.writer.mu.Unlock()
+
+	j.add(h.writer, logger)
+}
+
+// removeHook 通过 logger 自身输出 MaxTotalSize 删除的备份
+func removeHook(logger *Logger) func(string, int64) {
+	return func(path string, size int64) {
+		logger.Info("pplogger: removed log backup to stay under MaxTotalSize", zap.String("path", path), zap.Int64("size", size))
+	}
+}
+
+// enforceTotalSize 统计当前文件及其全部备份（lumberjack 或 FilenamePattern 格式，包括压缩后的）的大小，
+// 超过 MaxTotalSize 时按修改时间从旧到新删除备份，不会删除当前文件。其他清理可能同时删除或压缩备份，消失的文件直接跳过
+func (w *fileWriter) enforceTotalSize(onRemove func(path string, size int64)) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	dir := filepath.Dir(w.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var total int64
+	if info, err := os.Stat(w.path); err == nil {
+		total = info.Size()
+	}
+
+	backups := w.backups()
+	type backup struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []backup
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if path == w.path || !backups[trimCompressExt(path)] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		total += info.Size()
+		files = append(files, backup{path: path, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	for _, f := range files {
+		if total <= w.maxTotalSize {
+			return
+		}
+		total -= f.size
+		// 已被其他清理删除时跳过
+		if err := os.Remove(f.path); err != nil {
+			continue
+		}
+		if onRemove != nil {
+			onRemove(f.path, f.size)
+		}
+	}
+}
+
+// retentionJanitor 在多个日志文件间统一清理备份，见 FactoryRetention
+type retentionJanitor struct {
+	maxBackups   int
+	maxTotalSize int64
+	dryRun       bool
+
+	mu      sync.Mutex              // 保护 writers
+	writers map[*fileWriter]*Logger // 管理的文件及用于输出删除记录的 Logger
+	runMu   sync.Mutex              // 清理串行执行
+}
+
+func newRetentionJanitor(maxBackups int, maxTotalSize int64, dryRun bool) *retentionJanitor {
+	return &retentionJanitor{
+		maxBackups:   maxBackups,
+		maxTotalSize: maxTotalSize,
+		dryRun:       dryRun,
+		writers:      make(map[*fileWriter]*Logger),
+	}
+}
+
+// manage 将 logger 的日志文件交给 j 管理，随即在后台检查一次
+func (j *retentionJanitor) manage(logger *Logger) {
+	for _, c := range logger.closers {
+		if m, ok := c.(interface {
+			setJanitor(*retentionJanitor, *Logger)
+		}); ok {
+			m.setJanitor(j, logger)
+		}
+	}
+
+	go j.run()
+}
+
+func (j *retentionJanitor) add(w *fileWriter, logger *Logger) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, ok := j.writers[w]; !ok {
+		j.writers[w] = logger
+	}
+}
+
+// run 统计全部文件的备份，超过 maxBackups 个或总大小超过 maxTotalSize 时从最旧的开始删除
+func (j *retentionJanitor) run() {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	j.mu.Lock()
+	writers := make(map[*fileWriter]*Logger, len(j.writers))
+	for w, logger := range j.writers {
+		writers[w] = logger
+	}
+	j.mu.Unlock()
+
+	type backup struct {
+		path    string
+		size    int64
+		modTime time.Time
+		w       *fileWriter
+		logger  *Logger
+	}
+	var files []backup
+	var total int64
+	seen := make(map[string]bool)
+	for w, logger := range writers {
+		if seen[w.path] {
+			continue
+		}
+		seen[w.path] = true
+		if info, err := os.Stat(w.path); err == nil {
+			total += info.Size()
+		}
+
+		dir := filepath.Dir(w.path)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		backups := w.backups()
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if path == w.path || !backups[trimCompressExt(path)] {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			total += info.Size()
+			files = append(files, backup{path: path, size: info.Size(), modTime: info.ModTime(), w: w, logger: logger})
+		}
+	}
+	sort.Slice(files, func(a, b int) bool {
+		return files[a].modTime.Before(files[b].modTime)
+	})
+
+	count := len(files)
+	for _, f := range files {
+		if (j.maxBackups == 0 || count <= j.maxBackups) && (j.maxTotalSize == 0 || total <= j.maxTotalSize) {
+			return
+		}
+		count--
+		total -= f.size
+		if j.dryRun {
+			f.logger.Info("pplogger: would remove log backup to stay under factory retention (dry run)", zap.String("path", f.path), zap.Int64("size", f.size))
+			continue
+		}
+		if !j.remove(f.w, f.path) {
+			continue
+		}
+		f.logger.Info("pplogger: removed log backup to stay under factory retention", zap.String("path", f.path), zap.Int64("size", f.size))
+	}
+}
+
+// remove 删除 w 的备份 path，与 mill 及压缩互斥，已被其他清理删除时返回 false
+func (j *retentionJanitor) remove(w *fileWriter, path string) bool {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	return os.Remove(path) == nil
+}
@@ -0,0 +1,125 @@
+package pplogger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for s, want := range map[string]int64{
+		"":      0,
+		"1024":  1024,
+		"2GB":   2 << 30,
+		"500mb": 500 << 20,
+		"1.5K":  1536,
+		" 3 M ": 3 << 20,
+	} {
+		if got, err := parseByteSize(s); err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"abc", "-1", "1XB"} {
+		if _, err := parseByteSize(s); err == nil {
+			t.Errorf("parseByteSize(%q) succeeded", s)
+		}
+	}
+}
+
+// writeAged 写入 size 字节的文件并把修改时间设为 age 之前
+func writeAged(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnforceTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	h, err := acquireFileWriter(Config{LogPath: dir, Filename: "app.log", MaxTotalSize: "1K"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	if _, err := h.Write([]byte(strings.Repeat("x", 299) + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{
+		"app-2024-06-15T01-00-00.000.log",
+		"app-2024-06-15T02-00-00.000.log.gz",
+		"app-2024-06-15T03-00-00.000.log",
+		"app-2024-06-15T04-00-00.000.log",
+	} {
+		writeAged(t, filepath.Join(dir, name), 300, time.Duration(4-i)*time.Hour)
+	}
+	// 不属于该日志文件的文件不统计也不删除
+	writeAged(t, filepath.Join(dir, "other.log"), 5000, 24*time.Hour)
+
+	var removed []string
+	h.writer.enforceTotalSize(func(path string, size int64) {
+		removed = append(removed, filepath.Base(path))
+		if size != 300 {
+			t.Errorf("removed %s with size %d", path, size)
+		}
+		// 模拟其他清理同时删除了下一个备份
+		if len(removed) == 1 {
+			_ = os.Remove(filepath.Join(dir, "app-2024-06-15T02-00-00.000.log.gz"))
+		}
+	})
+
+	// 共 1500 字节，从最旧的删到不超过 1K：删除 01、02（已被删除，跳过），保留 03、04 与当前文件
+	if want := []string{"app-2024-06-15T01-00-00.000.log"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed %v, want %v", removed, want)
+	}
+	var names []string
+	for name := range readDir(t, dir) {
+		names = append(names, name)
+	}
+	for _, name := range []string{"app.log", "other.log", "app-2024-06-15T03-00-00.000.log", "app-2024-06-15T04-00-00.000.log"} {
+		if !fileExists(filepath.Join(dir, name)) {
+			t.Errorf("%s was removed, remaining %v", name, names)
+		}
+	}
+}
+
+func TestMaxTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(Config{FileWriter: true, LogPath: dir, Filename: "app.log", MaxBackups: 100, MaxTotalSize: "1K", Encoding: JSONEncoding})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := strings.Repeat("x", 300)
+	for i := 0; i < 6; i++ {
+		logger.Info(message)
+		if err := logger.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 删除记录通过 Logger 自身输出到当前文件
+	eventually(t, "backups to fit in MaxTotalSize", func() bool {
+		var backups int64
+		for name, content := range readDir(t, dir) {
+			if name != "app.log" {
+				backups += int64(len(content))
+			}
+		}
+		b, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+		return backups <= 1024 && strings.Contains(string(b), "MaxTotalSize")
+	})
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (Config{StdoutWriter: true, MaxTotalSize: "lots"}).Validate(); err == nil {
+		t.Error("Validate accepted an invalid MaxTotalSize")
+	}
+}
@@ -0,0 +1,85 @@
+package pplogger
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// ringBuffer 保存最近写入的 capacity 条日志，写入无锁，可与写入并发读取
+type ringBuffer struct {
+	slots []atomic.Pointer[ringEntry]
+	next  atomic.Uint64
+}
+
+// ringEntry 记录日志的序号，读取时据此跳过读取过程中被覆盖的位置
+type ringEntry struct {
+	seq uint64
+	msg string
+}
+
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{slots: make([]atomic.Pointer[ringEntry], capacity)}
+}
+
+func (r *ringBuffer) Write(p []byte) (int, error) {
+	seq := r.next.Add(1) - 1
+	r.slots[seq%uint64(len(r.slots))].Store(&ringEntry{seq: seq, msg: string(p)})
+
+	return len(p), nil
+}
+
+func (r *ringBuffer) Sync() error {
+	return nil
+}
+
+// entries 按写入顺序返回当前保存的日志
+func (r *ringBuffer) entries() []string {
+	end := r.next.Load()
+	start := end - min(end, uint64(len(r.slots)))
+
+	msgs := make([]string, 0, end-start)
+	for seq := start; seq < end; seq++ {
+		entry := r.slots[seq%uint64(len(r.slots))].Load()
+		// 尚未写完或已被更新的日志覆盖
+		if entry == nil || entry.seq != seq {
+			continue
+		}
+		msgs = append(msgs, entry.msg)
+	}
+
+	return msgs
+}
+
+var errNoRingBuffer = errors.New("pplogger: RingBufferSize is not set")
+
+// Entries 返回内存中保存的最近 RingBufferSize 条日志，不含行尾，未设置 RingBufferSize 时返回 nil
+func (l *Logger) Entries() []string {
+	if l.ring == nil {
+		return nil
+	}
+
+	msgs := l.ring.entries()
+	for i, msg := range msgs {
+		msgs[i] = strings.TrimRight(msg, "\r\n")
+	}
+
+	return msgs
+}
+
+// Dump 将内存中保存的最近 RingBufferSize 条日志按原格式写到 w，可与日志写入并发调用，
+// 例如在 /debug 接口或崩溃处理中使用
+func (l *Logger) Dump(w io.Writer) error {
+	if l.ring == nil {
+		return errNoRingBuffer
+	}
+
+	for _, msg := range l.ring.entries() {
+		if _, err := io.WriteString(w, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
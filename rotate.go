@@ -0,0 +1,300 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Config.RotateInterval 的取值
+const (
+	RotateHourly = "hourly"
+	RotateDaily  = "daily"
+)
+
+const (
+	lumberjackTimeFormat  = "2006-01-02T15-04-05.000"
+	rotateCompressTimeout = time.Minute
+	rotatePollInterval    = 100 * time.Millisecond
+)
+
+// rotate 执行会产生备份文件的操作，完成后重置 size 并在新文件开头写入 Banner，设置了 onRotate 时对比前后的备份文件找出新产生的
+func (w *fileWriter) rotate(fn func() error) error {
+	if w.link != nil {
+		defer w.link.update(w.path)
+	}
+	if w.reopen {
+		// 切割打开了新文件，记录其 FileInfo，调用方随后会更新 size
+		defer func() {
+			if info, err := os.Stat(w.path); err == nil {
+				w.file = info
+			}
+		}()
+	}
+	if w.ownMill() {
+		defer func(now time.Time) {
+			go w.mill(now)
+		}(w.now())
+	}
+	if w.maxTotalSize > 0 {
+		defer func(onRemove func(string, int64)) {
+			go w.enforceTotalSize(onRemove)
+		}(w.onRemove)
+	}
+	if w.janitor != nil {
+		defer func(j *retentionJanitor) {
+			go j.run()
+		}(w.janitor)
+	}
+
+	var before map[string]bool
+	if w.onRotate != nil {
+		before = w.backups()
+	}
+	err := fn()
+	w.size = 0
+	if err == nil && w.banner != nil {
+		n, _ := w.logger.Write(w.banner())
+		w.size = int64(n)
+	}
+	if w.onRotate != nil {
+		for backup := range w.backups() {
+			if !before[backup] {
+				go w.rotated(backup, w.onRotate)
+			}
+		}
+	}
+
+	return err
+}
+
+// rotator 由可以手动切割的文件输出实现
+type rotator interface {
+	forceRotate() error
+}
+
+// Rotate 立即切割 Logger 的全部日志文件，包括 Error、路由、合并及镜像文件，可与写入并发调用，
+// 切割前后的日志不会交错或丢失。可配合外部 logrotate，或在维护前后得到干净的文件边界，FileSyncer 不会切割
+func (l *Logger) Rotate() error {
+	var errs []error
+	for _, c := range l.closers {
+		if r, ok := c.(rotator); ok {
+			errs = append(errs, r.forceRotate())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// forceRotate 立即切割，设置了 FilenamePattern 时备份按其命名
+func (w *fileWriter) forceRotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.released {
+		return nil
+	}
+
+	return w.rotate(w.rotateNow())
+}
+
+// rotateNow 返回按大小或手动切割的操作：设置了 FilenamePattern 时按其命名，否则按 lumberjack 的格式以当前时间命名，
+// 使用 WriterFactory 时交给其 Rotate
+func (w *fileWriter) rotateNow() func() error {
+	switch {
+	case w.pattern != nil:
+		return func() error { return w.rotateTo(w.patternBackup(w.period)) }
+	case w.lj != nil:
+		return func() error { return w.rotateTo(w.backupName(w.period, w.now())) }
+	}
+
+	return w.logger.Rotate
+}
+
+// backupName 返回按时间切割时备份文件的路径。设置了 FilenamePattern 时按其命名，时间为文件所属周期的开始时间 period；
+// 否则沿用 lumberjack 的命名，时间为切割时间 boundary，按 LocalTime 使用 UTC 或本地时间，以便 MaxBackups、MaxAge 及压缩照常生效。
+// 同一毫秒内多次切割时依次加 1 毫秒，不会像 lumberjack 自身切割那样覆盖之前的备份
+func (w *fileWriter) backupName(period, boundary time.Time) string {
+	if w.pattern != nil {
+		return w.patternBackup(period)
+	}
+
+	if w.lj.LocalTime {
+		boundary = boundary.Local()
+	} else {
+		boundary = boundary.UTC()
+	}
+
+	ext := filepath.Ext(w.path)
+	for {
+		name := strings.TrimSuffix(w.path, ext) + "-" + boundary.Format(lumberjackTimeFormat) + ext
+		if !fileExists(name) && !fileExists(name+gzipExt) && !fileExists(name+zstdExt) {
+			return name
+		}
+		boundary = boundary.Add(time.Millisecond)
+	}
+}
+
+// rotateTo 关闭当前文件并重命名为 name，随即创建新文件，tail -F 及 SymlinkName 能尽快切换。
+// lumberjack 在重新打开文件时清理及压缩其格式的备份，FilenamePattern 格式的备份由 mill 清理
+func (w *fileWriter) rotateTo(name string) error {
+	if err := w.logger.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(w.path, name); err != nil {
+		return fmt.Errorf("pplogger: rotate log file: %w", err)
+	}
+	if _, err := w.logger.Write(nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseRotateInterval 解析 Config.RotateInterval，为空时返回 0。
+// 时长需能整除 24h 且不小于 1 分钟，以便每天的切割时间固定
+func parseRotateInterval(s string) (time.Duration, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return 0, nil
+	case RotateHourly:
+		return time.Hour, nil
+	case RotateDaily:
+		return 24 * time.Hour, nil
+	}
+
+	interval, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("unknown RotateInterval %q, want %s, %s or a duration such as 6h", s, RotateHourly, RotateDaily)
+	}
+	if interval < time.Minute || 24*time.Hour%interval != 0 {
+		return 0, fmt.Errorf("invalid RotateInterval %q, want at least 1m and a divisor of 24h", s)
+	}
+
+	return interval, nil
+}
+
+// periodStart 返回 t 所属周期的开始时间，周期从 loc 中当天零点起按 interval 对齐（例如整点），
+// 不同实例的文件边界一致
+func (w *fileWriter) periodStart(t time.Time) time.Time {
+	t = t.In(w.loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, w.loc)
+
+	return midnight.Add(t.Sub(midnight) / w.interval * w.interval)
+}
+
+// nextRotation 返回 t 之后的下一个切割时间，夏令时切换的当天最后一个周期截止到次日零点
+func (w *fileWriter) nextRotation(t time.Time) time.Time {
+	start := w.periodStart(t)
+	tomorrow := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, w.loc)
+
+	if next := start.Add(w.interval); next.Before(tomorrow) {
+		return next
+	}
+
+	return tomorrow
+}
+
+// timeLocation 返回 TimeZone 对应的时区，为空或无效时使用本地时区
+func timeLocation(timeZone string) *time.Location {
+	if timeZone != "" {
+		if loc, err := time.LoadLocation(timeZone); err == nil {
+			return loc
+		}
+	}
+
+	return time.Local
+}
+
+// maxSize 返回 lumberjack 切割的字节数，与 lumberjack 相同，MaxSize 为 0 时为 100M。
+// 使用 WriterFactory 时按大小切割交给其实现，返回最大值
+func (w *fileWriter) maxSize() int64 {
+	if w.lj == nil {
+		return math.MaxInt64
+	}
+	if w.lj.MaxSize == 0 {
+		return 100 << 20
+	}
+
+	return int64(w.lj.MaxSize) << 20
+}
+
+// backups 返回当前的备份文件，lumberjack 的备份命名为 <name>-<时间><ext>，压缩后追加 .gz 或 .zst，另外包括按 FilenamePattern 命名的。
+// 时间部分需能解析，避免把同目录下 app-error.log 之类的其他日志文件当作备份。
+// 返回的路径均去掉压缩扩展名，避免之前的备份恰好压缩完成时被当作新产生的
+func (w *fileWriter) backups() map[string]bool {
+	dir := filepath.Dir(w.path)
+	ext := filepath.Ext(w.path)
+	prefix := strings.TrimSuffix(filepath.Base(w.path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	backups := make(map[string]bool)
+	for _, entry := range entries {
+		name := trimCompressExt(entry.Name())
+		if entry.IsDir() {
+			continue
+		}
+		if w.isLumberjackBackup(name, prefix, ext) || (w.pattern != nil && w.pattern.match.MatchString(entry.Name())) {
+			backups[filepath.Join(dir, name)] = true
+		}
+	}
+
+	return backups
+}
+
+// isLumberjackBackup 返回 name 是否为 lumberjack 格式的备份文件名
+func (w *fileWriter) isLumberjackBackup(name, prefix, ext string) bool {
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+		return false
+	}
+	_, err := time.Parse(lumberjackTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+
+	return err == nil
+}
+
+// rotated 在后台等待备份文件压缩完成后调用 onRotate，传入最终的文件路径
+func (w *fileWriter) rotated(backup string, onRotate func(string)) {
+	if w.compression != "" {
+		// 压缩时先写压缩文件再删除原文件
+		deadline := time.Now().Add(w.compressDelay + rotateCompressTimeout)
+		for time.Now().Before(deadline) {
+			if _, err := os.Stat(backup); errors.Is(err, os.ErrNotExist) {
+				break
+			}
+			time.Sleep(rotatePollInterval)
+		}
+		for _, ext := range []string{gzipExt, zstdExt} {
+			if _, err := os.Stat(backup + ext); err == nil {
+				backup += ext
+				break
+			}
+		}
+	}
+
+	onRotate(backup)
+}
+
+// rotateHook 包装 Config.OnRotate，回调的错误及 panic 通过 logger 自身输出
+func rotateHook(logger *Logger, onRotate func(path string) error) func(string) {
+	return func(path string) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("pplogger: OnRotate panic", zap.String("path", path), zap.Any("panic", r))
+			}
+		}()
+
+		if err := onRotate(path); err != nil {
+			logger.Error("pplogger: OnRotate failed", zap.String("path", path), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventually 在 5 秒内反复检查 cond，超时后报错
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestOnRotate(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		compress bool
+		hook     func(path string) error
+		wantLog  string
+	}{
+		{"plain", false, func(string) error { return nil }, ""},
+		{"compressed", true, func(string) error { return nil }, ""},
+		{"error", false, func(string) error { return errors.New("upload failed") }, "pplogger: OnRotate failed"},
+		{"panic", false, func(string) error { panic("boom") }, "pplogger: OnRotate panic"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			calls := make(chan string, 10)
+			logger, err := NewLogger(Config{
+				FileWriter: true,
+				LogPath:    dir,
+				Filename:   "app.log",
+				MaxSize:    1,
+				Compress:   tt.compress,
+				OnRotate: func(path string) error {
+					// 回调时备份文件已存在，压缩时为压缩后的文件
+					if _, err := os.Stat(path); err != nil {
+						t.Errorf("OnRotate called with missing file: %v", err)
+					}
+					calls <- path
+					return tt.hook(path)
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer logger.Close()
+
+			big := strings.Repeat("x", 400<<10)
+			for i := 0; i < 3; i++ {
+				logger.Info(big)
+			}
+			select {
+			case path := <-calls:
+				if filepath.Dir(path) != dir || strings.HasSuffix(path, ".gz") != tt.compress {
+					t.Errorf("OnRotate path = %q", path)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("OnRotate was not called")
+			}
+
+			if tt.wantLog != "" {
+				eventually(t, tt.wantLog, func() bool {
+					b, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+					return strings.Contains(string(b), tt.wantLog)
+				})
+			}
+			select {
+			case path := <-calls:
+				t.Errorf("unexpected second OnRotate call with %q", path)
+			default:
+			}
+		})
+	}
+}
+
+func TestOnRotateForced(t *testing.T) {
+	dir := t.TempDir()
+	calls := make(chan string, 1)
+	logger, err := NewLogger(Config{FileWriter: true, LogPath: dir, Filename: "app.log", OnRotate: func(path string) error {
+		calls <- path
+		return nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Info("before")
+	if err := logger.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case path := <-calls:
+		if b, err := os.ReadFile(path); err != nil || !strings.Contains(string(b), "before") {
+			t.Errorf("backup %q = %q, %v", path, b, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnRotate was not called after Rotate")
+	}
+}
+
+// setClock 替换 h 的时钟，按时间切割时按新时钟重新计算当前周期
+func setClock(h *fileHandle, now func() time.Time) {
+	w := h.writer
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.now = now
+	if w.interval > 0 {
+		w.period, w.next = w.periodStart(now()), w.nextRotation(now())
+	}
+}
+
+// readDir 返回 dir 中各文件的内容
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name()] = string(b)
+	}
+
+	return files
+}
+
+func TestRotateDaily(t *testing.T) {
+	dir := t.TempDir()
+	h, err := acquireFileWriter(Config{LogPath: dir, Filename: "app.log", RotateInterval: RotateDaily, TimeZone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now, advance := fakeClock(time.Date(2024, 6, 15, 23, 59, 0, 0, time.UTC))
+	setClock(h, now)
+
+	for _, step := range []struct {
+		advance time.Duration
+		line    string
+	}{
+		{0, "day1\n"},
+		{2 * time.Minute, "day2\n"},
+		// 空闲两天后只切割一次，备份以上一周期结束的零点命名
+		{48 * time.Hour, "day4\n"},
+		{time.Hour, "day4b\n"},
+	} {
+		advance(step.advance)
+		if _, err := h.Write([]byte(step.line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"app-2024-06-16T00-00-00.000.log": "day1\n",
+		"app-2024-06-17T00-00-00.000.log": "day2\n",
+		"app.log":                         "day4\nday4b\n",
+	}
+	if got := readDir(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %v, want %v", got, want)
+	}
+}
+
+func TestRotateDailyAndMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	h, err := acquireFileWriter(Config{LogPath: dir, Filename: "app.log", RotateInterval: RotateDaily, TimeZone: "UTC", MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now, advance := fakeClock(time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC))
+	setClock(h, now)
+
+	// 先达到 MaxSize 时在当天切割，跨天时再按时间切割
+	line := []byte(strings.Repeat("x", 600<<10) + "\n")
+	for i := 0; i < 2; i++ {
+		if _, err := h.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	advance(24 * time.Hour)
+	if _, err := h.Write([]byte("next day\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readDir(t, dir)
+	if len(files) != 3 || files["app.log"] != "next day\n" || files["app-2024-06-16T00-00-00.000.log"] != string(line) {
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		t.Errorf("unexpected files %v", names)
+	}
+}
+
+func TestRotateDailyMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	h, err := acquireFileWriter(Config{LogPath: dir, Filename: "app.log", RotateInterval: RotateDaily, TimeZone: "UTC", MaxAge: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	now, advance := fakeClock(time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC))
+	setClock(h, now)
+
+	// MaxAge 按备份文件名中的切割时间清理，2024-06-16 的备份早已过期
+	if _, err := h.Write([]byte("old\n")); err != nil {
+		t.Fatal(err)
+	}
+	advance(24 * time.Hour)
+	if _, err := h.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the expired backup to be removed", func() bool {
+		return !fileExists(filepath.Join(dir, "app-2024-06-16T00-00-00.000.log"))
+	})
+}
+
+func TestRotateDailyStaleFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("yesterday\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已有的文件属于之前的周期，首次写入即切割
+	h, err := acquireFileWriter(Config{LogPath: dir, Filename: "app.log", RotateInterval: RotateDaily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Write([]byte("today\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readDir(t, dir)
+	if len(files) != 2 || files["app.log"] != "today\n" {
+		t.Errorf("unexpected files %v", files)
+	}
+}
+
+func TestParseRotateInterval(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"":       0,
+		"hourly": time.Hour,
+		"daily":  24 * time.Hour,
+		"Daily":  24 * time.Hour,
+		"1h":     time.Hour,
+		"6h":     6 * time.Hour,
+		"24h":    24 * time.Hour,
+		"15m":    15 * time.Minute,
+	} {
+		if got, err := parseRotateInterval(s); err != nil || got != want {
+			t.Errorf("parseRotateInterval(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"7h", "30s", "48h", "weekly", "bad"} {
+		if _, err := parseRotateInterval(s); err == nil {
+			t.Errorf("parseRotateInterval(%q) succeeded", s)
+		}
+	}
+}
+
+func TestNextRotation(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	for _, tt := range []struct {
+		interval time.Duration
+		now      time.Time
+		want     time.Time
+	}{
+		{time.Hour, time.Date(2024, 6, 15, 10, 30, 0, 0, shanghai), time.Date(2024, 6, 15, 11, 0, 0, 0, shanghai)},
+		{time.Hour, time.Date(2024, 6, 15, 23, 0, 0, 0, shanghai), time.Date(2024, 6, 16, 0, 0, 0, 0, shanghai)},
+		{6 * time.Hour, time.Date(2024, 6, 15, 13, 0, 0, 0, shanghai), time.Date(2024, 6, 15, 18, 0, 0, 0, shanghai)},
+		{15 * time.Minute, time.Date(2024, 6, 15, 13, 59, 59, 0, shanghai), time.Date(2024, 6, 15, 14, 0, 0, 0, shanghai)},
+		{24 * time.Hour, time.Date(2024, 6, 15, 0, 0, 0, 0, shanghai), time.Date(2024, 6, 16, 0, 0, 0, 0, shanghai)},
+		// 夏令时开始当天只有 23 小时，最后一个周期截止到次日零点
+		{6 * time.Hour, time.Date(2024, 3, 10, 20, 0, 0, 0, newYork), time.Date(2024, 3, 11, 0, 0, 0, 0, newYork)},
+	} {
+		w := &fileWriter{interval: tt.interval, loc: tt.now.Location()}
+		if got := w.nextRotation(tt.now); !got.Equal(tt.want) {
+			t.Errorf("nextRotation(%v) with %v = %v, want %v", tt.now, tt.interval, got, tt.want)
+		}
+	}
+}
+
+func TestRotateHourlyConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	h, err := acquireFileWriter(Config{LogPath: dir, Filename: "app.log", RotateInterval: "1h", TimeZone: "Asia/Shanghai"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	now := time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC)
+	setClock(h, func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	})
+
+	// 并发写入时推进时钟跨过多个整点，每行都完整地落在某一个文件中
+	const writers, lines = 8, 500
+	record := strings.Repeat("x", 32)
+	var wg sync.WaitGroup
+	for g := 0; g < writers; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				_, _ = h.Write([]byte(fmt.Sprintf("g%d-%d-%s\n", g, i, record)))
+			}
+		}(g)
+	}
+	for i := 0; i < 4; i++ {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		now = now.Add(time.Hour)
+		mu.Unlock()
+	}
+	wg.Wait()
+	mu.Lock()
+	now = now.Add(time.Hour)
+	mu.Unlock()
+	if _, err := h.Write([]byte("last\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	total := 0
+	for name, content := range readDir(t, dir) {
+		if name != "app.log" && !strings.HasSuffix(name, "-00-00.000.log") {
+			t.Errorf("backup %q is not named after an hour boundary", name)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+			if line != "last" && !strings.HasSuffix(line, "-"+record) {
+				t.Errorf("torn line %q in %s", line, name)
+			}
+			total++
+		}
+	}
+	if total != writers*lines+1 {
+		t.Errorf("got %d lines, want %d", total, writers*lines+1)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "app.log")); string(b) != "last\n" {
+		t.Errorf("app.log = %q", b)
+	}
+}
@@ -0,0 +1,72 @@
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+// SamplingConfig 是日志采样的配置，语义同 zapcore.NewSamplerWithOptions：每个 Tick 内等级及消息都相同的日志，
+// 先输出 Initial 条，之后每 Thereafter 条输出一条，其余丢弃。Initial、Thereafter 都不为 0 时才采样
+type SamplingConfig struct {
+	Initial       int
+	Thereafter    int
+	Tick          time.Duration // 计数周期，默认 1 秒
+	IncludeErrors bool          // Error 及以上等级默认不采样，为 true 时同样采样
+}
+
+func (c *SamplingConfig) validate() []error {
+	var errs []error
+
+	if c.Initial < 0 || c.Thereafter < 0 {
+		errs = append(errs, fmt.Errorf("Sampling: negative Initial %d or Thereafter %d", c.Initial, c.Thereafter))
+	}
+
+	if c.Tick < 0 {
+		errs = append(errs, fmt.Errorf("Sampling: negative Tick %s", c.Tick))
+	}
+
+	return errs
+}
+
+func (c *SamplingConfig) enabled() bool {
+	return c != nil && c.Initial > 0 && c.Thereafter > 0
+}
+
+// newSamplingCore 按 c 对 core 采样，丢弃的条数计入 stats
+func newSamplingCore(core zapcore.Core, c *SamplingConfig, stats *sinkStats) zapcore.Core {
+	tick := c.Tick
+	if tick == 0 {
+		tick = time.Second
+	}
+
+	sampled := zapcore.NewSamplerWithOptions(core, tick, c.Initial, c.Thereafter,
+		zapcore.SamplerHook(func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+			if dec&zapcore.LogDropped != 0 {
+				stats.sampled.Add(1)
+			}
+		}))
+	if c.IncludeErrors {
+		return sampled
+	}
+
+	return &errorExemptCore{Core: core, sampled: sampled}
+}
+
+// errorExemptCore 只对 Error 以下的日志采样，Error 及以上直接交给 Core
+type errorExemptCore struct {
+	zapcore.Core
+	sampled zapcore.Core
+}
+
+func (c *errorExemptCore) With(fields []zapcore.Field) zapcore.Core {
+	return &errorExemptCore{Core: c.Core.With(fields), sampled: c.sampled.With(fields)}
+}
+
+func (c *errorExemptCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level >= zapcore.ErrorLevel {
+		return c.Core.Check(ent, ce)
+	}
+
+	return c.sampled.Check(ent, ce)
+}
@@ -0,0 +1,246 @@
+// Package sentry 将 Error 及以上等级的日志上报到 Sentry，通过 pplogger.Config.Cores 接入：
+//
+//	core, err := sentry.NewCore(sentry.Config{DSN: "https://key@sentry.example.com/1"})
+//	config.Cores = append(config.Cores, core)
+//
+// 日志的字段作为名为 fields 的 context 上报，Tags 中列出的字段作为 tag，堆栈解析为 Sentry 的 frame。
+// Panic 与 Fatal 在写入时同步刷新，保证进程退出前已发送
+package sentry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/getsentry/sentry-go"
+	"github.com/piaoyunsoft/pplogger"
+	"go.uber.org/zap/zapcore"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultFlushTimeout = 2 * time.Second
+
+// Config 是 Sentry 输出的配置
+type Config struct {
+	DSN         string
+	Environment string
+	Release     string
+
+	Level      string   // 最低上报等级，取值同 pplogger.Config.LogLevel，默认 Error
+	SampleRate float64  // 采样率，0 至 1，默认 1 即全部上报
+	Tags       []string // 作为 tag 上报的字段名，其余字段放在 fields context 中
+
+	// 上报前调用，可修改或脱敏事件，返回 nil 时不上报
+	BeforeSend func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event
+
+	FlushTimeout time.Duration // Sync、Close 及 Panic/Fatal 时等待发送的最长时间，默认 2s
+
+	Transport sentry.Transport // 自定义 Transport，用于测试
+}
+
+// Core 实现 zapcore.Core 与 io.Closer，使用独立的 sentry.Client，不影响全局的 sentry.CurrentHub
+type Core struct {
+	zapcore.LevelEnabler
+	client       *sentry.Client
+	tags         map[string]bool
+	fields       []zapcore.Field
+	flushTimeout time.Duration
+}
+
+// NewCore 创建 Core，交给 Logger 时由 Logger 关闭
+func NewCore(config Config) (*Core, error) {
+	if config.SampleRate < 0 || config.SampleRate > 1 {
+		return nil, fmt.Errorf("pplogger/sentry: SampleRate %v out of range [0, 1]", config.SampleRate)
+	}
+	if config.FlushTimeout < 0 {
+		return nil, errors.New("pplogger/sentry: negative FlushTimeout")
+	}
+
+	level := zapcore.ErrorLevel
+	if config.Level != "" {
+		var err error
+		if level, err = pplogger.ParseLevel(config.Level); err != nil {
+			return nil, fmt.Errorf("pplogger/sentry: %w", err)
+		}
+	}
+
+	sampleRate := config.SampleRate
+	if sampleRate == 0 {
+		sampleRate = 1
+	}
+	flushTimeout := config.FlushTimeout
+	if flushTimeout == 0 {
+		flushTimeout = defaultFlushTimeout
+	}
+
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         config.DSN,
+		Environment: config.Environment,
+		Release:     config.Release,
+		SampleRate:  sampleRate,
+		BeforeSend:  config.BeforeSend,
+		Transport:   config.Transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pplogger/sentry: %w", err)
+	}
+
+	tags := make(map[string]bool, len(config.Tags))
+	for _, tag := range config.Tags {
+		tags[tag] = true
+	}
+
+	return &Core{
+		LevelEnabler: level,
+		client:       client,
+		tags:         tags,
+		flushTimeout: flushTimeout,
+	}, nil
+}
+
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(append([]zapcore.Field(nil), c.fields...), fields...)
+	return &clone
+}
+
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.client.CaptureEvent(c.event(ent, append(append([]zapcore.Field(nil), c.fields...), fields...)), nil, nil)
+
+	if ent.Level > zapcore.ErrorLevel {
+		// DPanic、Panic 与 Fatal 之后进程可能立即退出，先等待发送完成
+		c.client.Flush(c.flushTimeout)
+	}
+
+	return nil
+}
+
+func (c *Core) Sync() error {
+	c.client.Flush(c.flushTimeout)
+	return nil
+}
+
+// Close 等待已捕获的事件发送完成
+func (c *Core) Close() error {
+	c.client.Flush(c.flushTimeout)
+	return nil
+}
+
+func (c *Core) event(ent zapcore.Entry, fields []zapcore.Field) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Level = sentryLevel(ent.Level)
+	event.Message = ent.Message
+	event.Timestamp = ent.Time
+	event.Logger = ent.LoggerName
+
+	enc := zapcore.NewMapObjectEncoder()
+	var errType, errValue string
+	for _, f := range fields {
+		if f.Type == zapcore.ErrorType && errType == "" {
+			if err, ok := f.Interface.(error); ok && err != nil {
+				errType, errValue = reflect.TypeOf(err).String(), err.Error()
+			}
+		}
+		f.AddTo(enc)
+	}
+	extra := make(sentry.Context, len(enc.Fields))
+	for k, v := range enc.Fields {
+		if s, ok := v.(string); ok && c.tags[k] {
+			event.Tags[k] = s
+			continue
+		}
+		extra[k] = v
+	}
+	if ent.Caller.Defined {
+		extra["caller"] = ent.Caller.TrimmedPath()
+	}
+	event.Contexts["fields"] = extra
+
+	if errType == "" {
+		errType, errValue = ent.Message, ent.Caller.TrimmedPath()
+	}
+	exception := sentry.Exception{Type: errType, Value: errValue}
+	if frames := parseStack(ent.Stack); len(frames) > 0 {
+		exception.Stacktrace = &sentry.Stacktrace{Frames: frames}
+	}
+	event.Exception = []sentry.Exception{exception}
+
+	return event
+}
+
+func sentryLevel(level zapcore.Level) sentry.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return sentry.LevelDebug
+	case zapcore.InfoLevel:
+		return sentry.LevelInfo
+	case zapcore.WarnLevel:
+		return sentry.LevelWarning
+	case zapcore.ErrorLevel:
+		return sentry.LevelError
+	default:
+		return sentry.LevelFatal
+	}
+}
+
+// parseStack 解析 zap 的堆栈文本，每个 frame 为函数名一行、制表符开头的 file:line 一行，
+// Sentry 要求 frame 从最外层调用开始排列，因此倒序返回
+func parseStack(stack string) []sentry.Frame {
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+
+	var frames []sentry.Frame
+	for i := 0; i+1 < len(lines); i += 2 {
+		function := strings.TrimSpace(lines[i])
+		location := strings.TrimSpace(lines[i+1])
+
+		file, line := location, 0
+		if idx := strings.LastIndexByte(location, ':'); idx > 0 {
+			if n, err := strconv.Atoi(location[idx+1:]); err == nil {
+				file, line = location[:idx], n
+			}
+		}
+
+		module, name := splitFunction(function)
+		frames = append(frames, sentry.Frame{
+			Function: name,
+			Module:   module,
+			AbsPath:  file,
+			Filename: file[strings.LastIndexByte(file, '/')+1:],
+			Lineno:   line,
+			InApp:    inApp(module),
+		})
+	}
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+
+	return frames
+}
+
+// splitFunction 将 github.com/a/b.(*T).Method 拆分为包路径 github.com/a/b 与 (*T).Method
+func splitFunction(function string) (string, string) {
+	slash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[slash+1:], '.')
+	if dot < 0 {
+		return "", function
+	}
+
+	dot += slash + 1
+	return function[:dot], function[dot+1:]
+}
+
+// inApp 判断 frame 是否属于应用代码，标准库（包路径首段不含 .）与 zap 不算
+func inApp(module string) bool {
+	first, _, _ := strings.Cut(module, "/")
+	return strings.Contains(first, ".") && !strings.HasPrefix(module, "go.uber.org/zap")
+}
@@ -0,0 +1,8 @@
+//go:build windows || plan9
+
+package pplogger
+
+// watchSIGHUP 在不支持 SIGHUP 的平台上不做任何处理
+func watchSIGHUP(*Logger) func() {
+	return func() {}
+}
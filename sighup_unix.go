@@ -0,0 +1,64 @@
+//go:build !windows && !plan9
+
+package pplogger
+
+import (
+	"go.uber.org/zap"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// sighup 记录设置了 RotateOnSIGHUP 的 Logger，多个 Logger 共用一个信号处理
+var sighup struct {
+	sync.Mutex
+	loggers map[*Logger]struct{}
+	signals chan os.Signal
+}
+
+// watchSIGHUP 在收到 SIGHUP 时切割 l，返回的函数将其移除，最后一个 Logger 移除后不再接收 SIGHUP
+func watchSIGHUP(l *Logger) func() {
+	sighup.Lock()
+	defer sighup.Unlock()
+
+	if sighup.loggers == nil {
+		sighup.loggers = make(map[*Logger]struct{})
+		sighup.signals = make(chan os.Signal, 1)
+		signal.Notify(sighup.signals, syscall.SIGHUP)
+		go handleSIGHUP(sighup.signals)
+	}
+	sighup.loggers[l] = struct{}{}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sighup.Lock()
+			defer sighup.Unlock()
+
+			delete(sighup.loggers, l)
+			if len(sighup.loggers) == 0 {
+				signal.Stop(sighup.signals)
+				close(sighup.signals)
+				sighup.loggers, sighup.signals = nil, nil
+			}
+		})
+	}
+}
+
+func handleSIGHUP(signals chan os.Signal) {
+	for range signals {
+		sighup.Lock()
+		loggers := make([]*Logger, 0, len(sighup.loggers))
+		for l := range sighup.loggers {
+			loggers = append(loggers, l)
+		}
+		sighup.Unlock()
+
+		for _, l := range loggers {
+			if err := l.Rotate(); err != nil {
+				l.Error("pplogger: rotate on SIGHUP failed", zap.Error(err))
+			}
+		}
+	}
+}
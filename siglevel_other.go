@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package pplogger
+
+// watchLevelSignals 在没有 SIGUSR1、SIGUSR2 的平台上只输出一条警告
+func watchLevelSignals(l *Logger) func() {
+	l.Warn("pplogger: SignalLevelToggle is not supported on this platform")
+
+	return func() {}
+}
@@ -0,0 +1,72 @@
+//go:build !windows && !plan9
+
+package pplogger
+
+import (
+	"go.uber.org/zap"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// levelSignals 记录设置了 SignalLevelToggle 的 Logger，多个 Logger 共用一个信号处理
+var levelSignals struct {
+	sync.Mutex
+	loggers map[*Logger]struct{}
+	signals chan os.Signal
+}
+
+// watchLevelSignals 在收到 SIGUSR1 时将 l 的等级改为 Debug，收到 SIGUSR2 时恢复为 LogLevel，
+// 返回的函数将其移除，最后一个 Logger 移除后不再接收这两个信号
+func watchLevelSignals(l *Logger) func() {
+	levelSignals.Lock()
+	defer levelSignals.Unlock()
+
+	if levelSignals.loggers == nil {
+		levelSignals.loggers = make(map[*Logger]struct{})
+		levelSignals.signals = make(chan os.Signal, 1)
+		signal.Notify(levelSignals.signals, syscall.SIGUSR1, syscall.SIGUSR2)
+		go handleLevelSignals(levelSignals.signals)
+	}
+	levelSignals.loggers[l] = struct{}{}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			levelSignals.Lock()
+			defer levelSignals.Unlock()
+
+			delete(levelSignals.loggers, l)
+			if len(levelSignals.loggers) == 0 {
+				signal.Stop(levelSignals.signals)
+				close(levelSignals.signals)
+				levelSignals.loggers, levelSignals.signals = nil, nil
+			}
+		})
+	}
+}
+
+func handleLevelSignals(signals chan os.Signal) {
+	for sig := range signals {
+		name := "SIGUSR1"
+		if sig == syscall.SIGUSR2 {
+			name = "SIGUSR2"
+		}
+
+		levelSignals.Lock()
+		loggers := make([]*Logger, 0, len(levelSignals.loggers))
+		for l := range levelSignals.loggers {
+			loggers = append(loggers, l)
+		}
+		levelSignals.Unlock()
+
+		for _, l := range loggers {
+			level := zap.DebugLevel
+			if sig == syscall.SIGUSR2 {
+				level, _ = ParseLevel(l.config.LogLevel)
+			}
+			l.changeLevel(level, "pplogger: log level changed by signal", zap.String("signal", name))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"io"
+)
+
+// Sink 是可插拔的输出，由 pplogger/kafka 等子包实现，避免核心包引入额外依赖。
+// 通过 Config.Sinks 传入后由 Logger 负责关闭，Sync 应等待已缓冲的日志发送完成
+type Sink interface {
+	zapcore.WriteSyncer
+	io.Closer
+}
+
+// FallbackSink 由发送失败时可降级写入本地的 Sink 实现，开启 FileWriter 时会传入日志文件
+type FallbackSink interface {
+	Sink
+	SetFallback(w io.Writer)
+}
+
+// sinkCores 为 Config.Sinks 创建输出，返回的 closers 应先于日志文件关闭，
+// 使 Sink 关闭时发送失败的日志仍能写入文件
+func sinkCores(config Config, fallback io.Writer) ([]zapcore.Core, []io.Closer) {
+	encoding := config.SinkEncoding
+	if encoding == "" {
+		encoding = JSONEncoding
+	}
+
+	var cores []zapcore.Core
+	var closers []io.Closer
+	for _, sink := range config.Sinks {
+		if fs, ok := sink.(FallbackSink); ok && fallback != nil {
+			fs.SetFallback(fallback)
+		}
+		cores = append(cores, newSinkCore(config, encoding, false, sink))
+		closers = append(closers, sink)
+	}
+
+	return cores, closers
+}
@@ -0,0 +1,52 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"sort"
+)
+
+// sortCore 将 With 附加的字段与日志自身的字段合并后按 key 排序再写入，
+// 保证相同字段的输出顺序稳定。zap.Namespace 之后的字段属于命名空间，保持原有顺序
+type sortCore struct {
+	zapcore.Core
+	context []zapcore.Field
+}
+
+func newSortCore(core zapcore.Core) zapcore.Core {
+	return &sortCore{Core: core}
+}
+
+func (c *sortCore) With(fields []zapcore.Field) zapcore.Core {
+	context := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+
+	return &sortCore{Core: c.Core, context: context}
+}
+
+func (c *sortCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *sortCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	all = append(all, c.context...)
+	all = append(all, fields...)
+
+	sorted := all
+	for i, f := range all {
+		if f.Type == zapcore.NamespaceType {
+			sorted = all[:i]
+			break
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	return c.Core.Write(ent, all)
+}
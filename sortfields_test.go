@@ -0,0 +1,51 @@
+package pplogger
+
+import (
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestSortFieldsGolden(t *testing.T) {
+	for encoding, timestamp := range map[string]*regexp.Regexp{
+		ConsoleEncoding: regexp.MustCompile(`^\S+ \S+`),
+		JSONEncoding:    regexp.MustCompile(`"ts":"[^"]+"`),
+	} {
+		want, err := os.ReadFile(filepath.Join("testdata", "sortfields."+encoding+".golden"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		replacement := "TS"
+		if encoding == JSONEncoding {
+			replacement = `"ts":"TS"`
+		}
+
+		// map 的遍历顺序每次不同，多次运行输出应保持一致
+		for i := 0; i < 20; i++ {
+			var buf syncBuffer
+			logger, err := NewLogger(Config{
+				Encoding:      encoding,
+				SortFields:    true,
+				DisableCaller: true,
+				InitialFields: map[string]interface{}{"m3": 3, "m1": 1, "m2": 2},
+				ExtraWriters:  []io.Writer{&buf},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields []zap.Field
+			for k, v := range map[string]int{"c": 3, "a": 1, "b": 2} {
+				fields = append(fields, zap.Int(k, v))
+			}
+			logger.With(zap.Int("z", 0)).Info("msg", fields...)
+			_ = logger.Sync()
+
+			if got := timestamp.ReplaceAllString(buf.String(), replacement); got != string(want) {
+				t.Fatalf("%s run %d:\ngot  %q\nwant %q", encoding, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package pplogger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+)
+
+// splitStdCores 创建按等级分流的控制台输出：minLevel 及以上写到 stderr，其余写到 stdout
+func splitStdCores(config Config, color bool, minLevel zapcore.Level) []zapcore.Core {
+	belowMin := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		return level < minLevel
+	})
+
+	return []zapcore.Core{
+		newLevelFilterCore(newSinkCore(config, config.StdoutEncoding, color, stdoutSyncer{os.Stdout}), belowMin),
+		newLevelFilterCore(newSinkCore(config, config.StdoutEncoding, color, stdoutSyncer{os.Stderr}), minLevel),
+	}
+}
+
+// k8sCores 创建容器模式下的控制台输出：Warn 及以上写到 stderr，其余写到 stdout，
+// 每条日志转义换行后只占一行
+func k8sCores(config Config) []zapcore.Core {
+	if config.TimeFormat == "" {
+		config.TimeFormat = TimeFormatRFC3339Nano
+	}
+	config.EscapeNewlines = true
+	config.EscapeStacktrace = true
+
+	return splitStdCores(config, false, zapcore.WarnLevel)
+}
@@ -0,0 +1,76 @@
+package pplogger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitStdStreams(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		config     Config
+		wantStdout []string
+		wantStderr []string
+	}{
+		{
+			name:       "default threshold",
+			config:     Config{SplitStdStreams: true},
+			wantStdout: []string{"m-debug", "m-info"},
+			wantStderr: []string{"m-warn", "m-error"},
+		},
+		{
+			name:       "StderrMinLevel",
+			config:     Config{SplitStdStreams: true, StderrMinLevel: ErrorLevel},
+			wantStdout: []string{"m-debug", "m-info", "m-warn"},
+			wantStderr: []string{"m-error"},
+		},
+		{
+			name:       "not split",
+			config:     Config{},
+			wantStdout: []string{"m-debug", "m-info", "m-warn", "m-error"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := captureStdout(t), captureStderr(t)
+			tt.config.StdoutWriter = true
+			tt.config.LogLevel = DebugLevel
+			tt.config.DisableStacktrace = true
+			logger, err := NewLogger(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Debug("m-debug")
+			logger.Info("m-info")
+			logger.Warn("m-warn")
+			logger.Error("m-error")
+			_ = logger.Sync()
+
+			for name, got := range map[string][]string{"stdout": messages(stdout()), "stderr": messages(stderr())} {
+				want := tt.wantStdout
+				if name == "stderr" {
+					want = tt.wantStderr
+				}
+				if strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Errorf("%s got %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+
+	if err := (Config{StdoutWriter: true, StderrMinLevel: "x"}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown StderrMinLevel")
+	}
+}
+
+// messages 返回控制台输出中每行的最后一列（消息）
+func messages(s string) []string {
+	var messages []string
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		if line != "" {
+			columns := strings.Split(line, "\t")
+			messages = append(messages, columns[len(columns)-1])
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,91 @@
+package pplogger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// syslogFacilities 为 syslog 设施名称对应的编号
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+func (c *SyslogConfig) validate() []error {
+	var errs []error
+
+	switch c.Network {
+	case "", "unix", "unixgram":
+	default:
+		errs = append(errs, fmt.Errorf("Syslog: unknown network %q", c.Network))
+	}
+
+	if c.Network != "" && c.Address == "" {
+		errs = append(errs, fmt.Errorf("Syslog: Address is required when Network is %q", c.Network))
+	}
+
+	if err := validSyslogFacility(c.Facility); err != nil {
+		errs = append(errs, fmt.Errorf("Syslog: %w", err))
+	}
+
+	return errs
+}
+
+func (c *SyslogConfig) facility() int {
+	return syslogFacility(c.Facility)
+}
+
+func (c *SyslogConfig) tag(appName string) string {
+	return syslogTag(c.Tag, appName)
+}
+
+func validSyslogFacility(facility string) error {
+	if facility == "" {
+		return nil
+	}
+
+	if _, ok := syslogFacilities[strings.ToLower(facility)]; !ok {
+		names := make([]string, 0, len(syslogFacilities))
+		for name := range syslogFacilities {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown facility %q, want one of %s", facility, strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+// syslogFacility 返回设施编号，默认 user
+func syslogFacility(facility string) int {
+	if n, ok := syslogFacilities[strings.ToLower(facility)]; ok {
+		return n
+	}
+
+	return syslogFacilityUser
+}
+
+// syslogTag 返回 syslog 标签，依次使用 tag、appName 及程序名
+func syslogTag(tag, appName string) string {
+	switch {
+	case tag != "":
+		return tag
+	case appName != "":
+		return appName
+	default:
+		return filepath.Base(os.Args[0])
+	}
+}
+
+// syslogMessageConfig 返回用于编码 syslog 消息的配置，时间和等级由 syslog 记录，不再重复输出
+func syslogMessageConfig(config Config) Config {
+	config.Keys.TimeKey = OmitKey
+	config.Keys.LevelKey = OmitKey
+
+	return config
+}
@@ -0,0 +1,173 @@
+package pplogger
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	syslogFacilityUser = 1
+	syslogSDID         = "pplogger@32473"
+)
+
+// syslog5424Encoder 按 RFC5424 输出：<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG，
+// 字段及调用者、堆栈作为 SD-PARAM 放在 [pplogger@32473 ...] 中，MSGID 为 logger 名称
+type syslog5424Encoder struct {
+	*flatEncoder
+	cfg      *zapcore.EncoderConfig
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	loc      *time.Location
+}
+
+func newSyslog5424Encoder(cfg zapcore.EncoderConfig, config Config, facility int) zapcore.Encoder {
+	appName := config.AppName
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+
+	var loc *time.Location
+	if config.TimeZone != "" {
+		loc, _ = time.LoadLocation(config.TimeZone)
+	}
+
+	return &syslog5424Encoder{
+		flatEncoder: newFlatEncoder(&cfg),
+		cfg:         &cfg,
+		facility:    facility,
+		hostname:    syslogHeaderField(hostname(), 255),
+		appName:     syslogHeaderField(appName, 48),
+		procID:      strconv.Itoa(os.Getpid()),
+		loc:         loc,
+	}
+}
+
+func (enc *syslog5424Encoder) Clone() zapcore.Encoder {
+	clone := *enc
+	clone.flatEncoder = enc.flatEncoder.clone()
+	return &clone
+}
+
+func (enc *syslog5424Encoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := enc.flatEncoder.clone()
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+
+	var params []flatField
+	if ent.Caller.Defined && enc.cfg.CallerKey != "" && enc.cfg.EncodeCaller != nil {
+		var caller primitiveStrings
+		enc.cfg.EncodeCaller(ent.Caller, &caller)
+		params = append(params, flatField{Key: enc.cfg.CallerKey, Value: strings.Join(caller, " ")})
+	}
+	params = append(params, final.fields...)
+	if ent.Stack != "" && enc.cfg.StacktraceKey != "" {
+		params = append(params, flatField{Key: enc.cfg.StacktraceKey, Value: ent.Stack})
+	}
+
+	buf := bufferPool.Get()
+	buf.AppendByte('<')
+	buf.AppendInt(int64(enc.facility*8 + syslogSeverity(ent.Level)))
+	buf.AppendString(">1 ")
+
+	if ent.Time.IsZero() {
+		buf.AppendByte('-')
+	} else {
+		t := ent.Time
+		if enc.loc != nil {
+			t = t.In(enc.loc)
+		}
+		buf.AppendTime(t, "2006-01-02T15:04:05.000000Z07:00")
+	}
+
+	buf.AppendByte(' ')
+	buf.AppendString(enc.hostname)
+	buf.AppendByte(' ')
+	buf.AppendString(enc.appName)
+	buf.AppendByte(' ')
+	buf.AppendString(enc.procID)
+	buf.AppendByte(' ')
+	buf.AppendString(syslogHeaderField(encodeName(enc.cfg, ent.LoggerName), 32))
+	buf.AppendByte(' ')
+
+	if len(params) == 0 {
+		buf.AppendByte('-')
+	} else {
+		buf.AppendString("[" + syslogSDID)
+		for _, f := range params {
+			buf.AppendByte(' ')
+			appendSDParamName(buf, f.Key)
+			buf.AppendString(`="`)
+			appendSDParamValue(buf, f.Value)
+			buf.AppendByte('"')
+		}
+		buf.AppendByte(']')
+	}
+
+	if ent.Message != "" {
+		buf.AppendByte(' ')
+		buf.AppendString(ent.Message)
+	}
+
+	lineEnding := enc.cfg.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	buf.AppendString(lineEnding)
+
+	return buf, nil
+}
+
+// syslogHeaderField 将头部字段限制为可打印 ASCII 且不超过 max 个字符，为空时返回 NILVALUE "-"
+func syslogHeaderField(s string, max int) string {
+	if s == "" {
+		return "-"
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s) && b.Len() < max; i++ {
+		if c := s[i]; c >= 33 && c <= 126 {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String()
+}
+
+// appendSDParamName 写入 PARAM-NAME：最多 32 个可打印 ASCII 字符，不能包含 =、空格、] 和 "
+func appendSDParamName(buf *buffer.Buffer, key string) {
+	if key == "" {
+		buf.AppendByte('_')
+		return
+	}
+
+	for i := 0; i < len(key) && i < 32; i++ {
+		c := key[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		buf.AppendByte(c)
+	}
+}
+
+// appendSDParamValue 写入 PARAM-VALUE，按 RFC5424 对 "、\ 和 ] 加反斜杠转义
+func appendSDParamValue(buf *buffer.Buffer, value string) {
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\\', ']':
+			buf.AppendByte('\\')
+			buf.AppendByte(c)
+		default:
+			buf.AppendByte(c)
+		}
+	}
+}
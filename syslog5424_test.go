@@ -0,0 +1,139 @@
+package pplogger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAppendSDParamValue(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"", ""},
+		{`"`, `\"`},
+		{`\`, `\\`},
+		{`]`, `\]`},
+		{`]]\\"`, `\]\]\\\\\"`},
+		{`a "b" \c] d`, `a \"b\" \\c\] d`},
+		// [ 和 = 不需要转义，UTF-8 原样保留
+		{`[x=y]`, `[x=y\]`},
+		{"中文", "中文"},
+	} {
+		var buf buffer.Buffer
+		appendSDParamValue(&buf, tt.value)
+		if buf.String() != tt.want {
+			t.Errorf("appendSDParamValue(%q) = %q, want %q", tt.value, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestAppendSDParamName(t *testing.T) {
+	for _, tt := range []struct {
+		key  string
+		want string
+	}{
+		{"user", "user"},
+		{"", "_"},
+		{"bad=key ]", "bad_key__"},
+		{`q"x`, "q_x"},
+		{"名字", "______"},
+		{strings.Repeat("k", 40), strings.Repeat("k", 32)},
+	} {
+		var buf buffer.Buffer
+		appendSDParamName(&buf, tt.key)
+		if buf.String() != tt.want {
+			t.Errorf("appendSDParamName(%q) = %q, want %q", tt.key, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestSyslogHeaderField(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"", 48, "-"},
+		{"my app", 48, "my_app"},
+		{"abcdef", 3, "abc"},
+	} {
+		if got := syslogHeaderField(tt.s, tt.max); got != tt.want {
+			t.Errorf("syslogHeaderField(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestSyslog5424Encoding(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{
+		Encoding:          Syslog5424Encoding,
+		AppName:           "my app",
+		TimeZone:          "UTC",
+		DisableStacktrace: true,
+		ExtraWriters:      []io.Writer{&buf},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Named("db").Warn("hello world",
+		zap.String("q", `a "b" \c] d`),
+		zap.Int("n", 1),
+		zap.Object("o", testObject{name: "v", count: 2}),
+		zap.String("bad=key ]", "x"),
+	)
+	_ = logger.Sync()
+
+	// warn 为 user(1)*8+4=12
+	re := regexp.MustCompile(`^<12>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z \S+ my_app ` + strconv.Itoa(os.Getpid()) +
+		` db \[pplogger@32473 caller="[^"]*/syslog5424_test\.go:\d+" q="a \\"b\\" \\\\c\\] d" n="1" o\.name="v" o\.count="2" bad_key__="x"\] hello world\n$`)
+	if !re.MatchString(buf.String()) {
+		t.Errorf("unexpected line %q", buf.String())
+	}
+}
+
+func TestSyslog5424NilValues(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{Encoding: Syslog5424Encoding, DisableCaller: true, ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("m")
+	_ = logger.Sync()
+
+	// 没有名称和字段时 MSGID 与 SD 为 NILVALUE
+	if !regexp.MustCompile(`^<14>1 \S+ \S+ \S+ \d+ - - m\n$`).MatchString(buf.String()) {
+		t.Errorf("unexpected line %q", buf.String())
+	}
+}
+
+func TestSyslog5424Severity(t *testing.T) {
+	for _, tt := range []struct {
+		log  func(*Logger)
+		want string
+	}{
+		{func(l *Logger) { l.Debug("x") }, "<15>"},
+		{func(l *Logger) { l.Info("x") }, "<14>"},
+		{func(l *Logger) { l.Warn("x") }, "<12>"},
+		{func(l *Logger) { l.Error("x") }, "<11>"},
+	} {
+		var buf syncBuffer
+		logger, err := NewLogger(Config{Encoding: Syslog5424Encoding, LogLevel: DebugLevel, DisableStacktrace: true, ExtraWriters: []io.Writer{&buf}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.log(logger)
+		_ = logger.Sync()
+
+		if !strings.HasPrefix(buf.String(), tt.want+"1 ") {
+			t.Errorf("got %q, want PRI %s", buf.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package pplogger
+
+import (
+	"errors"
+	"go.uber.org/zap/zapcore"
+	"io"
+)
+
+func newSyslogCore(config Config, stats *sinkStats) (zapcore.Core, io.Closer, error) {
+	return nil, nil, errors.New("pplogger: syslog is not supported on this platform")
+}
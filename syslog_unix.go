@@ -0,0 +1,93 @@
+//go:build !windows && !plan9
+
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"log/syslog"
+	"strings"
+)
+
+// syslogCore 将日志写入 syslog，按等级选择优先级，写入失败时计数并丢弃，不影响其他输出
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc    zapcore.Encoder
+	writer *syslog.Writer
+	stats  *sinkStats
+}
+
+func newSyslogCore(config Config, stats *sinkStats) (zapcore.Core, io.Closer, error) {
+	c := config.Syslog
+	writer, err := syslog.Dial(c.Network, c.Address, syslog.Priority(c.facility()<<3), c.tag(config.AppName))
+	if err != nil {
+		return nil, nil, fmt.Errorf("pplogger: connect syslog: %w", err)
+	}
+
+	messageConfig := syslogMessageConfig(config)
+	core := &syslogCore{
+		LevelEnabler: zapcore.DebugLevel,
+		enc:          newEncoder(messageConfig, "", false),
+		writer:       writer,
+		stats:        stats,
+	}
+
+	return core, writer, nil
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+
+	return &clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	message := strings.TrimRight(buf.String(), "\r\n")
+	buf.Free()
+
+	if err := c.write(ent.Level, message); err != nil {
+		c.stats.writeErrors.Add(1)
+	}
+
+	return nil
+}
+
+func (c *syslogCore) write(level zapcore.Level, message string) error {
+	switch level {
+	case zapcore.DebugLevel:
+		return c.writer.Debug(message)
+	case zapcore.InfoLevel:
+		return c.writer.Info(message)
+	case zapcore.WarnLevel:
+		return c.writer.Warning(message)
+	case zapcore.ErrorLevel:
+		return c.writer.Err(message)
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return c.writer.Crit(message)
+	case zapcore.FatalLevel:
+		return c.writer.Alert(message)
+	default:
+		return c.writer.Info(message)
+	}
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
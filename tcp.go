@@ -0,0 +1,275 @@
+package pplogger
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// TCPConfig 是 TCP 输出的配置，每条日志以换行结尾，默认 json 编码即为 NDJSON
+type TCPConfig struct {
+	Address      string        // host:port
+	Encoding     string        // 编码格式，默认 json
+	TLSConfig    *tls.Config   // 不为空时使用 TLS 连接
+	DialTimeout  time.Duration // 连接超时时间，默认 5s
+	WriteTimeout time.Duration // 写入超时时间，默认 5s
+
+	// 内存中最多缓冲的日志条数，默认 1000。断线期间日志缓冲在其中，重连后按顺序补发，
+	// 缓冲区满时丢弃最早的日志并计入 Stats.Dropped
+	BufferSize int
+
+	FlushTimeout time.Duration // Close 时发送剩余日志最多等待的时间，默认 2s
+}
+
+const (
+	defaultTCPTimeout      = 5 * time.Second
+	defaultTCPBufferSize   = 1000
+	defaultTCPFlushTimeout = 2 * time.Second
+	tcpMinBackoff          = 100 * time.Millisecond
+	tcpMaxBackoff          = 30 * time.Second
+	tcpMaxBatchBytes       = 64 << 10
+)
+
+func (c *TCPConfig) validate() []error {
+	var errs []error
+
+	if host, _, err := net.SplitHostPort(c.Address); err != nil || host == "" {
+		errs = append(errs, fmt.Errorf("TCP: invalid Address %q, want host:port", c.Address))
+	}
+
+	if !validEncoding(c.Encoding) {
+		errs = append(errs, fmt.Errorf("TCP: unknown Encoding %q", c.Encoding))
+	}
+
+	if c.DialTimeout < 0 || c.WriteTimeout < 0 || c.FlushTimeout < 0 || c.BufferSize < 0 {
+		errs = append(errs, errors.New("TCP: negative BufferSize or timeout"))
+	}
+
+	return errs
+}
+
+// newTCPCore 创建 TCP 输出，日志放入环形缓冲区，由后台 goroutine 按顺序发送
+func newTCPCore(config Config, stats *sinkStats) (zapcore.Core, *tcpWriter) {
+	c := *config.TCP
+	if c.Encoding == "" {
+		c.Encoding = JSONEncoding
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = defaultTCPTimeout
+	}
+	if c.WriteTimeout == 0 {
+		c.WriteTimeout = defaultTCPTimeout
+	}
+	if c.BufferSize == 0 {
+		c.BufferSize = defaultTCPBufferSize
+	}
+	if c.FlushTimeout == 0 {
+		c.FlushTimeout = defaultTCPFlushTimeout
+	}
+
+	w := &tcpWriter{
+		config: c,
+		ring:   make([]tcpEntry, c.BufferSize),
+		stats:  stats,
+		notify: make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+
+	return newSinkCore(config, c.Encoding, false, w), w
+}
+
+// tcpWriter 写入从不阻塞，断线时按带抖动的指数退避重连
+type tcpWriter struct {
+	config TCPConfig
+	stats  *sinkStats
+	conn   net.Conn
+	dialed bool
+
+	mu      sync.Mutex
+	ring    []tcpEntry
+	head    int // 最早一条日志的位置
+	count   int
+	nextSeq uint64
+
+	notify    chan struct{}
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// tcpEntry 是缓冲区中的一条日志，seq 用于发送成功后确认移除的范围
+type tcpEntry struct {
+	seq uint64
+	msg []byte
+}
+
+func (w *tcpWriter) Write(p []byte) (int, error) {
+	msg := append([]byte(nil), p...)
+
+	w.mu.Lock()
+	if w.count == len(w.ring) {
+		// 缓冲区已满，丢弃最早的日志
+		w.ring[w.head] = tcpEntry{}
+		w.head = (w.head + 1) % len(w.ring)
+		w.count--
+		w.stats.dropped.Add(1)
+	}
+	w.ring[(w.head+w.count)%len(w.ring)] = tcpEntry{seq: w.nextSeq, msg: msg}
+	w.nextSeq++
+	w.count++
+	w.mu.Unlock()
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+
+	return len(p), nil
+}
+
+func (w *tcpWriter) Sync() error {
+	return nil
+}
+
+// Close 最多等待 FlushTimeout 发送缓冲区中剩余的日志
+func (w *tcpWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.quit)
+		<-w.done
+	})
+
+	return nil
+}
+
+// peek 返回缓冲区开头总大小不超过 tcpMaxBatchBytes 的若干条日志及其后一条的 seq，
+// 发送成功后再以该 seq 调用 pop
+func (w *tcpWriter) peek() ([][]byte, uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var batch [][]byte
+	var end uint64
+	size := 0
+	for i := 0; i < w.count; i++ {
+		e := w.ring[(w.head+i)%len(w.ring)]
+		if i > 0 && size+len(e.msg) > tcpMaxBatchBytes {
+			break
+		}
+		batch = append(batch, e.msg)
+		size += len(e.msg)
+		end = e.seq + 1
+	}
+
+	return batch, end
+}
+
+// pop 移除 seq 小于 end 的日志，发送期间因缓冲区满被丢弃的不会重复移除
+func (w *tcpWriter) pop(end uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for w.count > 0 && w.ring[w.head].seq < end {
+		w.ring[w.head] = tcpEntry{}
+		w.head = (w.head + 1) % len(w.ring)
+		w.count--
+	}
+}
+
+func (w *tcpWriter) run() {
+	defer close(w.done)
+	defer func() {
+		if w.conn != nil {
+			_ = w.conn.Close()
+		}
+	}()
+
+	backoff := tcpMinBackoff
+	for {
+		batch, end := w.peek()
+		if len(batch) == 0 {
+			select {
+			case <-w.notify:
+				continue
+			case <-w.quit:
+				return
+			}
+		}
+
+		if err := w.send(batch); err == nil {
+			w.pop(end)
+			backoff = tcpMinBackoff
+			continue
+		}
+		w.stats.writeErrors.Add(1)
+
+		// 带 ±50% 抖动，避免多个实例同时重连
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-time.After(wait):
+		case <-w.quit:
+			w.drain()
+			return
+		}
+		backoff = min(backoff*2, tcpMaxBackoff)
+	}
+}
+
+// drain 关闭时在 FlushTimeout 内尽力发送剩余日志，未发出的计入 Stats.Dropped
+func (w *tcpWriter) drain() {
+	deadline := time.Now().Add(w.config.FlushTimeout)
+	for time.Now().Before(deadline) {
+		batch, end := w.peek()
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.send(batch); err != nil {
+			time.Sleep(min(tcpMinBackoff, time.Until(deadline)))
+			continue
+		}
+		w.pop(end)
+	}
+
+	w.mu.Lock()
+	w.stats.dropped.Add(uint64(w.count))
+	w.mu.Unlock()
+}
+
+// send 发送一批日志，写入失败时关闭连接以便下次重连
+func (w *tcpWriter) send(batch [][]byte) error {
+	if w.conn == nil {
+		conn, err := w.dial()
+		if err != nil {
+			return err
+		}
+		if w.dialed {
+			w.stats.reconnects.Add(1)
+		}
+		w.conn, w.dialed = conn, true
+	}
+
+	_ = w.conn.SetWriteDeadline(time.Now().Add(w.config.WriteTimeout))
+	buffers := net.Buffers(batch)
+	if _, err := buffers.WriteTo(w.conn); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+func (w *tcpWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: w.config.DialTimeout}
+	if w.config.TLSConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", w.config.Address, w.config.TLSConfig)
+	}
+
+	return dialer.Dial("tcp", w.config.Address)
+}
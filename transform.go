@@ -0,0 +1,194 @@
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"unicode/utf8"
+)
+
+// transformCore 在编码前改写消息、字符串类字段及堆栈，对应的函数为 nil 时不改写
+type transformCore struct {
+	zapcore.Core
+	message func(string) string
+	field   func(string) string
+	stack   func(string) string
+}
+
+func (c *transformCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(c.transformFields(fields))
+	return &clone
+}
+
+func (c *transformCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *transformCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.message != nil {
+		ent.Message = c.message(ent.Message)
+	}
+	if c.stack != nil && ent.Stack != "" {
+		ent.Stack = c.stack(ent.Stack)
+	}
+
+	return c.Core.Write(ent, c.transformFields(fields))
+}
+
+// transformFields 改写字符串类字段，只在需要修改时复制 fields
+func (c *transformCore) transformFields(fields []zapcore.Field) []zapcore.Field {
+	if c.field == nil {
+		return fields
+	}
+
+	var out []zapcore.Field
+	for i, f := range fields {
+		transformed, ok := c.transformField(f)
+		if !ok {
+			if out != nil {
+				out[i] = f
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields[:i])
+		}
+		out[i] = transformed
+	}
+
+	if out == nil {
+		return fields
+	}
+
+	return out
+}
+
+// transformField 改写单个字段，字段未改变时返回 false
+func (c *transformCore) transformField(f zapcore.Field) (zapcore.Field, bool) {
+	var s string
+	switch f.Type {
+	case zapcore.StringType:
+		s = f.String
+	case zapcore.ByteStringType:
+		s = string(f.Interface.([]byte))
+	case zapcore.StringerType:
+		stringer, ok := f.Interface.(fmt.Stringer)
+		if !ok {
+			return f, false
+		}
+		s = stringer.String()
+	case zapcore.ErrorType:
+		err, ok := f.Interface.(error)
+		if !ok || err == nil {
+			return f, false
+		}
+		s = err.Error()
+	default:
+		return f, false
+	}
+
+	transformed := c.field(s)
+	if transformed == s {
+		return f, false
+	}
+
+	return zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: transformed}, true
+}
+
+// newTruncateCore 截断过长的消息及字符串字段，避免单条日志撑爆文件和下游解析
+func newTruncateCore(core zapcore.Core, maxMessage, maxField int) zapcore.Core {
+	c := &transformCore{Core: core}
+	if maxMessage > 0 {
+		c.message = func(s string) string { return truncateString(s, maxMessage) }
+	}
+	if maxField > 0 {
+		c.field = func(s string) string { return truncateString(s, maxField) }
+	}
+
+	return c
+}
+
+// truncateString 将 s 截断到最多 max 字节，不拆分多字节字符，并追加截断标记
+func truncateString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s...(truncated, %d bytes)", s[:cut], len(s)-cut)
+}
+
+// newEscapeCore 将消息及字符串字段中的换行和回车替换为字面的 \n、\r
+func newEscapeCore(core zapcore.Core) zapcore.Core {
+	return &transformCore{Core: core, message: escapeNewlines, field: escapeNewlines}
+}
+
+var newlineReplacer = strings.NewReplacer("\r", `\r`, "\n", `\n`)
+
+func escapeNewlines(s string) string {
+	if !strings.ContainsAny(s, "\r\n") {
+		return s
+	}
+
+	return newlineReplacer.Replace(s)
+}
+
+// newStackCore 去掉函数名匹配 skipPrefixes 的堆栈帧，并最多保留 maxFrames 帧，0 表示不限制
+func newStackCore(core zapcore.Core, maxFrames int, skipPrefixes []string) zapcore.Core {
+	return &transformCore{Core: core, stack: func(stack string) string {
+		return trimStack(stack, maxFrames, skipPrefixes)
+	}}
+}
+
+// trimStack 处理 zap 格式的堆栈，每帧为函数名一行加上以 \t 开头的位置一行，
+// 超出 maxFrames 的部分以 "... N more" 代替
+func trimStack(stack string, maxFrames int, skipPrefixes []string) string {
+	lines := strings.Split(stack, "\n")
+	var kept []string
+	frames, more := 0, 0
+	for i := 0; i < len(lines); {
+		// 一帧为函数名加上其后以 \t 开头的若干行
+		end := i + 1
+		for end < len(lines) && strings.HasPrefix(lines[end], "\t") {
+			end++
+		}
+		frame := lines[i:end]
+		i = end
+
+		if hasAnyPrefix(frame[0], skipPrefixes) {
+			continue
+		}
+		if maxFrames > 0 && frames >= maxFrames {
+			more++
+			continue
+		}
+		kept = append(kept, frame...)
+		frames++
+	}
+
+	if more > 0 {
+		kept = append(kept, fmt.Sprintf("... %d more", more))
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
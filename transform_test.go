@@ -0,0 +1,175 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateString(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"abcdefgh", 3, "abc...(truncated, 5 bytes)"},
+		// 每个汉字 3 字节，截断点落在字符中间时向前退到字符边界
+		{"你好世界", 7, "你好...(truncated, 6 bytes)"},
+		{"你好世界", 6, "你好...(truncated, 6 bytes)"},
+		{"你好", 2, "...(truncated, 6 bytes)"},
+		{"a日本語", 5, "a日...(truncated, 6 bytes)"},
+	} {
+		got := truncateString(tt.s, tt.max)
+		if got != tt.want {
+			t.Errorf("truncateString(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateString(%q, %d) split a rune: %q", tt.s, tt.max, got)
+		}
+	}
+}
+
+func TestMaxMessageAndFieldBytes(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{Encoding: JSONEncoding, MaxMessageBytes: 10, MaxFieldBytes: 4, ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.With(zap.String("ctx", "中文字段")).Info("日志消息很长很长",
+		zap.String("s", "abcdefg"),
+		zap.String("ok", "abc"),
+		zap.Int("n", 123456),
+		zap.Error(errors.New("错误信息")),
+		zap.ByteString("b", []byte("bytesbytes")),
+	)
+	_ = logger.Sync()
+
+	m := decodeLine(t, buf.lines()[0])
+	want := map[string]interface{}{
+		"msg":   "日志消...(truncated, 15 bytes)",
+		"ctx":   "中...(truncated, 9 bytes)",
+		"s":     "abcd...(truncated, 3 bytes)",
+		"ok":    "abc",
+		"n":     float64(123456),
+		"error": "错...(truncated, 9 bytes)",
+		"b":     "byte...(truncated, 6 bytes)",
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, m[k], v)
+		}
+	}
+
+	if err := (Config{StdoutWriter: true, MaxFieldBytes: -1}).Validate(); err == nil {
+		t.Error("Validate accepted a negative MaxFieldBytes")
+	}
+}
+
+func TestEscapeNewlines(t *testing.T) {
+	for _, tt := range []struct {
+		encoding        string
+		escapeStack     bool
+		wantStackInline bool
+	}{
+		{ConsoleEncoding, false, false},
+		{ConsoleEncoding, true, true},
+		{JSONEncoding, false, true},
+		{JSONEncoding, true, true},
+	} {
+		var buf syncBuffer
+		logger, err := NewLogger(Config{
+			Encoding:         tt.encoding,
+			EscapeNewlines:   true,
+			EscapeStacktrace: tt.escapeStack,
+			StacktraceLevel:  "error",
+			ExtraWriters:     []io.Writer{&buf},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Error("line1\r\nline2\nline3\r", zap.String("out", "a\r\nb"))
+		_ = logger.Sync()
+
+		s := buf.String()
+		if strings.Contains(s, "\r") {
+			t.Errorf("%s: raw carriage return in %q", tt.encoding, s)
+		}
+		// 消息和字段转义后只占一行，json 编码（包括控制台的字段部分）会再转义一次反斜杠
+		first := buf.lines()[0]
+		wantMsg, wantField := `line1\r\nline2\nline3\r`, `a\\r\\nb`
+		if tt.encoding == JSONEncoding {
+			wantMsg = `line1\\r\\nline2\\nline3\\r`
+		}
+		if !strings.Contains(first, wantMsg) || !strings.Contains(first, wantField) {
+			t.Errorf("%s: first line %q does not contain the escaped message and field", tt.encoding, first)
+		}
+		if inline := len(buf.lines()) == 1; inline != tt.wantStackInline {
+			t.Errorf("%s EscapeStacktrace=%v: got %d lines", tt.encoding, tt.escapeStack, len(buf.lines()))
+		}
+	}
+}
+
+// syntheticStack 生成 n 帧的 zap 格式堆栈，每 3 帧一个 zap 内部帧，每 5 帧一个 net/http 帧
+func syntheticStack(n int) string {
+	frames := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		function := fmt.Sprintf("app.f%d", i)
+		if i%3 == 0 {
+			function = fmt.Sprintf("go.uber.org/zap.x%d", i)
+		}
+		if i%5 == 0 {
+			function = fmt.Sprintf("net/http.h%d", i)
+		}
+		frames = append(frames, fmt.Sprintf("%s\n\t/src/f.go:%d", function, i))
+	}
+
+	return strings.Join(frames, "\n")
+}
+
+func TestTrimStack(t *testing.T) {
+	stack := syntheticStack(30)
+	skip := []string{"go.uber.org/zap", "net/http"}
+	for _, tt := range []struct {
+		name      string
+		maxFrames int
+		skip      []string
+		want      string
+	}{
+		{"unchanged", 0, nil, stack},
+		{"skip and limit", 4, skip, "app.f1\n\t/src/f.go:1\napp.f2\n\t/src/f.go:2\napp.f4\n\t/src/f.go:4\napp.f7\n\t/src/f.go:7\n... 12 more"},
+		{"limit only", 2, nil, "net/http.h0\n\t/src/f.go:0\napp.f1\n\t/src/f.go:1\n... 28 more"},
+		{"limit above depth", 100, nil, stack},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimStack(stack, tt.maxFrames, tt.skip); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// 只跳过时帧数为 30 减去 16 个被过滤的帧
+	if got := strings.Count(trimStack(stack, 0, skip), "\n\t"); got != 16 {
+		t.Errorf("skip only kept %d frames, want 16", got)
+	}
+}
+
+func TestStacktraceMaxFrames(t *testing.T) {
+	var buf syncBuffer
+	logger, err := NewLogger(Config{StacktraceMaxFrames: 1, StacktraceLevel: "error", ExtraWriters: []io.Writer{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Error("boom")
+	_ = logger.Sync()
+
+	lines := buf.lines()
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "github.com/piaoyunsoft/pplogger.TestStacktraceMaxFrames") || !strings.HasPrefix(lines[3], "... ") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,166 @@
+package pplogger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net"
+	"sync"
+	"time"
+)
+
+// UnixSocketConfig 是 Unix domain socket 输出的配置
+type UnixSocketConfig struct {
+	Path string // socket 路径，例如 /var/run/logagent.sock
+
+	// unixgram（默认）每条日志一个数据报，unix 为流式连接，每条日志前加 4 字节大端长度
+	Network string
+}
+
+const (
+	unixSocketRedialInterval = time.Second
+	unixSocketMinDatagram    = 256
+	unixSocketMarkerSize     = 32 // 截断标记 ...(truncated, N bytes) 的最大长度
+)
+
+func (c *UnixSocketConfig) validate() []error {
+	var errs []error
+
+	if c.Path == "" {
+		errs = append(errs, errors.New("UnixSocket: missing Path"))
+	}
+
+	switch c.Network {
+	case "", "unixgram", "unix":
+	default:
+		errs = append(errs, fmt.Errorf("UnixSocket: unknown Network %q, want unixgram or unix", c.Network))
+	}
+
+	return errs
+}
+
+// newUnixSocketCore 创建 Unix domain socket 输出，连接在第一次写入时建立，
+// 对端重启导致写入失败时重新连接，仍失败的日志计入 Stats.WriteErrors 并丢弃
+func newUnixSocketCore(config Config, stats *sinkStats) (zapcore.Core, *unixSocketWriter) {
+	network := config.UnixSocket.Network
+	if network == "" {
+		network = "unixgram"
+	}
+
+	w := &unixSocketWriter{
+		network: network,
+		path:    config.UnixSocket.Path,
+		stats:   stats,
+	}
+
+	return newSinkCore(config, "", false, w), w
+}
+
+// unixSocketWriter 写入 Unix domain socket，数据报超过 socket 缓冲区时截断后发送
+type unixSocketWriter struct {
+	network string
+	path    string
+	stats   *sinkStats
+
+	mu          sync.Mutex
+	conn        net.Conn
+	lastDial    time.Time
+	maxDatagram int // 已知可发送的最大数据报，0 表示未知
+	closed      bool
+}
+
+func (w *unixSocketWriter) Write(p []byte) (int, error) {
+	msg := bytes.TrimRight(p, "\r\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return len(p), nil
+	}
+
+	if err := w.send(msg); err != nil {
+		// 对端可能已重启并重新创建了 socket，重连后再试一次
+		w.reset()
+		if err := w.send(msg); err != nil {
+			w.stats.writeErrors.Add(1)
+		}
+	}
+
+	return len(p), nil
+}
+
+func (w *unixSocketWriter) Sync() error {
+	return nil
+}
+
+func (w *unixSocketWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	w.reset()
+	return nil
+}
+
+func (w *unixSocketWriter) reset() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func (w *unixSocketWriter) send(msg []byte) error {
+	if w.conn == nil {
+		// 对端不存在时避免每条日志都尝试连接
+		if time.Since(w.lastDial) < unixSocketRedialInterval {
+			return errors.New("pplogger: unix socket not connected")
+		}
+		w.lastDial = time.Now()
+
+		conn, err := net.Dial(w.network, w.path)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+		w.lastDial = time.Time{}
+	}
+
+	if w.network == "unix" {
+		frame := make([]byte, 4, 4+len(msg))
+		binary.BigEndian.PutUint32(frame, uint32(len(msg)))
+		_, err := w.conn.Write(append(frame, msg...))
+		return err
+	}
+
+	return w.sendDatagram(msg)
+}
+
+// sendDatagram 发送一个数据报，过大时逐步减半截断，并记住可发送的大小
+func (w *unixSocketWriter) sendDatagram(msg []byte) error {
+	size := len(msg)
+	if w.maxDatagram > 0 && size > w.maxDatagram {
+		size = w.maxDatagram
+	}
+
+	for {
+		datagram := msg
+		if size < len(msg) {
+			datagram = []byte(truncateString(string(msg), size-unixSocketMarkerSize))
+		}
+
+		_, err := w.conn.Write(datagram)
+		if err == nil {
+			if size < len(msg) {
+				w.maxDatagram = size
+			}
+			return nil
+		}
+		if !datagramTooLarge(err) || size <= unixSocketMinDatagram {
+			return err
+		}
+		size /= 2
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !plan9
+
+package pplogger
+
+import (
+	"errors"
+	"syscall"
+)
+
+// datagramTooLarge 判断写入失败是否因为数据报超过了 socket 缓冲区
+func datagramTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
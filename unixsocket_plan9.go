@@ -0,0 +1,6 @@
+package pplogger
+
+// datagramTooLarge plan9 不支持 Unix domain socket
+func datagramTooLarge(err error) bool {
+	return false
+}
@@ -0,0 +1,122 @@
+//go:build !windows && !plan9
+
+package pplogger
+
+import (
+	"encoding/binary"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listenUnixgram(t *testing.T, path string) *net.UnixConn {
+	t.Helper()
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadBuffer(4 << 20)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	return conn
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+
+	buf := make([]byte, 2<<20)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(buf[:n])
+}
+
+func TestUnixSocketDatagram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	conn := listenUnixgram(t, path)
+	logger, err := NewLogger(Config{UnixSocket: &UnixSocketConfig{Path: path}, Encoding: JSONEncoding})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	// 每条日志一个数据报，消息中的换行不会拆分数据报
+	logger.Info("one")
+	logger.Info("two\nlines")
+	logger.Info("big", zap.String("data", strings.Repeat("x", 1<<20)))
+	if got := readDatagram(t, conn); !strings.Contains(got, `"msg":"one"`) || strings.HasSuffix(got, "\n") {
+		t.Errorf("first datagram %q", got)
+	}
+	if got := readDatagram(t, conn); !strings.Contains(got, `"msg":"two\nlines"`) {
+		t.Errorf("second datagram %q", got)
+	}
+	// 超过 socket 缓冲区的数据报截断并追加标记
+	if got := readDatagram(t, conn); len(got) >= 1<<20 || !strings.Contains(got, "...(truncated, ") {
+		t.Errorf("oversized datagram not truncated, %d bytes", len(got))
+	}
+
+	// agent 重启并重新创建 socket 后重新连接
+	_ = conn.Close()
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("while down")
+	if stats := logger.Stats(); stats.WriteErrors != 1 {
+		t.Errorf("Stats = %+v, want 1 write error while the agent is down", stats)
+	}
+	conn = listenUnixgram(t, path)
+	defer conn.Close()
+	// 连接失败后间隔 unixSocketRedialInterval 才重新连接
+	time.Sleep(unixSocketRedialInterval)
+	logger.Info("three")
+	if got := readDatagram(t, conn); !strings.Contains(got, `"msg":"three"`) {
+		t.Errorf("datagram after restart %q", got)
+	}
+}
+
+func TestUnixSocketStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	logger, err := NewLogger(Config{UnixSocket: &UnixSocketConfig{Path: path, Network: "unix"}, Encoding: JSONEncoding})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	logger.Info("a")
+	logger.Info("b\nc")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// SOCK_STREAM 每帧以 4 字节大端长度开头
+	for _, want := range []string{`"msg":"a"`, `"msg":"b\nc"`} {
+		var header [4]byte
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			t.Fatal(err)
+		}
+		frame := make([]byte, binary.BigEndian.Uint32(header[:]))
+		if _, err := io.ReadFull(conn, frame); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(frame), want) || strings.HasSuffix(string(frame), "\n") {
+			t.Errorf("frame %q, want %s", frame, want)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package pplogger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConfigError 汇总 Config 中的全部错误
+type ConfigError struct {
+	Problems []error
+}
+
+func (e *ConfigError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.Error())
+	}
+
+	return "pplogger: invalid config: " + strings.Join(msgs, "; ")
+}
+
+func (e *ConfigError) Unwrap() []error {
+	return e.Problems
+}
+
+// Validate 检查配置，一次性返回所有问题，没有问题时返回 nil
+func (config Config) Validate() error {
+	var errs []error
+
+	if _, err := ParseLevel(config.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+
+	if config.MaxSize < 0 {
+		errs = append(errs, fmt.Errorf("negative MaxSize %d", config.MaxSize))
+	}
+
+	if config.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("negative MaxBackups %d", config.MaxBackups))
+	}
+
+	if config.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("negative MaxAge %d", config.MaxAge))
+	}
+
+	if config.MaxMessageBytes < 0 {
+		errs = append(errs, fmt.Errorf("negative MaxMessageBytes %d", config.MaxMessageBytes))
+	}
+
+	if config.MaxFieldBytes < 0 {
+		errs = append(errs, fmt.Errorf("negative MaxFieldBytes %d", config.MaxFieldBytes))
+	}
+
+	if config.ErrorMaxSize < 0 || config.ErrorMaxBackups < 0 || config.ErrorMaxAge < 0 {
+		errs = append(errs, errors.New("negative ErrorMaxSize, ErrorMaxBackups or ErrorMaxAge"))
+	}
+
+	if config.StderrMinLevel != "" {
+		if _, err := ParseLevel(config.StderrMinLevel); err != nil {
+			errs = append(errs, fmt.Errorf("StderrMinLevel: %w", err))
+		}
+	}
+
+	if config.Syslog != nil {
+		errs = append(errs, config.Syslog.validate()...)
+	}
+
+	if config.Sampling != nil {
+		errs = append(errs, config.Sampling.validate()...)
+	}
+
+	if config.RateLimit != nil {
+		errs = append(errs, config.RateLimit.validate()...)
+	}
+
+	if config.DedupWindow < 0 {
+		errs = append(errs, fmt.Errorf("negative DedupWindow %s", config.DedupWindow))
+	}
+
+	if config.RemoteSyslog != nil {
+		errs = append(errs, config.RemoteSyslog.validate()...)
+	}
+
+	if config.GELF != nil {
+		errs = append(errs, config.GELF.validate()...)
+	}
+
+	if config.UnixSocket != nil {
+		errs = append(errs, config.UnixSocket.validate()...)
+	}
+
+	if config.TCP != nil {
+		errs = append(errs, config.TCP.validate()...)
+	}
+
+	if config.AlertWebhook != nil {
+		errs = append(errs, config.AlertWebhook.validate()...)
+	}
+
+	if config.Email != nil {
+		errs = append(errs, config.Email.validate()...)
+	}
+
+	if config.EventLogLevel != "" {
+		if _, err := ParseLevel(config.EventLogLevel); err != nil {
+			errs = append(errs, fmt.Errorf("EventLogLevel: %w", err))
+		}
+	}
+
+	if _, err := parseLevelOutputs(config.LevelOutputs); err != nil {
+		errs = append(errs, err)
+	}
+
+	if config.RingBufferSize < 0 {
+		errs = append(errs, fmt.Errorf("negative RingBufferSize %d", config.RingBufferSize))
+	}
+
+	if config.StacktraceMaxFrames < 0 {
+		errs = append(errs, fmt.Errorf("negative StacktraceMaxFrames %d", config.StacktraceMaxFrames))
+	}
+
+	if !config.hasSink() {
+		errs = append(errs, errors.New("no output configured, set StdoutWriter, FileWriter, DiscardWriter, ExtraWriters or another output"))
+	}
+
+	if config.CombinedFilename != "" && config.CombinedFilename == config.Filename {
+		errs = append(errs, fmt.Errorf("CombinedFilename %q is the same as Filename", config.CombinedFilename))
+	}
+
+	if err := config.validCompression(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := parseByteSize(config.MaxTotalSize); err != nil {
+		errs = append(errs, fmt.Errorf("MaxTotalSize: %w", err))
+	}
+
+	if _, err := parseRotateInterval(config.RotateInterval); err != nil {
+		errs = append(errs, err)
+	}
+
+	if config.FilenamePattern != "" {
+		if config.RotateInterval == "" {
+			errs = append(errs, errors.New("FilenamePattern requires RotateInterval"))
+		}
+		if _, err := parseFilenamePattern(config.FilenamePattern, "app"); err != nil {
+			errs = append(errs, err)
+		}
+		if config.WriterFactory != nil {
+			errs = append(errs, errors.New("FilenamePattern is not supported with WriterFactory"))
+		}
+	}
+
+	if config.SymlinkName != "" {
+		if err := config.validSymlinkName(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if config.PathPattern != "" {
+		if err := validPathPattern(config.PathPattern); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(config.AdditionalFiles) > 0 && !config.FileWriter {
+		errs = append(errs, errors.New("AdditionalFiles requires FileWriter"))
+	}
+	for _, target := range config.AdditionalFiles {
+		errs = append(errs, target.validate()...)
+	}
+
+	for _, output := range config.Outputs {
+		if err := validOutput(output); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for i, w := range config.ExtraWriters {
+		if w == nil {
+			errs = append(errs, fmt.Errorf("ExtraWriters[%d] is nil", i))
+		}
+	}
+
+	for i, s := range config.Sinks {
+		if s == nil {
+			errs = append(errs, fmt.Errorf("Sinks[%d] is nil", i))
+		}
+	}
+
+	for i, c := range config.Cores {
+		if c == nil {
+			errs = append(errs, fmt.Errorf("Cores[%d] is nil", i))
+		}
+	}
+
+	if config.FileWriter && config.FileSyncer == nil && config.Filename == "" {
+		errs = append(errs, errors.New("Filename is required when FileWriter is true"))
+	}
+
+	if !validEncoding(config.Encoding) {
+		errs = append(errs, fmt.Errorf("unknown encoding %q", config.Encoding))
+	}
+
+	if !validEncoding(config.StdoutEncoding) {
+		errs = append(errs, fmt.Errorf("unknown StdoutEncoding %q", config.StdoutEncoding))
+	}
+
+	if !validEncoding(config.FileEncoding) {
+		errs = append(errs, fmt.Errorf("unknown FileEncoding %q", config.FileEncoding))
+	}
+
+	if !validEncoding(config.SinkEncoding) {
+		errs = append(errs, fmt.Errorf("unknown SinkEncoding %q", config.SinkEncoding))
+	}
+
+	if config.TimeZone != "" {
+		if _, err := time.LoadLocation(config.TimeZone); err != nil {
+			errs = append(errs, fmt.Errorf("TimeZone: %w", err))
+		}
+	}
+
+	switch config.TimeFormat {
+	case "", TimeFormatEpochMillis, TimeFormatEpochNanos, TimeFormatRFC3339Nano:
+	default:
+		errs = append(errs, fmt.Errorf("unknown TimeFormat %q", config.TimeFormat))
+	}
+
+	switch config.LevelFormat {
+	case "", LevelFormatCapital, LevelFormatLower, LevelFormatCapitalColor, LevelFormatLowerColor, LevelFormatShort3:
+	default:
+		errs = append(errs, fmt.Errorf("unknown LevelFormat %q", config.LevelFormat))
+	}
+
+	switch config.DurationFormat {
+	case "", DurationFormatString, DurationFormatSeconds, DurationFormatMillis, DurationFormatNanos:
+	default:
+		errs = append(errs, fmt.Errorf("unknown DurationFormat %q", config.DurationFormat))
+	}
+
+	switch config.CallerFormat {
+	case "", CallerFormatShort, CallerFormatFull, CallerFormatFunc:
+	default:
+		errs = append(errs, fmt.Errorf("unknown CallerFormat %q", config.CallerFormat))
+	}
+
+	switch config.LineEnding {
+	case "", LineEndingLF, LineEndingCRLF:
+	default:
+		errs = append(errs, fmt.Errorf("unknown LineEnding %q", config.LineEnding))
+	}
+
+	switch config.Color {
+	case "", ColorAuto, ColorAlways, ColorNever:
+	default:
+		errs = append(errs, fmt.Errorf("unknown Color %q", config.Color))
+	}
+
+	if config.CallerSkip < 0 {
+		errs = append(errs, fmt.Errorf("negative CallerSkip %d", config.CallerSkip))
+	}
+
+	if config.StacktraceLevel != StacktraceNone {
+		if _, err := ParseLevel(config.StacktraceLevel); err != nil {
+			errs = append(errs, fmt.Errorf("StacktraceLevel: %w", err))
+		}
+	}
+
+	if _, err := parseModuleLevels(config.ModuleLevels); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := compileDropPatterns(config.DropPatterns); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return &ConfigError{Problems: errs}
+	}
+
+	return nil
+}
+
+// hasSink 判断是否设置了至少一个输出
+func (config Config) hasSink() bool {
+	return config.StdoutWriter || config.FileWriter || config.DiscardWriter || config.K8s ||
+		config.ErrorFilename != "" || len(config.LevelOutputs) > 0 ||
+		config.Syslog != nil || config.RemoteSyslog != nil || config.GELF != nil || config.UnixSocket != nil || config.TCP != nil || config.Journald || config.EventSource != "" || len(config.Sinks) > 0 || len(config.Cores) > 0 ||
+		len(config.ExtraWriters) > 0 || len(config.Outputs) > 0
+}
@@ -0,0 +1,130 @@
+package pplogger
+
+import (
+	"go.uber.org/zap"
+	"sync"
+)
+
+// PPLogger 提供 printf 风格及键值对风格的便捷方法，SugaredLogger 在首次使用时才创建
+type PPLogger struct {
+	logger    *Logger
+	sugarOnce sync.Once
+	sugar     *zap.SugaredLogger
+	helper    *zap.SugaredLogger // 供 Infof 等便捷方法使用，多跳过一层调用
+}
+
+// NewWrapped 根据 Config 创建 PPLogger
+func NewWrapped(config Config) (*PPLogger, error) {
+	logger, err := newLogger([]Option{WithConfig(config)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PPLogger{logger: logger}, nil
+}
+
+// Raw 返回底层的 *zap.Logger，用于结构化日志
+func (l *PPLogger) Raw() *zap.Logger {
+	return l.logger.Logger
+}
+
+// Sugar 返回 SugaredLogger，首次调用时创建
+func (l *PPLogger) Sugar() *zap.SugaredLogger {
+	l.initSugar()
+
+	return l.sugar
+}
+
+// sugared 返回便捷方法使用的 SugaredLogger，跳过 PPLogger 自身的一层调用，使 caller 指向业务代码
+func (l *PPLogger) sugared() *zap.SugaredLogger {
+	l.initSugar()
+
+	return l.helper
+}
+
+func (l *PPLogger) initSugar() {
+	l.sugarOnce.Do(func() {
+		l.sugar = l.logger.Sugar()
+		l.helper = l.logger.WithOptions(zap.AddCallerSkip(1)).Sugar()
+	})
+}
+
+// Sync 刷新缓冲
+func (l *PPLogger) Sync() error {
+	return l.logger.Sync()
+}
+
+// Close 刷新缓冲并关闭日志文件
+func (l *PPLogger) Close() error {
+	return l.logger.Close()
+}
+
+// Debugf 按 fmt.Sprintf 格式输出 Debug 日志
+func (l *PPLogger) Debugf(template string, args ...interface{}) {
+	l.sugared().Debugf(template, args...)
+}
+
+// Infof 按 fmt.Sprintf 格式输出 Info 日志
+func (l *PPLogger) Infof(template string, args ...interface{}) {
+	l.sugared().Infof(template, args...)
+}
+
+// Warnf 按 fmt.Sprintf 格式输出 Warn 日志
+func (l *PPLogger) Warnf(template string, args ...interface{}) {
+	l.sugared().Warnf(template, args...)
+}
+
+// Errorf 按 fmt.Sprintf 格式输出 Error 日志
+func (l *PPLogger) Errorf(template string, args ...interface{}) {
+	l.sugared().Errorf(template, args...)
+}
+
+// DPanicf 按 fmt.Sprintf 格式输出 DPanic 日志，开发模式下随后 panic
+func (l *PPLogger) DPanicf(template string, args ...interface{}) {
+	l.sugared().DPanicf(template, args...)
+}
+
+// Panicf 按 fmt.Sprintf 格式输出 Panic 日志，随后 panic
+func (l *PPLogger) Panicf(template string, args ...interface{}) {
+	l.sugared().Panicf(template, args...)
+}
+
+// Fatalf 按 fmt.Sprintf 格式输出 Fatal 日志，随后调用 os.Exit(1)
+func (l *PPLogger) Fatalf(template string, args ...interface{}) {
+	l.sugared().Fatalf(template, args...)
+}
+
+// Debugw 输出带键值对的 Debug 日志
+func (l *PPLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.sugared().Debugw(msg, keysAndValues...)
+}
+
+// Infow 输出带键值对的 Info 日志
+func (l *PPLogger) Infow(msg string, keysAndValues ...interface{}) {
+	l.sugared().Infow(msg, keysAndValues...)
+}
+
+// Warnw 输出带键值对的 Warn 日志
+func (l *PPLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.sugared().Warnw(msg, keysAndValues...)
+}
+
+// Errorw 输出带键值对的 Error 日志
+func (l *PPLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.sugared().Errorw(msg, keysAndValues...)
+}
+
+// DPanicw 输出带键值对的 DPanic 日志，开发模式下随后 panic
+func (l *PPLogger) DPanicw(msg string, keysAndValues ...interface{}) {
+	l.sugared().DPanicw(msg, keysAndValues...)
+}
+
+// Panicw 输出带键值对的 Panic 日志，随后 panic
+func (l *PPLogger) Panicw(msg string, keysAndValues ...interface{}) {
+	l.sugared().Panicw(msg, keysAndValues...)
+}
+
+// Fatalw 输出带键值对的 Fatal 日志，随后调用 os.Exit(1)
+func (l *PPLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.sugared().Fatalw(msg, keysAndValues...)
+}
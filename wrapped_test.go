@@ -0,0 +1,95 @@
+package pplogger
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPPLoggerCaller(t *testing.T) {
+	buf := &syncBuffer{}
+	logger, err := NewWrapped(Config{ExtraWriters: []io.Writer{buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.Infof("helper %d", 1)
+	logger.Sugar().Infof("sugar %d", 2)
+	logger.Infow("helper", "n", 3)
+
+	lines := buf.lines()
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "wrapped_test.go") {
+			t.Errorf("caller does not point to the test: %s", line)
+		}
+	}
+}
+
+func TestPPLoggerLazySugar(t *testing.T) {
+	wrapped, err := NewWrapped(Config{DiscardWriter: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wrapped.Close()
+	plain, err := NewLogger(Config{DiscardWriter: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+
+	// 只使用结构化日志时不创建 SugaredLogger，分配次数与直接使用 *zap.Logger 相同
+	got := testing.AllocsPerRun(100, func() { wrapped.Raw().Info("benchmark") })
+	want := testing.AllocsPerRun(100, func() { plain.Info("benchmark") })
+	if got != want {
+		t.Errorf("PPLogger allocs = %v, *zap.Logger allocs = %v", got, want)
+	}
+	if wrapped.sugar != nil {
+		t.Error("SugaredLogger was created without using the sugared helpers")
+	}
+}
+
+func BenchmarkPPLoggerInfo(b *testing.B) {
+	b.Run("zap.Logger", func(b *testing.B) {
+		logger, err := NewLogger(Config{DiscardWriter: true})
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer logger.Close()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Info("benchmark")
+		}
+	})
+	b.Run("PPLogger.Raw", func(b *testing.B) {
+		logger, err := NewWrapped(Config{DiscardWriter: true})
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer logger.Close()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Raw().Info("benchmark")
+		}
+	})
+	b.Run("PPLogger.Infof", func(b *testing.B) {
+		logger, err := NewWrapped(Config{DiscardWriter: true})
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer logger.Close()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Infof("benchmark %d", i)
+		}
+	})
+}
@@ -0,0 +1,354 @@
+package pplogger
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RotatingWriter 是日志文件的写入及切割实现，见 Config.WriterFactory，*lumberjack.Logger 即实现了该接口。
+// Close 之后再次 Write 时需重新打开文件（与 lumberjack 相同），ReopenOnRename 依赖这一行为
+type RotatingWriter interface {
+	io.Writer
+	Rotate() error
+	Close() error
+}
+
+// newLumberjackWriter 是默认的 Config.WriterFactory
+func newLumberjackWriter(config Config) (RotatingWriter, error) {
+	return getFileLogger(config), nil
+}
+
+// 同一进程内指向同一文件的 RotatingWriter 只创建一个，避免多个实例同时切割同一文件
+var fileWriters = struct {
+	sync.Mutex
+	writers map[string]*fileWriter
+}{
+	writers: make(map[string]*fileWriter),
+}
+
+// fileWriter 是按文件路径共享的 RotatingWriter，引用计数归零时关闭
+type fileWriter struct {
+	mu       sync.Mutex
+	path     string
+	logger   RotatingWriter
+	lj       *lumberjack.Logger // 使用默认实现时与 logger 相同，否则为 nil，按大小切割及备份的命名、清理均交给 WriterFactory 的实现
+	refs     int
+	size     int64        // 当前文件大小，用于判断本次写入是否会触发切割
+	onRotate func(string) // 切割产生备份文件后调用，见 Config.OnRotate
+
+	// 按时间切割，见 Config.RotateInterval
+	interval time.Duration
+	loc      *time.Location
+	now      func() time.Time // 便于测试替换时钟，同样用于 mill 按 MaxAge 清理
+	next     time.Time        // 下一次按时间切割的时间
+	period   time.Time        // 当前周期的开始时间
+
+	pattern *filenamePattern // 按 Config.FilenamePattern 命名备份，此时按大小切割也不交给 lumberjack
+	millMu  sync.Mutex       // mill 及 MaxTotalSize 的清理串行执行
+
+	// 由 mill 清理及压缩备份时使用，见 ownMill
+	maxBackups       int
+	maxAge           int
+	compression      string
+	compressionLevel int
+	compressDelay    time.Duration
+
+	link *currentLink // 指向该文件的链接，切割后更新，见 Config.SymlinkName
+
+	released bool // 已经关闭，Logger.Rotate 不再重新打开
+
+	maxTotalSize int64                         // 见 Config.MaxTotalSize，切割后在后台清理
+	onRemove     func(path string, size int64) // MaxTotalSize 删除备份后调用
+
+	// 文件被外部移走后重新打开，见 Config.ReopenOnRename
+	reopen  bool
+	file    os.FileInfo // 当前打开的文件
+	checked time.Time   // 上次检查的时间
+
+	banner func() []byte // 切割后写到新文件开头，见 Config.Banner
+
+	janitor *retentionJanitor // Factory 的统一清理，见 FactoryRetention
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.reopen {
+		if err := w.checkReopen(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.interval > 0 {
+		if now := w.now(); !now.Before(w.next) {
+			period, boundary := w.period, w.next
+			w.period, w.next = w.periodStart(now), w.nextRotation(now)
+			// 上一周期没有写入时不产生空的备份文件
+			if w.size > 0 {
+				rotate := w.logger.Rotate
+				if w.lj != nil {
+					rotate = func() error { return w.rotateTo(w.backupName(period, boundary)) }
+				}
+				if err := w.rotate(rotate); err != nil {
+					return 0, err
+				}
+			}
+		}
+	}
+
+	if w.size+int64(len(p)) <= w.maxSize() {
+		n, err := w.logger.Write(p)
+		w.size += int64(n)
+		return n, err
+	}
+
+	// 本次写入会触发按大小切割，先切割再写入，Banner 位于新文件开头
+	if w.size > 0 {
+		if err := w.rotate(w.rotateNow()); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.logger.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// acquireFileWriter 返回 config 对应文件的写入句柄，同一路径共享底层 RotatingWriter，
+// 切割参数以第一次创建时为准
+func acquireFileWriter(config Config) (*fileHandle, error) {
+	path, err := filepath.Abs(filepath.Join(config.LogPath, config.Filename))
+	if err != nil {
+		return nil, fmt.Errorf("pplogger: resolve log file: %w", err)
+	}
+
+	fileWriters.Lock()
+	defer fileWriters.Unlock()
+
+	w, ok := fileWriters.writers[path]
+	if !ok {
+		config.LogPath, config.Filename = filepath.Split(path)
+		factory := config.WriterFactory
+		if factory == nil {
+			factory = newLumberjackWriter
+		}
+		logger, err := factory(config)
+		if err != nil {
+			return nil, fmt.Errorf("pplogger: create log writer: %w", err)
+		}
+		w = &fileWriter{
+			path:   path,
+			logger: logger,
+			now:    time.Now,
+			reopen: config.ReopenOnRename,
+		}
+		// 备份由 mill 及 MaxTotalSize 清理时依赖 lumberjack 的命名
+		if lj, ok := logger.(*lumberjack.Logger); ok && config.WriterFactory == nil {
+			w.lj = lj
+			w.maxBackups, w.maxAge = config.MaxBackups, config.MaxAge
+			w.compression, w.compressionLevel, w.compressDelay = config.compression(), config.CompressionLevel, config.CompressDelay
+			w.maxTotalSize, _ = parseByteSize(config.MaxTotalSize)
+		}
+		// 写入空数据以提前打开文件，尽早暴露权限等问题
+		if _, err := w.Write(nil); err != nil && !config.lazyOpen {
+			return nil, fmt.Errorf("pplogger: open log file: %w", err)
+		}
+		info, err := os.Stat(path)
+		if err == nil {
+			w.size = info.Size()
+			w.file, w.checked = info, w.now()
+		}
+		if w.interval, _ = parseRotateInterval(config.RotateInterval); w.interval > 0 {
+			w.loc = timeLocation(config.TimeZone)
+			w.period, w.next = w.periodStart(w.now()), w.nextRotation(w.now())
+			if w.size > 0 {
+				// 已有的文件属于之前的周期时，第一次写入即切割
+				w.period, w.next = w.periodStart(info.ModTime()), w.nextRotation(info.ModTime())
+			}
+			if config.FilenamePattern != "" && w.lj != nil {
+				ext := filepath.Ext(config.Filename)
+				if w.pattern, err = parseFilenamePattern(config.FilenamePattern, strings.TrimSuffix(config.Filename, ext)); err != nil {
+					_ = w.logger.Close()
+					return nil, fmt.Errorf("pplogger: %w", err)
+				}
+			}
+		}
+		if w.ownMill() {
+			go w.mill(w.now())
+		}
+		fileWriters.writers[path] = w
+	}
+	w.refs++
+
+	return &fileHandle{writer: w}, nil
+}
+
+// acquireErrorFileWriter 返回 Error 日志文件的写入句柄，未设置的路径及切割参数沿用主文件
+func acquireErrorFileWriter(config Config) (*fileHandle, error) {
+	config.Filename = config.ErrorFilename
+	config.FilenamePattern = sharedFilenamePattern(config.FilenamePattern)
+	if config.ErrorLogPath != "" {
+		config.LogPath = config.ErrorLogPath
+	}
+	if config.ErrorMaxSize != 0 {
+		config.MaxSize = config.ErrorMaxSize
+	}
+	if config.ErrorMaxBackups != 0 {
+		config.MaxBackups = config.ErrorMaxBackups
+	}
+	if config.ErrorMaxAge != 0 {
+		config.MaxAge = config.ErrorMaxAge
+	}
+
+	logPath, err := resolveLogPath(config.LogPath)
+	if err != nil {
+		return nil, err
+	}
+	config.LogPath = logPath
+
+	return acquireFileWriter(config)
+}
+
+// closeAll 关闭 closers，用于构建失败时释放已获取的资源
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		_ = c.Close()
+	}
+}
+
+func releaseFileWriter(w *fileWriter) error {
+	fileWriters.Lock()
+	defer fileWriters.Unlock()
+
+	w.refs--
+	if w.refs > 0 {
+		return nil
+	}
+	delete(fileWriters.writers, w.path)
+	compressor.finish(w)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.released = true
+	return w.logger.Close()
+}
+
+// fileHandle 是每个 Logger 持有的文件写入句柄，关闭后丢弃写入，避免重新打开文件
+type fileHandle struct {
+	writer *fileWriter
+	closed atomic.Bool
+}
+
+func (h *fileHandle) Write(p []byte) (int, error) {
+	if h.closed.Load() {
+		return len(p), nil
+	}
+
+	return h.writer.Write(p)
+}
+
+// setOnRotate 设置切割回调，共享同一文件的多个 Logger 以第一次设置的为准
+func (h *fileHandle) setOnRotate(onRotate func(string)) {
+	h.writer.mu.Lock()
+	defer h.writer.mu.Unlock()
+
+	if h.writer.onRotate == nil {
+		h.writer.onRotate = onRotate
+	}
+}
+
+// setLink 使 link 指向该文件并在切割后更新，共享同一文件的多个 Logger 以第一次设置的为准
+func (h *fileHandle) setLink(link *currentLink) {
+	h.writer.mu.Lock()
+	defer h.writer.mu.Unlock()
+
+	if h.writer.link == nil {
+		h.writer.link = link
+	}
+	link.update(h.writer.path)
+}
+
+func (h *fileHandle) forceRotate() error {
+	if h.closed.Load() {
+		return nil
+	}
+
+	return h.writer.forceRotate()
+}
+
+func (h *fileHandle) Sync() error {
+	return nil
+}
+
+func (h *fileHandle) Close() error {
+	if !h.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	return releaseFileWriter(h.writer)
+}
+
+// fileSyncer 包装 Config.FileSyncer，不负责关闭，也不会切割
+type fileSyncer struct {
+	zapcore.WriteSyncer
+}
+
+func (fileSyncer) Close() error {
+	return nil
+}
+
+func (fileSyncer) setOnRotate(func(string)) {}
+
+func (fileSyncer) setLink(*currentLink) {}
+
+func (fileSyncer) setOnRemove(func(string, int64)) {}
+
+func (fileSyncer) setBanner(func() []byte) {}
+
+// fileSyncerIgnored 返回设置了 FileSyncer 时不再生效的文件相关配置
+func (config Config) fileSyncerIgnored() []string {
+	if !config.FileWriter || config.FileSyncer == nil {
+		return nil
+	}
+
+	var ignored []string
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"LogPath", config.LogPath != ""},
+		{"Filename", config.Filename != ""},
+		{"MaxSize", config.MaxSize != 0},
+		{"MaxBackups", config.MaxBackups != 0},
+		{"MaxAge", config.MaxAge != 0},
+		{"Compress", config.Compress},
+		{"LocalTime", config.LocalTime},
+		{"Compression", config.Compression != ""},
+		{"CompressionLevel", config.CompressionLevel != 0},
+		{"CompressDelay", config.CompressDelay != 0},
+		{"RotateInterval", config.RotateInterval != ""},
+		{"FilenamePattern", config.FilenamePattern != ""},
+		{"PathPattern", config.PathPattern != ""},
+		{"SymlinkName", config.SymlinkName != ""},
+		{"ReopenOnRename", config.ReopenOnRename},
+		{"MaxTotalSize", config.MaxTotalSize != ""},
+		{"OnRotate", config.OnRotate != nil},
+		{"WriterFactory", config.WriterFactory != nil},
+	} {
+		if f.set {
+			ignored = append(ignored, f.name)
+		}
+	}
+
+	return ignored
+}
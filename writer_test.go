@@ -0,0 +1,290 @@
+package pplogger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sharedWriters 返回 path 对应的共享写入器的引用数，不存在时返回 0
+func sharedWriters(path string) int {
+	fileWriters.Lock()
+	defer fileWriters.Unlock()
+
+	if w, ok := fileWriters.writers[path]; ok {
+		return w.refs
+	}
+
+	return 0
+}
+
+// countLines 统计 dir 下全部文件中包含 substr 的行数
+func countLines(t *testing.T, dir, substr string) int {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, entry := range entries {
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			if strings.Contains(scanner.Text(), substr) {
+				n++
+			}
+		}
+		_ = f.Close()
+	}
+
+	return n
+}
+
+func TestSharedFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	config := Config{FileWriter: true, LogPath: dir, Filename: "app.log", MaxSize: 1, MaxBackups: 10}
+	first, err := NewLogger(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 不同写法的同一路径共享同一个写入器
+	config.LogPath = dir + string(filepath.Separator) + "."
+	second, err := NewLogger(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refs := sharedWriters(path); refs != 2 {
+		t.Fatalf("refs = %d, want 2", refs)
+	}
+
+	// 每条约 100KB，两个 Logger 交替写入并多次按大小切割
+	line := strings.Repeat("y", 100*1024)
+	for i := 0; i < 25; i++ {
+		first.Info("first " + line)
+		second.Info("second " + line)
+	}
+	if err := first.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	second.Info("after rotate")
+
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if refs := sharedWriters(path); refs != 1 {
+		t.Fatalf("refs after closing one logger = %d, want 1", refs)
+	}
+	second.Info("still open")
+	if err := second.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if refs := sharedWriters(path); refs != 0 {
+		t.Fatalf("writer not released, refs = %d", refs)
+	}
+
+	// 切割没有互相覆盖，所有日志都在且只在一个文件中
+	for substr, want := range map[string]int{"first ": 25, "second ": 25, "after rotate": 1, "still open": 1} {
+		if got := countLines(t, dir, substr); got != want {
+			t.Errorf("%q appears %d times, want %d", substr, got, want)
+		}
+	}
+	if b, _ := os.ReadFile(path); !strings.Contains(string(b), "after rotate") || strings.Contains(string(b), "first ") {
+		t.Fatalf("current file does not start after the forced rotation")
+	}
+}
+
+// sizeWriter 是只按大小切割的 RotatingWriter 参考实现，备份为 <path>.1 到 <path>.<backups>，数字越大越旧
+type sizeWriter struct {
+	mu      sync.Mutex
+	path    string
+	max     int64
+	backups int
+	file    *os.File
+	size    int64
+	rotates int
+}
+
+func (w *sizeWriter) open() error {
+	if w.file != nil {
+		return nil
+	}
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.file, w.size = f, info.Size()
+
+	return nil
+}
+
+func (w *sizeWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.open(); err != nil {
+		return 0, err
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.max {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+func (w *sizeWriter) rotate() error {
+	w.rotates++
+	if w.file != nil {
+		_ = w.file.Close()
+		w.file = nil
+	}
+	for i := w.backups - 1; i > 0; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+	}
+
+	return os.Rename(w.path, w.path+".1")
+}
+
+func (w *sizeWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.rotate(); err != nil {
+		return err
+	}
+
+	return w.open()
+}
+
+func (w *sizeWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
+
+// sizeWriterFactory 返回创建 sizeWriter 的 WriterFactory，创建的写入器依次追加到 writers
+func sizeWriterFactory(writers *[]*sizeWriter) func(Config) (RotatingWriter, error) {
+	var mu sync.Mutex
+	return func(config Config) (RotatingWriter, error) {
+		w := &sizeWriter{path: filepath.Join(config.LogPath, config.Filename), max: 500, backups: config.MaxBackups}
+		mu.Lock()
+		*writers = append(*writers, w)
+		mu.Unlock()
+		return w, nil
+	}
+}
+
+func TestWriterFactory(t *testing.T) {
+	dir := t.TempDir()
+	var writers []*sizeWriter
+	logger, err := NewLogger(Config{
+		FileWriter:    true,
+		LogPath:       dir,
+		Filename:      "app.log",
+		ErrorFilename: "error.log",
+		MaxBackups:    3,
+		WriterFactory: sizeWriterFactory(&writers),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		logger.Info(strings.Repeat("x", 100))
+	}
+	logger.Error("boom")
+	if err := logger.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 主文件与 Error 文件各通过工厂创建一个写入器，按大小切割及备份清理由其实现
+	if len(writers) != 2 {
+		t.Fatalf("factory called %d times, want 2", len(writers))
+	}
+	files := readDir(t, dir)
+	for _, name := range []string{"app.log", "app.log.1", "app.log.3", "error.log", "error.log.1"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if _, ok := files["app.log.4"]; ok {
+		t.Error("MaxBackups not applied by the factory writer")
+	}
+}
+
+func TestWriterFactoryInterval(t *testing.T) {
+	dir := t.TempDir()
+	var writers []*sizeWriter
+	h, err := acquireFileWriter(Config{LogPath: dir, Filename: "app.log", RotateInterval: RotateDaily, WriterFactory: sizeWriterFactory(&writers)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now, advance := fakeClock(time.Now())
+	setClock(h, now)
+
+	// 按时间切割通过 RotatingWriter.Rotate 完成
+	if _, err := h.Write([]byte("a\n")); err != nil {
+		t.Fatal(err)
+	}
+	advance(25 * time.Hour)
+	if _, err := h.Write([]byte("b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if writers[0].rotates != 1 {
+		t.Errorf("Rotate called %d times, want 1", writers[0].rotates)
+	}
+	if files := readDir(t, dir); files["app.log"] != "b\n" || files["app.log.1"] != "a\n" {
+		t.Errorf("unexpected files %v", files)
+	}
+}
+
+func TestWriterFactoryError(t *testing.T) {
+	_, err := NewLogger(Config{FileWriter: true, LogPath: t.TempDir(), Filename: "app.log", WriterFactory: func(Config) (RotatingWriter, error) {
+		return nil, errors.New("boom")
+	}})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("NewLogger error = %v", err)
+	}
+
+	var writers []*sizeWriter
+	if err := (Config{FileWriter: true, WriterFactory: sizeWriterFactory(&writers), FilenamePattern: "app-%Y.log", RotateInterval: RotateDaily}).Validate(); err == nil {
+		t.Error("Validate accepted FilenamePattern with WriterFactory")
+	}
+}